@@ -0,0 +1,338 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/MaterDev/golang_turnbased_game_spike/internal/equipment"
+	"github.com/MaterDev/golang_turnbased_game_spike/internal/game"
+	"github.com/MaterDev/golang_turnbased_game_spike/internal/progression"
+	"github.com/MaterDev/golang_turnbased_game_spike/internal/validation"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// CreateCharacterRequest creates a persistent character at level 1
+type CreateCharacterRequest struct {
+	Name      string   `json:"Name"`
+	Class     string   `json:"Class"`
+	Health    int      `json:"Health"`
+	Attack    int      `json:"Attack"`
+	Defense   int      `json:"Defense"`
+	Speed     int      `json:"Speed"`
+	Abilities []string `json:"Abilities"` // Ability IDs
+}
+
+// CharacterResponse is a persistent character, what it wears and its
+// progression
+type CharacterResponse struct {
+	*progression.Profile
+	Equipment equipment.Loadout    `json:"Equipment"`
+	Progress  progression.Progress `json:"Progress"`
+}
+
+// CharacterManager handles storing and retrieving persistent characters
+type CharacterManager struct {
+	profiles map[string]*progression.Profile
+	loadouts map[string]equipment.Loadout
+	mu       sync.RWMutex
+}
+
+func NewCharacterManager() *CharacterManager {
+	return &CharacterManager{
+		profiles: make(map[string]*progression.Profile),
+		loadouts: make(map[string]equipment.Loadout),
+	}
+}
+
+func (cm *CharacterManager) AddCharacter(profile *progression.Profile) {
+	cm.mu.Lock()
+	cm.profiles[profile.ID] = profile
+	cm.mu.Unlock()
+}
+
+// GetCharacter returns a copy of the profile, or nil if there is none
+func (cm *CharacterManager) GetCharacter(id string) *progression.Profile {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	profile, ok := cm.profiles[id]
+	if !ok {
+		return nil
+	}
+	copied := *profile
+	copied.Abilities = append([]string(nil), profile.Abilities...)
+	return &copied
+}
+
+// Equipment returns a copy of what a character wears
+func (cm *CharacterManager) Equipment(id string) equipment.Loadout {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	loadout := equipment.Loadout{}
+	for slot, item := range cm.loadouts[id] {
+		loadout[slot] = item
+	}
+	return loadout
+}
+
+// SetEquipment replaces what a character wears. It reports false if id is
+// not a persistent character.
+func (cm *CharacterManager) SetEquipment(id string, loadout equipment.Loadout) bool {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	if _, ok := cm.profiles[id]; !ok {
+		return false
+	}
+	cm.loadouts[id] = loadout
+	return true
+}
+
+// AwardExperience adds xp to a character and returns the levels it gained.
+// It reports false if id is not a persistent character.
+func (cm *CharacterManager) AwardExperience(id string, xp int) (int, bool) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	profile, ok := cm.profiles[id]
+	if !ok {
+		return 0, false
+	}
+	return profile.AddExperience(xp), true
+}
+
+var characterManager = NewCharacterManager()
+
+// combatCharacter builds the battle copy of a persistent character
+func combatCharacter(profile *progression.Profile) (game.Character, error) {
+	c, err := profile.CombatCharacter(library.Growth(profile.Class), library)
+	if err != nil {
+		return game.Character{}, err
+	}
+	if class, ok := library.Class(profile.Class); ok {
+		c = class.ApplyPassives(c)
+	}
+	return c, nil
+}
+
+// fromClass builds a battle character from its class and name. The class
+// supplies the stats and abilities, so the request must leave them out.
+func fromClass(v *validation.Validator, path string, c game.Character) game.Character {
+	if _, ok := library.Class(c.Class); !ok {
+		v.Add(path+".Class", "unknown class %q", c.Class)
+		return c
+	}
+	if c.Health != 0 || c.Attack != 0 || c.Defense != 0 || c.Speed != 0 || c.Abilities != nil {
+		v.Add(path+".Class", "stats and abilities come from the class and must be left out")
+		return c
+	}
+	built, err := library.NewCharacter(c.Class, c.Name)
+	if err != nil {
+		v.Add(path+".Class", "%v", err)
+		return c
+	}
+	built.Inventory = c.Inventory
+	return built
+}
+
+// awardExperience returns a completion callback that pays out experience to
+// the persistent characters in a battle. start1 and start2 are the
+// characters as they entered the battle, so opponents are judged at full
+// strength.
+func awardExperience(start1, start2 game.Character) func(*game.Battle) {
+	return func(b *game.Battle) {
+		// The winner may be a reserve, so judge by side rather than by ID
+		sides := []struct {
+			self, opponent game.Character
+			standing       *game.Character
+		}{
+			{start1, start2, b.Character1},
+			{start2, start1, b.Character2},
+		}
+		for _, side := range sides {
+			// Running away earns nothing
+			if b.Fled != nil && b.Fled.ID == side.self.ID {
+				continue
+			}
+			outcome := progression.OutcomeDraw
+			if b.Winner != nil && b.Winner == side.standing {
+				outcome = progression.OutcomeWin
+			} else if b.Winner != nil {
+				outcome = progression.OutcomeLoss
+			}
+
+			xp := progression.ExperienceReward(outcome, side.self, side.opponent)
+			if levels, ok := characterManager.AwardExperience(side.self.ID, xp); ok {
+				log.Printf("Character %s earned %d experience from battle %s (%s), gaining %d levels",
+					side.self.ID, xp, b.ID, outcome, levels)
+			}
+		}
+	}
+}
+
+// validateLoadout checks that every slot holds an item that exists and is
+// worn there
+func validateLoadout(v *validation.Validator, path string, loadout equipment.Loadout) {
+	for _, slot := range sortedSlots(loadout) {
+		id := loadout[slot]
+		slotPath := fmt.Sprintf("%s[%s]", path, slot)
+		if !slot.IsValid() {
+			v.Add(slotPath, "unknown slot %q", slot)
+			continue
+		}
+		item, ok := library.Item(id)
+		if !ok {
+			v.Add(slotPath, "unknown item %q", id)
+			continue
+		}
+		if item.Slot != slot {
+			v.Add(slotPath, "item %q is worn in %s", id, item.Slot)
+		}
+	}
+}
+
+func sortedSlots(loadout equipment.Loadout) []equipment.Slot {
+	slots := make([]equipment.Slot, 0, len(loadout))
+	for slot := range loadout {
+		slots = append(slots, slot)
+	}
+	sort.Slice(slots, func(i, j int) bool { return slots[i] < slots[j] })
+	return slots
+}
+
+// equip dresses the combat copy of a character in its loadout
+func equip(c game.Character, loadout equipment.Loadout) (game.Character, error) {
+	items, err := loadout.Resolve(library)
+	if err != nil {
+		return game.Character{}, err
+	}
+	return equipment.Equip(c, items...), nil
+}
+
+func toCharacterResponse(profile *progression.Profile) CharacterResponse {
+	return CharacterResponse{
+		Profile:   profile,
+		Equipment: characterManager.Equipment(profile.ID),
+		Progress:  profile.Progress(library.Growth(profile.Class)),
+	}
+}
+
+func createCharacterHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var request CreateCharacterRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	profile := progression.NewProfile(uuid.New().String(), request.Name, request.Class, progression.Stats{
+		Health:  request.Health,
+		Attack:  request.Attack,
+		Defense: request.Defense,
+		Speed:   request.Speed,
+	}, request.Abilities)
+
+	v := validation.New(validation.DefaultLimits)
+
+	// Characters of a known class take their stats and abilities from it
+	if class, ok := library.Class(request.Class); ok {
+		if profile.Base != (progression.Stats{}) {
+			v.Add("Class", "stats come from the class and must be left out")
+		}
+		profile.Base = class.Base
+		if profile.Abilities == nil {
+			profile.Abilities = class.StartingAbilities
+		}
+		for i, id := range profile.Abilities {
+			if !class.Allows(id) {
+				v.Add(fmt.Sprintf("Abilities[%d]", i), "ability %q is not allowed for the %s class", id, class.Name)
+			}
+		}
+	}
+	for i, id := range profile.Abilities {
+		if _, ok := library.Ability(id); !ok {
+			v.Add(fmt.Sprintf("Abilities[%d]", i), "unknown ability %q", id)
+		}
+	}
+	if len(v.Errors()) == 0 {
+		c, err := combatCharacter(profile)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		v.Character("", c)
+	}
+	if errs := v.Errors(); len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	characterManager.AddCharacter(profile)
+	log.Printf("Created character %s (%s)", profile.ID, profile.Name)
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(toCharacterResponse(profile))
+}
+
+func getCharacterHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	characterID := mux.Vars(r)["id"]
+	profile := characterManager.GetCharacter(characterID)
+	if profile == nil {
+		http.Error(w, fmt.Sprintf("Character not found: %s", characterID), http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(toCharacterResponse(profile))
+}
+
+func getProgressionHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	characterID := mux.Vars(r)["id"]
+	profile := characterManager.GetCharacter(characterID)
+	if profile == nil {
+		http.Error(w, fmt.Sprintf("Character not found: %s", characterID), http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(profile.Progress(library.Growth(profile.Class)))
+}
+
+func setEquipmentHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	characterID := mux.Vars(r)["id"]
+	profile := characterManager.GetCharacter(characterID)
+	if profile == nil {
+		http.Error(w, fmt.Sprintf("Character not found: %s", characterID), http.StatusNotFound)
+		return
+	}
+
+	var loadout equipment.Loadout
+	if err := json.NewDecoder(r.Body).Decode(&loadout); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	v := validation.New(validation.DefaultLimits)
+	validateLoadout(v, "Equipment", loadout)
+	if errs := v.Errors(); len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	characterManager.SetEquipment(characterID, loadout)
+	log.Printf("Character %s equipped %v", characterID, loadout)
+
+	json.NewEncoder(w).Encode(toCharacterResponse(profile))
+}
+
+func listClassesHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(library.Classes())
+}
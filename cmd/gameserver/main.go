@@ -2,16 +2,31 @@ package main
 
 import (
 	"bytes"
+	"cmp"
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"syscall"
+	"time"
 
+	"github.com/MaterDev/golang_turnbased_game_spike/internal/content"
+	"github.com/MaterDev/golang_turnbased_game_spike/internal/equipment"
 	"github.com/MaterDev/golang_turnbased_game_spike/internal/game"
+	"github.com/MaterDev/golang_turnbased_game_spike/internal/validation"
 	"github.com/gorilla/mux"
 	"github.com/google/uuid"
 )
@@ -19,48 +34,278 @@ import (
 type BattleRequest struct {
 	Character1 game.Character `json:"Character1"`
 	Character2 game.Character `json:"Character2"`
+	// Character1ID and Character2ID bring persistent characters into the
+	// battle in place of Character1 and Character2
+	Character1ID string `json:"Character1ID,omitempty"`
+	Character2ID string `json:"Character2ID,omitempty"`
+	// Character1Equipment and Character2Equipment dress the characters for
+	// this battle only. Persistent characters otherwise wear their own.
+	Character1Equipment equipment.Loadout `json:"Character1Equipment,omitempty"`
+	Character2Equipment equipment.Loadout `json:"Character2Equipment,omitempty"`
+	// Character1Items and Character2Items are the consumables each side
+	// carries into the battle, added to any already in its Inventory
+	Character1Items []ItemStack `json:"Character1Items,omitempty"`
+	Character2Items []ItemStack `json:"Character2Items,omitempty"`
+	// Character1Reserves and Character2Reserves join each side as reserves
+	// that can be swapped in, and take over when the side's character falls
+	Character1Reserves []game.Character `json:"Character1Reserves,omitempty"`
+	Character2Reserves []game.Character `json:"Character2Reserves,omitempty"`
+	// TurnTimeoutSeconds forfeits a turn nobody acts on in time. Zero disables it.
+	TurnTimeoutSeconds int `json:"TurnTimeoutSeconds,omitempty"`
+	TurnMode           game.TurnMode `json:"TurnMode,omitempty"`
+	// RuleSet names a preset from GET /api/rulesets, and Rules gives a
+	// complete rule set instead. TurnMode, when set, overrides either.
+	RuleSet string        `json:"RuleSet,omitempty"`
+	Rules   *game.RuleSet `json:"Rules,omitempty"`
+	// Environment is the weather the battle starts under
+	Environment *game.Environment `json:"Environment,omitempty"`
+	// Grid fights the battle on a map; without one it is a duel
+	Grid *GridRequest `json:"Grid,omitempty"`
+	// AI lets the server play one of the characters
+	AI *AIRequest `json:"AI,omitempty"`
+}
+
+// GridRequest is the map of a tactical battle: rows of terrain, one
+// character per cell, and where each side's character starts
+type GridRequest struct {
+	Rows      []string      `json:"Rows"`
+	Position1 game.Position `json:"Position1"`
+	Position2 game.Position `json:"Position2"`
+}
+
+// ItemStack is a quantity of one consumable, by ID
+type ItemStack struct {
+	Item     string `json:"Item"`
+	Quantity int    `json:"Quantity"`
+}
+
+// resolveItems looks up the consumables in stacks, recording problems
+// against path
+func resolveItems(v *validation.Validator, path string, stacks []ItemStack) []game.Consumable {
+	var inventory []game.Consumable
+	for i, stack := range stacks {
+		stackPath := fmt.Sprintf("%s[%d]", path, i)
+		consumable, ok := library.Consumable(stack.Item)
+		if !ok {
+			v.Add(stackPath+".Item", "unknown consumable %q", stack.Item)
+			continue
+		}
+		if stack.Quantity < 1 || stack.Quantity > v.Limits.MaxItemQuantity {
+			v.Add(stackPath+".Quantity", "must be between 1 and %d, got %d", v.Limits.MaxItemQuantity, stack.Quantity)
+			continue
+		}
+		consumable.Quantity = stack.Quantity
+		inventory = append(inventory, consumable)
+	}
+	return inventory
+}
+
+// battleRules picks the rules a battle request asks for, recording problems
+// against the fields that caused them
+func battleRules(v *validation.Validator, request BattleRequest) game.RuleSet {
+	rules := game.ClassicRules
+	path := "Rules"
+	if request.RuleSet != "" {
+		preset, ok := game.Preset(request.RuleSet)
+		if !ok {
+			v.Add("RuleSet", "unknown rule set %q", request.RuleSet)
+		}
+		rules = preset
+	}
+	if request.Rules != nil {
+		rules = *request.Rules
+	}
+	if request.TurnMode != "" && request.TurnMode != rules.TurnMode {
+		rules.TurnMode = request.TurnMode
+		// No longer the preset it was named after
+		rules.Name = ""
+		if request.Rules == nil {
+			path = ""
+		}
+	}
+	v.Rules(path, rules)
+	return rules
+}
+
+// prepareReserves builds and validates the reserves for one side, giving
+// each its own ID. Reserves may be built from a class like any character.
+func prepareReserves(v *validation.Validator, path string, reserves []game.Character, side int) []*game.Character {
+	if len(reserves) > v.Limits.MaxReserves {
+		v.Add(path, "must have at most %d reserves, got %d", v.Limits.MaxReserves, len(reserves))
+		return nil
+	}
+	var prepared []*game.Character
+	for i, c := range reserves {
+		reservePath := fmt.Sprintf("%s[%d]", path, i)
+		if c.Class != "" {
+			c = fromClass(v, reservePath, c)
+		}
+		if c.Abilities == nil {
+			c.Abilities = library.DefaultAbilities(side)
+		}
+		v.Character(reservePath, c)
+		c.ID = uuid.New().String()
+		prepared = append(prepared, &c)
+	}
+	return prepared
+}
+
+// AIRequest picks which character the server plays and how well
+type AIRequest struct {
+	Character  int             `json:"Character"` // 1 or 2
+	Difficulty game.Difficulty `json:"Difficulty"`
+}
+
+// PauseRequest is the optional body of a pause request
+type PauseRequest struct {
+	Reason string `json:"Reason"`
 }
 
 // BattleResponse represents the JSON-safe version of a Battle
 type BattleResponse struct {
 	ID         string          `json:"ID"`
-	Character1 *game.Character `json:"Character1"`
-	Character2 *game.Character `json:"Character2"`
+	Character1 *game.CharacterView `json:"Character1"`
+	Character2 *game.CharacterView `json:"Character2"`
 	State      game.BattleState `json:"State"`
-	Winner     *game.Character `json:"Winner,omitempty"`
+	Winner     *game.CharacterView `json:"Winner,omitempty"`
 	Round      int            `json:"Round"`
+	Reserves1  []*game.CharacterView `json:"Reserves1,omitempty"`
+	Reserves2  []*game.CharacterView `json:"Reserves2,omitempty"`
+	Fled       *game.CharacterView   `json:"Fled,omitempty"`
+	PauseReason         string `json:"PauseReason,omitempty"`
+	TurnTimeRemainingMs int64  `json:"TurnTimeRemainingMs,omitempty"`
+	TurnMode            game.TurnMode `json:"TurnMode"`
+	Rules               game.RuleSet  `json:"Rules"`
+	CurrentTurn         string        `json:"CurrentTurn,omitempty"`
+	AIControlled        []string      `json:"AIControlled,omitempty"`
+	// Sealed lists who has chosen an action this round in simultaneous mode
+	Sealed []string `json:"Sealed,omitempty"`
+	// Gauges holds each character's gauge in ATB mode, by ID; a character
+	// may act once its gauge reaches GaugeFull
+	Gauges    map[string]int `json:"Gauges,omitempty"`
+	GaugeFull int            `json:"GaugeFull,omitempty"`
+	// Environment is the weather over the battlefield, if any
+	Environment *game.Environment `json:"Environment,omitempty"`
+	// Pending lists charges, channels and delayed abilities yet to land
+	Pending []game.PendingAbility `json:"Pending,omitempty"`
+	// Minions lists the summoned characters in battle, all played by the
+	// computer
+	Minions []game.MinionView `json:"Minions,omitempty"`
+	// Grid is the map of a tactical battle with everyone's position on it
+	Grid *game.Grid `json:"Grid,omitempty"`
+	CreatedAt time.Time `json:"CreatedAt"`
+	// PlayerTokens identify the players of sides 1 and 2. They are handed
+	// only to whoever creates the battle, to pass on to the players.
+	PlayerTokens []string `json:"PlayerTokens,omitempty"`
 }
 
-// Convert Battle to BattleResponse
-func toBattleResponse(b *game.Battle) BattleResponse {
+// toBattleResponse renders the battle as the given side sees it: 1 or 2
+// for a player, 0 for a spectator
+func toBattleResponse(b *game.Battle, side int) BattleResponse {
+	view := b.View(side)
+	var currentTurn string
+	if view.TurnMode != game.TurnModeSimultaneous && view.TurnMode != game.TurnModeATB {
+		currentTurn = view.CurrentTurn
+	}
+	var gaugeFull int
+	if view.TurnMode == game.TurnModeATB {
+		gaugeFull = game.GaugeFull
+	}
+
 	return BattleResponse{
-		ID:         b.ID,
-		Character1: b.Character1,
-		Character2: b.Character2,
-		State:      b.State,
-		Winner:     b.Winner,
-		Round:      b.Round,
+		ID:         view.ID,
+		Character1: view.Character1,
+		Character2: view.Character2,
+		State:      view.State,
+		Winner:     view.Winner,
+		Round:      view.Round,
+		Reserves1:  view.Reserves1,
+		Reserves2:  view.Reserves2,
+		Fled:       view.Fled,
+		PauseReason:         view.PauseReason,
+		TurnTimeRemainingMs: view.TurnTimeRemaining.Milliseconds(),
+		TurnMode:            view.TurnMode,
+		Rules:               view.Rules,
+		CurrentTurn:         currentTurn,
+		AIControlled:        view.AIControlled,
+		Sealed:              view.Sealed,
+		Gauges:              view.Gauges,
+		GaugeFull:           gaugeFull,
+		Environment:         view.Environment,
+		Pending:             view.Pending,
+		Minions:             view.Minions,
+		Grid:                view.Grid,
+		CreatedAt:           view.CreatedAt,
+	}
+}
+
+// healthText renders a character's health as its viewer may see it: exact,
+// or as a percentage where the rules hide it
+func healthText(c *game.CharacterView) string {
+	if c.HealthPercent != nil {
+		return fmt.Sprintf("%d%%", *c.HealthPercent)
 	}
+	return strconv.Itoa(c.Health)
 }
 
+// actionTimeout bounds how long an action request waits on the battle loop
+const actionTimeout = 5 * time.Second
+
 // BattleManager handles storing and retrieving battles
 type BattleManager struct {
 	battles map[string]*game.Battle
-	mu      sync.RWMutex
+	// tokens holds the player tokens of sides 1 and 2 of each battle, by ID
+	tokens map[string][2]string
+	mu     sync.RWMutex
+
+	// ctx is the parent of every battle loop started by the manager
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 func NewBattleManager() *BattleManager {
+	ctx, cancel := context.WithCancel(context.Background())
 	return &BattleManager{
 		battles: make(map[string]*game.Battle),
+		tokens:  make(map[string][2]string),
+		ctx:     ctx,
+		cancel:  cancel,
 	}
 }
 
-func (bm *BattleManager) CreateBattle(char1, char2 *game.Character) *game.Battle {
-	battle := game.NewBattle(char1, char2)
+func (bm *BattleManager) CreateBattle(char1, char2 *game.Character, opts ...game.BattleOption) (*game.Battle, error) {
+	battle, err := game.NewBattle(char1, char2, opts...)
+	if err != nil {
+		return nil, err
+	}
 	bm.mu.Lock()
 	bm.battles[battle.ID] = battle
+	bm.tokens[battle.ID] = [2]string{uuid.New().String(), uuid.New().String()}
 	bm.mu.Unlock()
-	return battle
+	return battle, nil
+}
+
+// PlayerTokens returns the tokens that identify the players of sides 1 and
+// 2 of a battle
+func (bm *BattleManager) PlayerTokens(id string) [2]string {
+	bm.mu.RLock()
+	defer bm.mu.RUnlock()
+	return bm.tokens[id]
+}
+
+// Viewer returns the side of the battle played by whoever made the request,
+// as named by its bearer token, or 0 for a spectator
+func (bm *BattleManager) Viewer(r *http.Request, id string) int {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return 0
+	}
+	for i, t := range bm.PlayerTokens(id) {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(t)) == 1 {
+			return i + 1
+		}
+	}
+	return 0
 }
 
 func (bm *BattleManager) GetBattle(id string) *game.Battle {
@@ -69,9 +314,142 @@ func (bm *BattleManager) GetBattle(id string) *game.Battle {
 	return bm.battles[id]
 }
 
+// BattleFilter narrows a listing of battles. Zero fields match every battle.
+type BattleFilter struct {
+	State game.BattleState
+	// Participant is the ID of a character that fights in the battle, in
+	// front or in reserve, or that fled it
+	Participant string
+	// Winner is the ID of the character that won the battle
+	Winner string
+	// CreatedAfter and CreatedBefore bound when the battle was created,
+	// both exclusive
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+}
+
+// matches reports whether the battle passes the filter
+func (f BattleFilter) matches(s game.BattleSummary) bool {
+	if f.State != "" && s.State != f.State {
+		return false
+	}
+	if f.Winner != "" && s.WinnerID != f.Winner {
+		return false
+	}
+	if !f.CreatedAfter.IsZero() && !s.CreatedAt.After(f.CreatedAfter) {
+		return false
+	}
+	if !f.CreatedBefore.IsZero() && !s.CreatedAt.Before(f.CreatedBefore) {
+		return false
+	}
+	return f.Participant == "" || slices.Contains(s.Participants, f.Participant)
+}
+
+// battleCursor marks the last battle of a page: the next page starts with
+// the battle that follows it
+type battleCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// cursorAt returns the cursor that marks the battle summarized by s
+func cursorAt(s game.BattleSummary) battleCursor {
+	return battleCursor{CreatedAt: s.CreatedAt, ID: s.ID}
+}
+
+// compare orders battles as they are listed: newest first, and by ID among
+// battles created at the same time. It goes by wall-clock time alone, which
+// is all an encoded cursor keeps.
+func (c battleCursor) compare(d battleCursor) int {
+	if n := cmp.Compare(d.CreatedAt.UnixNano(), c.CreatedAt.UnixNano()); n != 0 {
+		return n
+	}
+	return strings.Compare(c.ID, d.ID)
+}
+
+// encode returns the cursor as an opaque string
+func (c battleCursor) encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d|%s", c.CreatedAt.UnixNano(), c.ID)))
+}
+
+// decodeBattleCursor reads a cursor made by encode
+func decodeBattleCursor(s string) (battleCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return battleCursor{}, errors.New("not a cursor from a previous page")
+	}
+	nanos, id, ok := strings.Cut(string(raw), "|")
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if !ok || err != nil || id == "" {
+		return battleCursor{}, errors.New("not a cursor from a previous page")
+	}
+	return battleCursor{CreatedAt: time.Unix(0, n), ID: id}, nil
+}
+
+// ListBattles returns up to limit battles that pass the filter, newest
+// first, starting after the cursor if there is one. It also returns the
+// cursor of the next page, or nil on the last page. A limit below one lists
+// one battle a page.
+func (bm *BattleManager) ListBattles(filter BattleFilter, after *battleCursor, limit int) ([]*game.Battle, *battleCursor) {
+	limit = max(limit, 1)
+	type listed struct {
+		battle *game.Battle
+		cursor battleCursor
+	}
+	bm.mu.RLock()
+	var found []listed
+	for _, battle := range bm.battles {
+		summary := battle.Summary()
+		if (after == nil || cursorAt(summary).compare(*after) > 0) && filter.matches(summary) {
+			found = append(found, listed{battle, cursorAt(summary)})
+		}
+	}
+	bm.mu.RUnlock()
+
+	slices.SortFunc(found, func(a, b listed) int {
+		return a.cursor.compare(b.cursor)
+	})
+	var battles []*game.Battle
+	for _, l := range found[:min(limit, len(found))] {
+		battles = append(battles, l.battle)
+	}
+	if len(found) <= limit {
+		return battles, nil
+	}
+	return battles, &found[limit-1].cursor
+}
+
+// StartBattle starts the battle loop under the manager's context
+func (bm *BattleManager) StartBattle(battle *game.Battle) error {
+	return battle.Start(bm.ctx)
+}
+
+// Shutdown stops every battle and waits for their loops to exit
+func (bm *BattleManager) Shutdown() {
+	bm.cancel()
+
+	bm.mu.RLock()
+	defer bm.mu.RUnlock()
+	for _, battle := range bm.battles {
+		battle.Stop()
+	}
+}
+
 var battleManager = NewBattleManager()
 
+// library holds the game content loaded at startup
+var library *content.Library
+
 func main() {
+	contentDir := flag.String("content", "content", "directory of character, ability and effect definitions")
+	flag.Parse()
+
+	var err error
+	library, err = content.Load(*contentDir)
+	if err != nil {
+		log.Fatalf("Failed to load content: %v", err)
+	}
+
 	r := mux.NewRouter()
 
 	// CORS middleware
@@ -79,7 +457,8 @@ func main() {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Access-Control-Allow-Origin", "*")
 			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "*")
+			// A wildcard does not cover Authorization, so name it
+			w.Header().Set("Access-Control-Allow-Headers", "*, Authorization")
 			w.Header().Set("Access-Control-Max-Age", "86400")
 
 			if r.Method == "OPTIONS" {
@@ -99,8 +478,21 @@ func main() {
 
 	// Battle endpoints
 	api.HandleFunc("/battles", createBattleHandler).Methods("POST", "OPTIONS")
+	api.HandleFunc("/battles", listBattlesHandler).Methods("GET")
+	api.HandleFunc("/battles/{id}", getBattleHandler).Methods("GET", "OPTIONS")
 	api.HandleFunc("/battles/{id}/start", startBattleHandler).Methods("POST", "OPTIONS")
 	api.HandleFunc("/battles/{id}/action", submitActionHandler).Methods("POST", "OPTIONS")
+	api.HandleFunc("/battles/{id}/pause", pauseBattleHandler).Methods("POST", "OPTIONS")
+	api.HandleFunc("/battles/{id}/resume", resumeBattleHandler).Methods("POST", "OPTIONS")
+	api.HandleFunc("/battles/{id}/stream", streamBattleHandler).Methods("GET", "OPTIONS")
+
+	// Character endpoints
+	api.HandleFunc("/characters", createCharacterHandler).Methods("POST", "OPTIONS")
+	api.HandleFunc("/characters/{id}", getCharacterHandler).Methods("GET", "OPTIONS")
+	api.HandleFunc("/characters/{id}/progression", getProgressionHandler).Methods("GET", "OPTIONS")
+	api.HandleFunc("/characters/{id}/equipment", setEquipmentHandler).Methods("PUT", "OPTIONS")
+	api.HandleFunc("/classes", listClassesHandler).Methods("GET", "OPTIONS")
+	api.HandleFunc("/rulesets", listRuleSetsHandler).Methods("GET", "OPTIONS")
 
 	// Serve static files (for non-API routes)
 	fs := http.FileServer(http.Dir("static"))
@@ -108,14 +500,37 @@ func main() {
 	r.HandleFunc("/", serveIndex)
 
 	// Start server
-	fmt.Println("Server starting on :3000...")
-	log.Fatal(http.ListenAndServe(":3000", r))
+	srv := &http.Server{Addr: ":3000", Handler: r}
+	go func() {
+		fmt.Println("Server starting on :3000...")
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	// Wait for an interrupt, then drain requests and stop all battle loops
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+
+	log.Println("Shutting down...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Error shutting down server: %v", err)
+	}
+	battleManager.Shutdown()
 }
 
 func loggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		log.Printf("Request: %s %s", r.Method, r.URL.Path)
-		log.Printf("Headers: %v", r.Header)
+		// Player tokens stay out of the log
+		headers := r.Header.Clone()
+		if headers.Get("Authorization") != "" {
+			headers.Set("Authorization", "[redacted]")
+		}
+		log.Printf("Headers: %v", headers)
 		
 		// Read and log the request body for debugging
 		if r.Method == "POST" {
@@ -151,61 +566,151 @@ func createBattleHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Swap in persistent characters
+	for i, ref := range []struct {
+		id        string
+		character *game.Character
+	}{
+		{request.Character1ID, &request.Character1},
+		{request.Character2ID, &request.Character2},
+	} {
+		if ref.id == "" {
+			continue
+		}
+		profile := characterManager.GetCharacter(ref.id)
+		if profile == nil {
+			http.Error(w, fmt.Sprintf("Character%d not found: %s", i+1, ref.id), http.StatusNotFound)
+			return
+		}
+		c, err := combatCharacter(profile)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		*ref.character = c
+	}
+	if request.Character1ID != "" && request.Character1ID == request.Character2ID {
+		http.Error(w, "A character cannot battle itself", http.StatusBadRequest)
+		return
+	}
+
+	// Build characters from class and name
+	v := validation.New(validation.DefaultLimits)
+	if request.Character1ID == "" && request.Character1.Class != "" {
+		request.Character1 = fromClass(v, "Character1", request.Character1)
+	}
+	if request.Character2ID == "" && request.Character2.Class != "" {
+		request.Character2 = fromClass(v, "Character2", request.Character2)
+	}
+	if errs := v.Errors(); len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+
 	// Initialize abilities if they're nil
 	if request.Character1.Abilities == nil {
 		log.Printf("Initializing abilities for Character1")
-		request.Character1.Abilities = []game.Ability{
-			{
-				Name:        "Basic Attack",
-				Damage:      10,
-				CooldownMax: 0,
-			},
-			{
-				Name:        "Power Strike",
-				Damage:      20,
-				CooldownMax: 2,
-				StatusEffect: game.StatusEffectData{
-					Type:     game.StatusEnraged,
-					Duration: 2,
-					Potency:  20,
-				},
-			},
-		}
+		request.Character1.Abilities = library.DefaultAbilities(1)
 	}
 
 	if request.Character2.Abilities == nil {
 		log.Printf("Initializing abilities for Character2")
-		request.Character2.Abilities = []game.Ability{
-			{
-				Name:        "Basic Attack",
-				Damage:      8,
-				CooldownMax: 0,
-			},
-			{
-				Name:        "Fireball",
-				Damage:      15,
-				CooldownMax: 2,
-				StatusEffect: game.StatusEffectData{
-					Type:     game.StatusBurning,
-					Duration: 3,
-					Potency:  5,
-				},
-			},
-		}
+		request.Character2.Abilities = library.DefaultAbilities(2)
+	}
+
+	if request.Character1Equipment == nil && request.Character1ID != "" {
+		request.Character1Equipment = characterManager.Equipment(request.Character1ID)
+	}
+	if request.Character2Equipment == nil && request.Character2ID != "" {
+		request.Character2Equipment = characterManager.Equipment(request.Character2ID)
+	}
+
+	v.Character("Character1", request.Character1)
+	v.Character("Character2", request.Character2)
+	validateLoadout(v, "Character1Equipment", request.Character1Equipment)
+	validateLoadout(v, "Character2Equipment", request.Character2Equipment)
+	request.Character1.Inventory = append(request.Character1.Inventory, resolveItems(v, "Character1Items", request.Character1Items)...)
+	request.Character2.Inventory = append(request.Character2.Inventory, resolveItems(v, "Character2Items", request.Character2Items)...)
+	reserves1 := prepareReserves(v, "Character1Reserves", request.Character1Reserves, 1)
+	reserves2 := prepareReserves(v, "Character2Reserves", request.Character2Reserves, 2)
+	rules := battleRules(v, request)
+	if request.Environment != nil {
+		v.Environment("Environment", *request.Environment)
+	}
+	if g := request.Grid; g != nil {
+		v.Grid("Grid", g.Rows, map[string]game.Position{"Position1": g.Position1, "Position2": g.Position2})
+	}
+	if request.TurnTimeoutSeconds < 0 {
+		v.Add("TurnTimeoutSeconds", "must not be negative, got %d", request.TurnTimeoutSeconds)
+	}
+	if errs := v.Errors(); len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	// Dress the combat copies; the engine only sees the result
+	var err error
+	if request.Character1, err = equip(request.Character1, request.Character1Equipment); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if request.Character2, err = equip(request.Character2, request.Character2Equipment); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
 	// Log the received characters
 	log.Printf("Creating battle with characters: %+v vs %+v", request.Character1, request.Character2)
 
-	// Set character IDs
-	request.Character1.ID = uuid.New().String()
-	request.Character2.ID = uuid.New().String()
+	// Set character IDs, keeping those of persistent characters
+	if request.Character1ID == "" {
+		request.Character1.ID = uuid.New().String()
+	}
+	if request.Character2ID == "" {
+		request.Character2.ID = uuid.New().String()
+	}
+
+	opts := []game.BattleOption{
+		game.WithTurnTimeout(time.Duration(request.TurnTimeoutSeconds) * time.Second),
+		game.WithOnComplete(awardExperience(request.Character1.Clone(), request.Character2.Clone())),
+	}
+	opts = append(opts, game.WithRules(rules))
+	if request.Environment != nil {
+		opts = append(opts, game.WithEnvironment(request.Environment.Weather, request.Environment.Duration))
+	}
+	if g := request.Grid; g != nil {
+		opts = append(opts, game.WithGrid(g.Rows, g.Position1, g.Position2))
+	}
+	if len(reserves1) > 0 {
+		opts = append(opts, game.WithReserves(1, reserves1...))
+	}
+	if len(reserves2) > 0 {
+		opts = append(opts, game.WithReserves(2, reserves2...))
+	}
+
+	if request.AI != nil {
+		strategy, err := game.StrategyForDifficulty(request.AI.Difficulty, nil)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		switch request.AI.Character {
+		case 1:
+			opts = append(opts, game.WithAI(&request.Character1, strategy))
+		case 2:
+			opts = append(opts, game.WithAI(&request.Character2, strategy))
+		default:
+			http.Error(w, "AI.Character must be 1 or 2", http.StatusBadRequest)
+			return
+		}
+	}
 
 	// Create new battle
-	battle := battleManager.CreateBattle(&request.Character1, &request.Character2)
-	if battle == nil {
-		log.Printf("Error creating battle: battle is nil")
-		http.Error(w, "Failed to create battle", http.StatusInternalServerError)
+	battle, err := battleManager.CreateBattle(&request.Character1, &request.Character2, opts...)
+	if err != nil {
+		// The request was validated, so this is the server's own doing
+		log.Printf("Error creating battle: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to create battle: %v", err), http.StatusInternalServerError)
 		return
 	}
 
@@ -215,12 +720,166 @@ func createBattleHandler(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Character2 ID: %s", battle.Character2.ID)
 
 	// Convert to response and return
-	response := toBattleResponse(battle)
+	response := toBattleResponse(battle, 0)
+	tokens := battleManager.PlayerTokens(battle.ID)
+	response.PlayerTokens = tokens[:]
 	log.Printf("Sending battle response: %+v", response)
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(response)
 }
 
+// getBattleHandler returns a battle as the caller sees it
+func getBattleHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	battleID := mux.Vars(r)["id"]
+	battle := battleManager.GetBattle(battleID)
+	if battle == nil {
+		http.Error(w, fmt.Sprintf("Battle not found: %s", battleID), http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(toBattleResponse(battle, battleManager.Viewer(r, battleID)))
+}
+
+// Page sizes of a battle listing
+const (
+	defaultBattlePage = 20
+	maxBattlePage     = 100
+)
+
+// BattleListResponse is a page of battles. NextCursor, passed back as the
+// cursor parameter, fetches the next page; it is empty on the last page.
+type BattleListResponse struct {
+	Battles    []BattleResponse `json:"Battles"`
+	NextCursor string           `json:"NextCursor,omitempty"`
+}
+
+// listBattlesHandler lists battles newest first, filtered by the query
+// parameters state, participant, winner, createdAfter and createdBefore
+// (RFC 3339 times), a page of limit at a time from cursor
+func listBattlesHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	query := r.URL.Query()
+	v := validation.New(validation.DefaultLimits)
+	filter := BattleFilter{
+		State:       game.BattleState(query.Get("state")),
+		Participant: query.Get("participant"),
+		Winner:      query.Get("winner"),
+	}
+	switch filter.State {
+	case "", game.BattleStatePending, game.BattleStateActive, game.BattleStatePaused, game.BattleStateComplete:
+	default:
+		v.Add("state", "unknown battle state %q", filter.State)
+	}
+	for _, bound := range []struct {
+		param string
+		t     *time.Time
+	}{
+		{"createdAfter", &filter.CreatedAfter},
+		{"createdBefore", &filter.CreatedBefore},
+	} {
+		if value := query.Get(bound.param); value != "" {
+			t, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				v.Add(bound.param, "must be an RFC 3339 time, got %q", value)
+			}
+			*bound.t = t
+		}
+	}
+	limit := defaultBattlePage
+	if value := query.Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxBattlePage {
+			v.Add("limit", "must be between 1 and %d, got %q", maxBattlePage, value)
+		}
+		limit = n
+	}
+	var after *battleCursor
+	if value := query.Get("cursor"); value != "" {
+		cursor, err := decodeBattleCursor(value)
+		if err != nil {
+			v.Add("cursor", "%v", err)
+		}
+		after = &cursor
+	}
+	if errs := v.Errors(); len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	battles, next := battleManager.ListBattles(filter, after, limit)
+	response := BattleListResponse{Battles: []BattleResponse{}}
+	for _, battle := range battles {
+		response.Battles = append(response.Battles, toBattleResponse(battle, battleManager.Viewer(r, battle.ID)))
+	}
+	if next != nil {
+		response.NextCursor = next.encode()
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// listRuleSetsHandler lists the preset rule sets a battle may name
+func listRuleSetsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(game.Presets())
+}
+
+// streamInterval is how often a battle stream sends the battle state, which
+// matches the battle loop's tick so that ATB gauges move smoothly
+const streamInterval = 100 * time.Millisecond
+
+// streamBattleHandler sends the battle state as server-sent events until the
+// battle completes or the client goes away
+func streamBattleHandler(w http.ResponseWriter, r *http.Request) {
+	battle := battleManager.GetBattle(mux.Vars(r)["id"])
+	if battle == nil {
+		http.Error(w, "Battle not found", http.StatusNotFound)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	side := battleManager.Viewer(r, battle.ID)
+	ticker := time.NewTicker(streamInterval)
+	defer ticker.Stop()
+	for {
+		response := toBattleResponse(battle, side)
+		data, err := json.Marshal(response)
+		if err != nil {
+			log.Printf("Error encoding battle %s: %v", battle.ID, err)
+			return
+		}
+		fmt.Fprintf(w, "data: %s\n\n", data)
+		flusher.Flush()
+		if response.State == game.BattleStateComplete {
+			return
+		}
+
+		select {
+		case <-ticker.C:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// ValidationErrorResponse is the body of a 422 response
+type ValidationErrorResponse struct {
+	Errors validation.Errors `json:"Errors"`
+}
+
+func writeValidationErrors(w http.ResponseWriter, errs validation.Errors) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	json.NewEncoder(w).Encode(ValidationErrorResponse{Errors: errs})
+}
+
 func startBattleHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	
@@ -234,16 +893,65 @@ func startBattleHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := battle.Start(); err != nil {
+	if err := battleManager.StartBattle(battle); err != nil {
 		http.Error(w, fmt.Sprintf("Failed to start battle: %v", err), http.StatusBadRequest)
 		return
 	}
 
-	response := toBattleResponse(battle)
+	response := toBattleResponse(battle, battleManager.Viewer(r, battleID))
 	log.Printf("Battle started successfully: %+v", response)
 	json.NewEncoder(w).Encode(response)
 }
 
+func pauseBattleHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	battleID := vars["id"]
+
+	battle := battleManager.GetBattle(battleID)
+	if battle == nil {
+		http.Error(w, fmt.Sprintf("Battle not found: %s", battleID), http.StatusNotFound)
+		return
+	}
+
+	// The reason is optional, so an empty body is fine
+	var request PauseRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && err != io.EOF {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := battle.Pause(request.Reason); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to pause battle: %v", err), http.StatusConflict)
+		return
+	}
+
+	log.Printf("Battle %s paused: %s", battleID, request.Reason)
+	json.NewEncoder(w).Encode(toBattleResponse(battle, battleManager.Viewer(r, battleID)))
+}
+
+func resumeBattleHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	battleID := vars["id"]
+
+	battle := battleManager.GetBattle(battleID)
+	if battle == nil {
+		http.Error(w, fmt.Sprintf("Battle not found: %s", battleID), http.StatusNotFound)
+		return
+	}
+
+	if err := battle.Resume(); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to resume battle: %v", err), http.StatusConflict)
+		return
+	}
+
+	log.Printf("Battle %s resumed", battleID)
+	json.NewEncoder(w).Encode(toBattleResponse(battle, battleManager.Viewer(r, battleID)))
+}
+
 func submitActionHandler(w http.ResponseWriter, r *http.Request) {
     // Check if this is an HTMX request
     if r.Header.Get("HX-Request") == "true" {
@@ -275,61 +983,87 @@ func submitActionHandler(w http.ResponseWriter, r *http.Request) {
         return
     }
 
+    // Only a player may act, and only for their own side
+    side := battleManager.Viewer(r, battleID)
+    if side == 0 || battle.SideOf(action.CharacterID) != side {
+        http.Error(w, fmt.Sprintf("Not allowed to act for character %s", action.CharacterID), http.StatusForbidden)
+        return
+    }
+
     // Handle target selection from form data for HTMX requests
     if r.Header.Get("HX-Request") == "true" {
         char1Target := r.FormValue("char1-target")
         char2Target := r.FormValue("char2-target")
+        view := battle.View(side)
         
         // Set target based on which character is acting
-        if action.CharacterID == battle.Character1.ID {
+        if action.CharacterID == view.Character1.ID {
             if char1Target == "self" {
-                action.TargetID = battle.Character1.ID
+                action.TargetID = view.Character1.ID
             } else {
-                action.TargetID = battle.Character2.ID
+                action.TargetID = view.Character2.ID
             }
         } else {
             if char2Target == "self" {
-                action.TargetID = battle.Character2.ID
+                action.TargetID = view.Character2.ID
             } else {
-                action.TargetID = battle.Character1.ID
+                action.TargetID = view.Character1.ID
             }
         }
     }
 
-    result := battle.SubmitAction(action)
+    ctx, cancel := context.WithTimeout(r.Context(), actionTimeout)
+    defer cancel()
+
+    result, err := battle.SubmitAction(ctx, action)
+    if err != nil {
+        status := http.StatusConflict
+        if errors.Is(err, context.DeadlineExceeded) {
+            status = http.StatusGatewayTimeout
+        }
+        http.Error(w, fmt.Sprintf("Failed to submit action: %v", err), status)
+        return
+    }
     
     if r.Header.Get("HX-Request") == "true" {
-        // Return updated battle view HTML
+        // Return updated battle view HTML, as the caller's side sees it
+        view := battle.View(side)
+        char1, char2 := view.Character1, view.Character2
+
         char1StatusEffects := ""
-        if len(battle.Character1.StatusEffects) > 0 {
-            effects := make([]string, len(battle.Character1.StatusEffects))
-            for i, effect := range battle.Character1.StatusEffects {
+        if len(char1.StatusEffects) > 0 {
+            effects := make([]string, len(char1.StatusEffects))
+            for i, effect := range char1.StatusEffects {
                 effects[i] = string(effect.Type)
             }
             char1StatusEffects = fmt.Sprintf("Status Effects: %s", strings.Join(effects, ", "))
         }
 
         char2StatusEffects := ""
-        if len(battle.Character2.StatusEffects) > 0 {
-            effects := make([]string, len(battle.Character2.StatusEffects))
-            for i, effect := range battle.Character2.StatusEffects {
+        if len(char2.StatusEffects) > 0 {
+            effects := make([]string, len(char2.StatusEffects))
+            for i, effect := range char2.StatusEffects {
                 effects[i] = string(effect.Type)
             }
             char2StatusEffects = fmt.Sprintf("Status Effects: %s", strings.Join(effects, ", "))
         }
 
-        disabledAttr := ""
-        if battle.State == game.BattleStateComplete {
-            disabledAttr = "disabled"
+        // Only the caller's own side can act, and nobody once it is over
+        char1Disabled, char2Disabled := "", ""
+        if view.State == game.BattleStateComplete || side != 1 {
+            char1Disabled = "disabled"
+        }
+        if view.State == game.BattleStateComplete || side != 2 {
+            char2Disabled = "disabled"
         }
 
         tmpl := `
-        <div id="battle-view">
+        <div id="battle-view" hx-headers='{"Authorization":"Bearer %s"}'>
             <div class="battle-container">
                 <div class="character" id="char1">
                     <h2>%s</h2>
                     <div class="stats">
-                        Health: %d<br>
+                        Health: %s<br>
                         Attack: %d<br>
                         Defense: %d<br>
                         Speed: %d
@@ -365,7 +1099,7 @@ func submitActionHandler(w http.ResponseWriter, r *http.Request) {
                 <div class="character" id="char2">
                     <h2>%s</h2>
                     <div class="stats">
-                        Health: %d<br>
+                        Health: %s<br>
                         Attack: %d<br>
                         Defense: %d<br>
                         Speed: %d
@@ -403,15 +1137,15 @@ func submitActionHandler(w http.ResponseWriter, r *http.Request) {
         </div>`
 
         // For character 1's buttons
-        char1Target := battle.Character2.ID // Default to enemy
+        char1Target := char2.ID // Default to enemy
         if r.FormValue("char1-target") == "self" {
-            char1Target = battle.Character1.ID
+            char1Target = char1.ID
         }
 
         // For character 2's buttons
-        char2Target := battle.Character1.ID // Default to enemy
+        char2Target := char1.ID // Default to enemy
         if r.FormValue("char2-target") == "self" {
-            char2Target = battle.Character2.ID
+            char2Target = char2.ID
         }
 
         battleLog := ""
@@ -419,38 +1153,78 @@ func submitActionHandler(w http.ResponseWriter, r *http.Request) {
             battleLog = fmt.Sprintf("<div>%s</div>", result.Message)
         }
 
+        // The buttons act again as the same player, so they carry the token
         fmt.Fprintf(w, tmpl,
+            battleManager.PlayerTokens(battleID)[side-1],
             // Character 1
-            battle.Character1.Name,
-            battle.Character1.Health,
-            battle.Character1.Attack,
-            battle.Character1.Defense,
-            battle.Character1.Speed,
+            char1.Name,
+            healthText(char1),
+            char1.Attack,
+            char1.Defense,
+            char1.Speed,
             char1StatusEffects,
             // Character 1 Basic Attack
-            battle.ID, battle.Character1.ID, char1Target, disabledAttr,
+            view.ID, char1.ID, char1Target, char1Disabled,
             // Character 1 Special Attack
-            battle.ID, battle.Character1.ID, char1Target, disabledAttr,
+            view.ID, char1.ID, char1Target, char1Disabled,
             // Character 2
-            battle.Character2.Name,
-            battle.Character2.Health,
-            battle.Character2.Attack,
-            battle.Character2.Defense,
-            battle.Character2.Speed,
+            char2.Name,
+            healthText(char2),
+            char2.Attack,
+            char2.Defense,
+            char2.Speed,
             char2StatusEffects,
             // Character 2 Basic Attack
-            battle.ID, battle.Character2.ID, char2Target, disabledAttr,
+            view.ID, char2.ID, char2Target, char2Disabled,
             // Character 2 Special Attack
-            battle.ID, battle.Character2.ID, char2Target, disabledAttr,
+            view.ID, char2.ID, char2Target, char2Disabled,
             // Battle log
             battleLog)
     } else {
         // Return JSON response for non-HTMX requests
-        response := map[string]interface{}{
-            "success": result.Success,
-            "message": result.Message,
-            "battle":  toBattleResponse(battle),
+        response := actionResultJSON(result)
+        response["battle"] = toBattleResponse(battle, side)
+        if result.Sealed {
+            response["sealed"] = true
+        }
+        if result.Resolution != nil {
+            resolution := make([]map[string]interface{}, len(result.Resolution))
+            for i, resolved := range result.Resolution {
+                resolution[i] = actionResultJSON(resolved.Result)
+                resolution[i]["characterId"] = resolved.CharacterID
+            }
+            response["resolution"] = resolution
         }
         json.NewEncoder(w).Encode(response)
     }
 }
+
+// actionResultJSON describes what an action did
+func actionResultJSON(result game.BattleActionResult) map[string]interface{} {
+    response := map[string]interface{}{
+        "success": result.Success,
+        "message": result.Message,
+    }
+    if result.Ability != nil {
+        response["ability"] = result.Ability
+    }
+    if result.Item != nil {
+        response["item"] = result.Item
+    }
+    if result.Defend != nil {
+        response["defend"] = result.Defend
+    }
+    if result.Wait != nil {
+        response["wait"] = result.Wait
+    }
+    if result.Swap != nil {
+        response["swap"] = result.Swap
+    }
+    if result.Flee != nil {
+        response["flee"] = result.Flee
+    }
+    if result.Move != nil {
+        response["move"] = result.Move
+    }
+    return response
+}
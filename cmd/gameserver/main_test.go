@@ -0,0 +1,276 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/MaterDev/golang_turnbased_game_spike/internal/game"
+	"github.com/MaterDev/golang_turnbased_game_spike/internal/validation"
+	"github.com/gorilla/mux"
+)
+
+var listEpoch = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func createTestCharacter(id string) *game.Character {
+	return &game.Character{
+		ID:        id,
+		Name:      id,
+		Health:    100,
+		MaxHealth: 100,
+		Attack:    10,
+		Defense:   5,
+		Speed:     10,
+		Abilities: []game.Ability{{Name: "Basic Attack", Damage: 10}},
+	}
+}
+
+// useBattleManager points the handlers at a fresh manager for one test
+func useBattleManager(t *testing.T) *BattleManager {
+	t.Helper()
+	previous := battleManager
+	battleManager = NewBattleManager()
+	t.Cleanup(func() { battleManager = previous })
+	return battleManager
+}
+
+// addBattle adds a battle with the given ID, created offset after
+// listEpoch, whose front characters are <id>-1 and <id>-2
+func addBattle(bm *BattleManager, id string, offset time.Duration) *game.Battle {
+	battle, err := game.NewBattle(createTestCharacter(id+"-1"), createTestCharacter(id+"-2"))
+	if err != nil {
+		panic(err)
+	}
+	battle.ID = id
+	battle.CreatedAt = listEpoch.Add(offset)
+	bm.battles[id] = battle
+	return battle
+}
+
+// listBattles calls the list handler with the given query
+func listBattles(t *testing.T, query string) (int, BattleListResponse, validation.Errors) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	listBattlesHandler(rec, httptest.NewRequest(http.MethodGet, "/api/battles?"+query, nil))
+
+	var page BattleListResponse
+	var invalid ValidationErrorResponse
+	if rec.Code == http.StatusOK {
+		if err := json.NewDecoder(rec.Body).Decode(&page); err != nil {
+			t.Fatalf("decoding page: %v", err)
+		}
+	} else if err := json.NewDecoder(rec.Body).Decode(&invalid); err != nil {
+		t.Fatalf("decoding errors: %v", err)
+	}
+	return rec.Code, page, invalid.Errors
+}
+
+func battleIDs(page BattleListResponse) []string {
+	var ids []string
+	for _, battle := range page.Battles {
+		ids = append(ids, battle.ID)
+	}
+	return ids
+}
+
+func TestListBattlesHandler_Filters(t *testing.T) {
+	bm := useBattleManager(t)
+	a := addBattle(bm, "a", 0)
+	a.State = game.BattleStateComplete
+	a.Winner = a.Character1
+	b := addBattle(bm, "b", time.Second)
+	b.State = game.BattleStateActive
+	b.Reserves1 = []*game.Character{createTestCharacter("b-reserve")}
+	c := addBattle(bm, "c", 2*time.Second)
+	c.Fled = createTestCharacter("c-fled")
+
+	at := func(offset time.Duration) string {
+		return listEpoch.Add(offset).Format(time.RFC3339Nano)
+	}
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"no filter", "", []string{"c", "b", "a"}},
+		{"state", "state=COMPLETE", []string{"a"}},
+		{"state pending", "state=PENDING", []string{"c"}},
+		{"winner", "winner=a-1", []string{"a"}},
+		{"winner who lost", "winner=a-2", nil},
+		{"participant in front", "participant=b-2", []string{"b"}},
+		{"participant in reserve", "participant=b-reserve", []string{"b"}},
+		{"participant who fled", "participant=c-fled", []string{"c"}},
+		{"created after, exclusive", "createdAfter=" + at(0), []string{"c", "b"}},
+		{"created before, exclusive", "createdBefore=" + at(2*time.Second), []string{"b", "a"}},
+		{"created between", "createdAfter=" + at(0) + "&createdBefore=" + at(2*time.Second), []string{"b"}},
+		{"combined", "state=ACTIVE&participant=a-1", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, page, errs := listBattles(t, tt.query)
+			if code != http.StatusOK {
+				t.Fatalf("status = %d (%v), want 200", code, errs)
+			}
+			if got := battleIDs(page); !slices.Equal(got, tt.want) {
+				t.Errorf("battles = %v, want %v", got, tt.want)
+			}
+			if page.NextCursor != "" {
+				t.Errorf("NextCursor = %q on the only page", page.NextCursor)
+			}
+		})
+	}
+}
+
+func TestListBattlesHandler_Pages(t *testing.T) {
+	bm := useBattleManager(t)
+	addBattle(bm, "oldest", 0)
+	addBattle(bm, "tie-b", time.Second)
+	addBattle(bm, "tie-a", time.Second)
+	addBattle(bm, "tie-c", time.Second)
+	addBattle(bm, "newest", 2*time.Second)
+
+	var got []string
+	query := "limit=2"
+	for pages := 0; ; pages++ {
+		if pages > 5 {
+			t.Fatal("paging did not end")
+		}
+		code, page, errs := listBattles(t, query)
+		if code != http.StatusOK {
+			t.Fatalf("status = %d (%v), want 200", code, errs)
+		}
+		got = append(got, battleIDs(page)...)
+		if page.NextCursor == "" {
+			if len(page.Battles) == 0 {
+				t.Error("Expected the last page to hold battles")
+			}
+			break
+		}
+		query = "limit=2&cursor=" + page.NextCursor
+	}
+
+	want := []string{"newest", "tie-a", "tie-b", "tie-c", "oldest"}
+	if !slices.Equal(got, want) {
+		t.Errorf("battles = %v, want %v", got, want)
+	}
+}
+
+func TestListBattlesHandler_LastPageIsFull(t *testing.T) {
+	bm := useBattleManager(t)
+	addBattle(bm, "a", 0)
+	addBattle(bm, "b", time.Second)
+
+	_, page, _ := listBattles(t, "limit=2")
+	if len(page.Battles) != 2 || page.NextCursor != "" {
+		t.Errorf("Expected one full page and no cursor, got %v and %q", battleIDs(page), page.NextCursor)
+	}
+}
+
+func TestBattleCursor_RoundTrip(t *testing.T) {
+	cursor := battleCursor{CreatedAt: listEpoch.Add(123456789), ID: "battle|with|bars"}
+	got, err := decodeBattleCursor(cursor.encode())
+	if err != nil {
+		t.Fatalf("decodeBattleCursor() error = %v", err)
+	}
+	if !got.CreatedAt.Equal(cursor.CreatedAt) || got.ID != cursor.ID || got.compare(cursor) != 0 {
+		t.Errorf("decodeBattleCursor() = %+v, want %+v", got, cursor)
+	}
+}
+
+func TestListBattlesHandler_Invalid(t *testing.T) {
+	useBattleManager(t)
+	encode := func(s string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(s))
+	}
+	tests := []struct {
+		name  string
+		query string
+		field string
+	}{
+		{"cursor not base64", "cursor=!!!", "cursor"},
+		{"cursor without separator", "cursor=" + encode("12345"), "cursor"},
+		{"cursor without ID", "cursor=" + encode("12345|"), "cursor"},
+		{"cursor without time", "cursor=" + encode("soon|abc"), "cursor"},
+		{"limit zero", "limit=0", "limit"},
+		{"limit too big", "limit=101", "limit"},
+		{"limit not a number", "limit=ten", "limit"},
+		{"unknown state", "state=FINISHED", "state"},
+		{"created after not a time", "createdAfter=yesterday", "createdAfter"},
+		{"created before not a time", "createdBefore=2024-01-01", "createdBefore"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, errs := listBattles(t, tt.query)
+			if code != http.StatusUnprocessableEntity {
+				t.Fatalf("status = %d, want 422", code)
+			}
+			if len(errs) != 1 || errs[0].Field != tt.field {
+				t.Errorf("errors = %v, want one for %s", errs, tt.field)
+			}
+		})
+	}
+
+	for _, limit := range []string{"1", "100"} {
+		if code, _, errs := listBattles(t, "limit="+limit); code != http.StatusOK {
+			t.Errorf("limit=%s: status = %d (%v), want 200", limit, code, errs)
+		}
+	}
+}
+
+func TestGetBattleHandler(t *testing.T) {
+	bm := useBattleManager(t)
+	addBattle(bm, "known", 0)
+
+	tests := []struct {
+		id   string
+		want int
+	}{
+		{"known", http.StatusOK},
+		{"unknown", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/api/battles/"+tt.id, nil), map[string]string{"id": tt.id})
+		getBattleHandler(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("GET %s: status = %d, want %d", tt.id, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestCreateBattleHandler_NegativeTurnTimeout(t *testing.T) {
+	useBattleManager(t)
+	body, _ := json.Marshal(BattleRequest{
+		Character1:         *createTestCharacter("a"),
+		Character2:         *createTestCharacter("b"),
+		TurnTimeoutSeconds: -5,
+	})
+	rec := httptest.NewRecorder()
+	createBattleHandler(rec, httptest.NewRequest(http.MethodPost, "/api/battles", bytes.NewReader(body)))
+
+	var invalid ValidationErrorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&invalid); err != nil || rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d (%v), want 422 with field errors", rec.Code, err)
+	}
+	if len(invalid.Errors) != 1 || invalid.Errors[0].Field != "TurnTimeoutSeconds" {
+		t.Errorf("errors = %v, want one for TurnTimeoutSeconds", invalid.Errors)
+	}
+}
+
+func TestBattleManager_ListBattlesLimit(t *testing.T) {
+	bm := NewBattleManager()
+	addBattle(bm, "a", 0)
+	addBattle(bm, "b", time.Second)
+
+	for _, limit := range []int{0, -3} {
+		battles, next := bm.ListBattles(BattleFilter{}, nil, limit)
+		if len(battles) != 1 || battles[0].ID != "b" || next == nil {
+			t.Errorf("ListBattles(limit %d) = %d battles, cursor %v, want a page of one", limit, len(battles), next)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+// Command simulate plays many battles between computer strategies without
+// the game server and reports how characters and abilities fare, to help
+// balance their numbers.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/MaterDev/golang_turnbased_game_spike/internal/content"
+	"github.com/MaterDev/golang_turnbased_game_spike/internal/game"
+)
+
+// entrant is a character played by a particular strategy
+type entrant struct {
+	Character game.Character
+	Strategy  string
+}
+
+func (e entrant) String() string {
+	return e.Character.Name + "/" + e.Strategy
+}
+
+// outcome is the result of a single simulated battle
+type outcome struct {
+	matchup int
+	winner  int // 1 or 2, or 0 for a draw
+	rounds  int
+	turns   int
+	uses    []abilityUse
+}
+
+// abilityUse is one ability used during a battle
+type abilityUse struct {
+	character string
+	ability   string
+	damage    int
+}
+
+type config struct {
+	battles    int
+	maxTurns   int
+	seed       uint64
+	searchSize int
+	workers    int
+	rules      game.RuleSet
+}
+
+func main() {
+	contentDir := flag.String("content", "content", "directory of character, ability and effect definitions")
+	strategies := flag.String("strategies", "random,greedy,cooldown,effect", "comma separated strategies to pit against each other")
+	battles := flag.Int("n", 1000, "battles per matchup")
+	maxTurns := flag.Int("max-turns", 500, "turns before a battle is called a draw")
+	seed := flag.Uint64("seed", 1, "random seed, for reproducible runs")
+	searchNodes := flag.Int("search-nodes", 2000, "node budget per move for the minimax and mcts strategies")
+	format := flag.String("format", "text", "output format: text, csv or json")
+	workers := flag.Int("workers", runtime.GOMAXPROCS(0), "battles to run in parallel")
+	ruleSet := flag.String("rules", "classic", "preset rule set to play by")
+	flag.Parse()
+
+	rules, ok := game.Preset(*ruleSet)
+	if !ok {
+		log.Fatalf("unknown rule set %q", *ruleSet)
+	}
+
+	library, err := content.Load(*contentDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	characters := library.Characters()
+
+	var entrants []entrant
+	for _, name := range strings.Split(*strategies, ",") {
+		name = strings.TrimSpace(name)
+		if _, err := newStrategy(name, *searchNodes, nil); err != nil {
+			log.Fatal(err)
+		}
+		for _, c := range characters {
+			entrants = append(entrants, entrant{Character: c, Strategy: name})
+		}
+	}
+	if len(entrants) == 0 {
+		log.Fatal("nothing to simulate: no characters or strategies")
+	}
+
+	cfg := config{
+		battles:    *battles,
+		maxTurns:   *maxTurns,
+		seed:       *seed,
+		searchSize: *searchNodes,
+		workers:    max(*workers, 1),
+		rules:      rules,
+	}
+	report := simulate(entrants, cfg)
+
+	switch *format {
+	case "text":
+		err = report.writeText(os.Stdout)
+	case "csv":
+		err = report.writeCSV(os.Stdout)
+	case "json":
+		err = report.writeJSON(os.Stdout)
+	default:
+		err = fmt.Errorf("unknown format %q", *format)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// newStrategy builds a strategy, giving the search strategies a node budget
+// so runs stay reproducible
+func newStrategy(name string, searchNodes int, rng *rand.Rand) (game.Strategy, error) {
+	budget := game.SearchBudget{MaxNodes: searchNodes}
+	switch name {
+	case "minimax":
+		return game.MinimaxStrategy{Budget: budget}, nil
+	case "mcts":
+		return game.NewMCTSStrategy(budget, rng), nil
+	}
+	return game.NewStrategy(name, rng)
+}
+
+// simulate plays every entrant against every entrant, itself included, and
+// gathers the results
+func simulate(entrants []entrant, cfg config) *report {
+	type job struct{ matchup, battle int }
+	jobs := make(chan job)
+	outcomes := make(chan outcome)
+
+	var wg sync.WaitGroup
+	for w := 0; w < cfg.workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				first := entrants[j.matchup/len(entrants)]
+				second := entrants[j.matchup%len(entrants)]
+				outcomes <- runBattle(first, second, j.matchup, j.battle, cfg)
+			}
+		}()
+	}
+
+	go func() {
+		for m := 0; m < len(entrants)*len(entrants); m++ {
+			for i := 0; i < cfg.battles; i++ {
+				jobs <- job{matchup: m, battle: i}
+			}
+		}
+		close(jobs)
+		wg.Wait()
+		close(outcomes)
+	}()
+
+	r := newReport(entrants, cfg.battles)
+	for o := range outcomes {
+		r.add(o)
+	}
+	return r
+}
+
+// runBattle plays one battle between fresh copies of both entrants. Each
+// battle gets its own seeds so results do not depend on scheduling.
+func runBattle(first, second entrant, matchup, index int, cfg config) outcome {
+	stream := uint64(matchup*cfg.battles+index) * 2
+	s1, _ := newStrategy(first.Strategy, cfg.searchSize, rand.New(rand.NewPCG(cfg.seed, stream)))
+	s2, _ := newStrategy(second.Strategy, cfg.searchSize, rand.New(rand.NewPCG(cfg.seed, stream+1)))
+
+	char1, char2 := first.Character.Clone(), second.Character.Clone()
+	char1.ID, char2.ID = "1", "2"
+	battle, err := game.NewBattle(&char1, &char2, game.WithRules(cfg.rules), game.WithAI(&char1, s1), game.WithAI(&char2, s2))
+	if err == nil {
+		err = battle.Play(cfg.maxTurns)
+	}
+	if err != nil {
+		// Both only fail on a misconfigured battle, and the rules are presets
+		panic(err)
+	}
+
+	o := outcome{matchup: matchup, rounds: battle.Round}
+	switch battle.Winner {
+	case &char1:
+		o.winner = 1
+	case &char2:
+		o.winner = 2
+	}
+
+	history := battle.History()
+	o.turns = len(history)
+	for _, record := range history {
+		// Items, defending, moving and the like count towards turns only
+		if record.Kind != game.ActionAbility {
+			continue
+		}
+		var actor *game.Character
+		switch record.CharacterID {
+		case char1.ID:
+			actor = &char1
+		case char2.ID:
+			actor = &char2
+		default:
+			// Minions count towards turns but not their summoner's abilities
+			continue
+		}
+		o.uses = append(o.uses, abilityUse{
+			character: actor.Name,
+			ability:   actor.Abilities[record.AbilityIndex].Name,
+			damage:    record.Damage,
+		})
+	}
+	return o
+}
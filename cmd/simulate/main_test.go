@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/MaterDev/golang_turnbased_game_spike/internal/game"
+)
+
+func testEntrants() []entrant {
+	strong := game.Character{
+		Name: "Knight", Health: 200, MaxHealth: 200, Attack: 20, Defense: 10, Speed: 10,
+		Abilities: []game.Ability{{Name: "Slash", Damage: 10}},
+	}
+	weak := game.Character{
+		Name: "Squire", Health: 50, MaxHealth: 50, Attack: 5, Defense: 5, Speed: 5,
+		Abilities: []game.Ability{{Name: "Poke", Damage: 5}},
+	}
+	return []entrant{{Character: strong, Strategy: "greedy"}, {Character: weak, Strategy: "greedy"}}
+}
+
+func TestSimulate_Totals(t *testing.T) {
+	cfg := config{battles: 5, maxTurns: 200, seed: 1, searchSize: 100, workers: 2, rules: game.ClassicRules}
+	r := simulate(testEntrants(), cfg)
+
+	if r.Battles != 20 {
+		t.Errorf("Battles = %d, want 5 for each of 4 matchups", r.Battles)
+	}
+	for _, m := range r.Matchups {
+		if m.Battles != 5 || m.Wins1+m.Wins2+m.Draws != 5 {
+			t.Errorf("%s vs %s: %d battles, %d+%d wins and %d draws, want 5 in all",
+				m.Entrant1, m.Entrant2, m.Battles, m.Wins1, m.Wins2, m.Draws)
+		}
+	}
+	// Matchups run row by row: Knight against Knight, then against Squire
+	if m := r.Matchups[1]; m.Wins1 != 5 || m.WinRate1 != 1 {
+		t.Errorf("Knight vs Squire: %d wins, rate %v, want the Knight to win every time", m.Wins1, m.WinRate1)
+	}
+	if m := r.Matchups[2]; m.Wins2 != 5 {
+		t.Errorf("Squire vs Knight: %d wins for the Knight, want 5", m.Wins2)
+	}
+	if len(r.Abilities) != 2 || r.Abilities[0].Uses == 0 || r.Abilities[1].Uses == 0 {
+		t.Errorf("Abilities = %+v, want Slash and Poke used", r.Abilities)
+	}
+}
+
+func TestSimulate_Draws(t *testing.T) {
+	cfg := config{battles: 3, maxTurns: 2, seed: 1, searchSize: 100, workers: 1, rules: game.ClassicRules}
+	r := simulate(testEntrants(), cfg)
+
+	for _, m := range r.Matchups {
+		if m.Draws != 3 || m.Wins1 != 0 || m.Wins2 != 0 {
+			t.Errorf("%s vs %s: %d+%d wins and %d draws, want only draws after 2 turns",
+				m.Entrant1, m.Entrant2, m.Wins1, m.Wins2, m.Draws)
+		}
+	}
+}
+
+func TestSimulate_Reproducible(t *testing.T) {
+	cfg := config{battles: 4, maxTurns: 100, seed: 7, searchSize: 100, workers: 3, rules: game.ClassicRules}
+	entrants := testEntrants()
+	for i := range entrants {
+		entrants[i].Strategy = "random"
+	}
+
+	var first, second bytes.Buffer
+	if err := simulate(entrants, cfg).writeJSON(&first); err != nil {
+		t.Fatal(err)
+	}
+	if err := simulate(entrants, cfg).writeJSON(&second); err != nil {
+		t.Fatal(err)
+	}
+	if first.String() != second.String() {
+		t.Error("Expected the same seed to give the same report")
+	}
+}
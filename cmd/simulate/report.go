@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+)
+
+// report aggregates the outcomes of a simulation run
+type report struct {
+	Battles       int             `json:"Battles"`
+	AverageRounds float64         `json:"AverageRounds"`
+	AverageTurns  float64         `json:"AverageTurns"`
+	Entrants      []string        `json:"Entrants"`
+	Matchups      []*matchupStats `json:"Matchups"`
+	Abilities     []*abilityStats `json:"Abilities"`
+
+	rounds    int
+	turns     int
+	abilities map[[2]string]*abilityStats
+}
+
+// matchupStats are the results of one entrant against another. Entrant1
+// always plays the first character.
+type matchupStats struct {
+	Entrant1      string  `json:"Entrant1"`
+	Entrant2      string  `json:"Entrant2"`
+	Battles       int     `json:"Battles"`
+	Wins1         int     `json:"Wins1"`
+	Wins2         int     `json:"Wins2"`
+	Draws         int     `json:"Draws"`
+	WinRate1      float64 `json:"WinRate1"`
+	AverageRounds float64 `json:"AverageRounds"`
+
+	rounds int
+}
+
+// abilityStats count how often an ability was used and what it achieved
+type abilityStats struct {
+	Character     string  `json:"Character"`
+	Ability       string  `json:"Ability"`
+	Uses          int     `json:"Uses"`
+	Damage        int     `json:"Damage"`
+	AverageDamage float64 `json:"AverageDamage"`
+}
+
+func newReport(entrants []entrant, battles int) *report {
+	r := &report{abilities: make(map[[2]string]*abilityStats)}
+	for _, e := range entrants {
+		r.Entrants = append(r.Entrants, e.String())
+	}
+	for _, first := range entrants {
+		for _, second := range entrants {
+			r.Matchups = append(r.Matchups, &matchupStats{
+				Entrant1: first.String(),
+				Entrant2: second.String(),
+			})
+		}
+	}
+	return r
+}
+
+func (r *report) add(o outcome) {
+	r.Battles++
+	r.rounds += o.rounds
+	r.turns += o.turns
+	r.AverageRounds = float64(r.rounds) / float64(r.Battles)
+	r.AverageTurns = float64(r.turns) / float64(r.Battles)
+
+	m := r.Matchups[o.matchup]
+	m.Battles++
+	m.rounds += o.rounds
+	switch o.winner {
+	case 1:
+		m.Wins1++
+	case 2:
+		m.Wins2++
+	default:
+		m.Draws++
+	}
+	m.WinRate1 = float64(m.Wins1) / float64(m.Battles)
+	m.AverageRounds = float64(m.rounds) / float64(m.Battles)
+
+	for _, use := range o.uses {
+		key := [2]string{use.character, use.ability}
+		stats, ok := r.abilities[key]
+		if !ok {
+			stats = &abilityStats{Character: use.character, Ability: use.ability}
+			r.abilities[key] = stats
+			r.Abilities = append(r.Abilities, stats)
+			sort.Slice(r.Abilities, func(i, j int) bool {
+				if r.Abilities[i].Character != r.Abilities[j].Character {
+					return r.Abilities[i].Character < r.Abilities[j].Character
+				}
+				return r.Abilities[i].Ability < r.Abilities[j].Ability
+			})
+		}
+		stats.Uses++
+		stats.Damage += use.damage
+		stats.AverageDamage = float64(stats.Damage) / float64(stats.Uses)
+	}
+}
+
+// writeText prints the win rate of each row entrant against each column
+// entrant, followed by battle length and ability statistics
+func (r *report) writeText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+
+	fmt.Fprintln(w, "Win rate of row (first character) against column:")
+	fmt.Fprint(tw, "\t")
+	for _, name := range r.Entrants {
+		fmt.Fprintf(tw, "%s\t", name)
+	}
+	fmt.Fprintln(tw)
+	for i, name := range r.Entrants {
+		fmt.Fprintf(tw, "%s\t", name)
+		for j := range r.Entrants {
+			fmt.Fprintf(tw, "%.1f%%\t", 100*r.Matchups[i*len(r.Entrants)+j].WinRate1)
+		}
+		fmt.Fprintln(tw)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\nBattles: %d\nAverage rounds: %.2f\nAverage turns: %.2f\n\n", r.Battles, r.AverageRounds, r.AverageTurns)
+
+	fmt.Fprintln(tw, "Character\tAbility\tUses\tDamage\tAvg damage\t")
+	for _, a := range r.Abilities {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%.2f\t\n", a.Character, a.Ability, a.Uses, a.Damage, a.AverageDamage)
+	}
+	return tw.Flush()
+}
+
+// writeCSV prints the matchup table and the ability table, separated by a
+// blank line, each with its own header row
+func (r *report) writeCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"entrant1", "entrant2", "battles", "wins1", "wins2", "draws", "win_rate1", "average_rounds"})
+	for _, m := range r.Matchups {
+		cw.Write([]string{
+			m.Entrant1, m.Entrant2,
+			strconv.Itoa(m.Battles), strconv.Itoa(m.Wins1), strconv.Itoa(m.Wins2), strconv.Itoa(m.Draws),
+			strconv.FormatFloat(m.WinRate1, 'f', 4, 64),
+			strconv.FormatFloat(m.AverageRounds, 'f', 2, 64),
+		})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	cw.Write([]string{"character", "ability", "uses", "damage", "average_damage"})
+	for _, a := range r.Abilities {
+		cw.Write([]string{
+			a.Character, a.Ability,
+			strconv.Itoa(a.Uses), strconv.Itoa(a.Damage),
+			strconv.FormatFloat(a.AverageDamage, 'f', 2, 64),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (r *report) writeJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
@@ -63,15 +63,33 @@ function App() {
   const [char1, setChar1] = useState<Character>(defaultCharacter1);
   const [char2, setChar2] = useState<Character>(defaultCharacter2);
   const [battle, setBattle] = useState<Battle | null>(null);
+  // Tokens of the players of sides 1 and 2, handed out when the battle is created
+  const [playerTokens, setPlayerTokens] = useState<string[]>([]);
   const [error, setError] = useState<string | null>(null);
   const [isLoading, setIsLoading] = useState(false);
 
   const resetBattle = useCallback(() => {
     setBattle(null);
+    setPlayerTokens([]);
     setError(null);
     setIsLoading(false);
   }, []);
 
+  // Both players share this screen, so act with the token of whichever side
+  // the character fights on
+  const headersFor = (token?: string): HeadersInit => ({
+    'Content-Type': 'application/json',
+    ...(token ? { Authorization: `Bearer ${token}` } : {}),
+  });
+
+  const tokenFor = (characterID: string) => {
+    if (!battle) {
+      return undefined;
+    }
+    const side1 = [battle.Character1, ...(battle.Reserves1 ?? [])].some((c) => c.ID === characterID);
+    return playerTokens[side1 ? 0 : 1];
+  };
+
   const createBattle = async () => {
     setIsLoading(true);
     setError(null);
@@ -95,13 +113,13 @@ function App() {
 
       const newBattle = await response.json();
       console.log('Battle created:', newBattle);
+      const tokens: string[] = newBattle.PlayerTokens ?? [];
+      setPlayerTokens(tokens);
 
       // Start the battle
       const startResponse = await fetch(`${API_BASE_URL}/battles/${newBattle.ID}/start`, {
         method: 'POST',
-        headers: {
-          'Content-Type': 'application/json',
-        },
+        headers: headersFor(tokens[0]),
       });
 
       if (!startResponse.ok) {
@@ -136,12 +154,11 @@ function App() {
     });
 
     setIsLoading(true);
+    const token = tokenFor(action.CharacterID);
     try {
       const response = await fetch(`${API_BASE_URL}/battles/${battle.ID}/action`, {
         method: 'POST',
-        headers: {
-          'Content-Type': 'application/json',
-        },
+        headers: headersFor(token),
         body: JSON.stringify(action),
       });
 
@@ -171,9 +188,7 @@ function App() {
         console.log('Fetching fresh battle state');
         const battleResponse = await fetch(`${API_BASE_URL}/battles/${battle.ID}`, {
           method: 'GET',
-          headers: {
-            'Content-Type': 'application/json',
-          },
+          headers: headersFor(token),
         });
 
         if (!battleResponse.ok) {
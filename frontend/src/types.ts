@@ -1,18 +1,31 @@
 export type Character = {
     ID: string;
     Name: string;
+    Class?: string;
     Health: number;
+    MaxHealth?: number;
     Attack: number;
     Defense: number;
+    DefenseBonus?: number;
     Speed: number;
     StatusEffects: StatusEffect[];
     Abilities: Ability[];
+    Resistances?: Record<string, number>;
+    Immunities?: string[];
+    TemporaryImmunities?: Record<string, number>;
+    Inventory?: Consumable[];
+    Movement?: number;
+    // Set on characters seen from the other side when the rules hide them
+    HealthPercent?: number;
+    HiddenAbilities?: number;
+    Hidden?: ("Cooldowns" | "Abilities" | "Health")[];
 };
 
 export type StatusEffect = {
     Type: string;
     Duration: number;
     Potency: number;
+    Formula?: string;
 };
 
 export type Ability = {
@@ -20,19 +33,139 @@ export type Ability = {
     Damage: number;
     CooldownMax: number;
     StatusEffect?: StatusEffect;
+    Formula?: string;
+    SetsWeather?: Weather;
+    WeatherDuration?: number;
+    ClearsWeather?: boolean;
+    Combo?: Combo;
+    ChargeRounds?: number;
+    ChannelRounds?: number;
+    DelayRounds?: number;
+    InterruptDamage?: number;
+    Priority?: number;
+    Summon?: Summon;
+    Range?: number;
+    Area?: Area;
+};
+
+export type Area = {
+    Shape: "LINE" | "CONE" | "RADIUS";
+    Size: number;
+};
+
+export type Summon = {
+    Minion: Character;
+    Strategy?: string;
+    Rounds?: number;
+};
+
+export type Combo = {
+    TargetHas?: string;
+    After?: string[];
+    AllyBefore?: boolean;
+    Bonus: number;
+    Finisher?: boolean;
+};
+
+export type Weather = "HEATWAVE" | "FOG" | "SANCTUARY";
+
+export type Environment = {
+    Weather: Weather;
+    Duration: number;
 };
 
 export type Battle = {
     ID: string;
     Character1: Character;
     Character2: Character;
-    State: "PENDING" | "ACTIVE" | "COMPLETE";
+    State: "PENDING" | "ACTIVE" | "PAUSED" | "COMPLETE";
+    PauseReason?: string;
+    TurnTimeRemainingMs?: number;
+    TurnMode?: "FREE" | "SEQUENTIAL" | "SIMULTANEOUS" | "ATB";
+    Sealed?: string[];
+    Gauges?: Record<string, number>;
+    GaugeFull?: number;
+    Rules?: RuleSet;
+    CurrentTurn?: string;
+    AIControlled?: string[];
     Winner?: Character;
     Round: number;
+    Reserves1?: Character[];
+    Reserves2?: Character[];
+    Fled?: Character;
+    Environment?: Environment;
+    Pending?: PendingAbility[];
+    Minions?: Minion[];
+    Grid?: Grid;
+    CreatedAt?: string;
+    PlayerTokens?: string[];
+};
+
+export type BattleList = {
+    Battles: Battle[];
+    NextCursor?: string;
+};
+
+export type Position = {
+    X: number;
+    Y: number;
+};
+
+export type Grid = {
+    // One character per cell: "." open, "#" wall, "~" water
+    Rows: string[];
+    Positions: Record<string, Position>;
+};
+
+export type Minion = {
+    Character: Character;
+    SummonerID: string;
+    Side: 1 | 2;
+    Remaining?: number;
+};
+
+export type PendingAbility = {
+    Kind: "CHARGING" | "CHANNELLING" | "DELAYED";
+    CasterID: string;
+    AbilityIndex: number;
+    Ability: string;
+    TargetSide: 1 | 2;
+    Remaining: number;
+};
+
+export type RuleSet = {
+    Name?: string;
+    TurnMode: "FREE" | "SEQUENTIAL" | "SIMULTANEOUS" | "ATB";
+    MaxRounds: number;
+    DamageModel: "SUBTRACT" | "PERCENT";
+    EffectTick: "ACTION" | "ROUND";
+    AllowSelfTarget: boolean;
+    StartingCooldowns: boolean;
+    ActionBuffer: number;
+    Visibility: Visibility;
+};
+
+export type Visibility = {
+    HideCooldowns?: boolean;
+    HideUnrevealedAbilities?: boolean;
+    HealthBuckets?: number;
+};
+
+export type Consumable = {
+    Name: string;
+    Quantity: number;
+    Target: "SELF" | "ENEMY" | "ANY";
+    Heal: number;
+    Damage: number;
+    Cures?: string[];
+    StatusEffect: StatusEffect;
 };
 
 export type BattleAction = {
+    Kind?: "ABILITY" | "ITEM" | "DEFEND" | "WAIT" | "SWAP" | "FLEE" | "MOVE";
     CharacterID: string;
     AbilityIndex: number;
+    ItemIndex?: number;
     TargetID: string;
+    To?: Position;
 };
@@ -0,0 +1,715 @@
+// Package content loads character, ability and status effect definitions
+// from a directory of JSON files, so game numbers can change without
+// recompiling.
+//
+// A content directory holds:
+//
+//	effects.json     status effects, referenced by abilities (optional)
+//	abilities.json   abilities, referenced by characters
+//	characters.json  character templates
+//	defaults.json    abilities given to characters created without any (optional)
+//	growth.json      growth curves of persistent characters by class (optional)
+//	items.json       equipment (optional)
+//	consumables.json potions, bombs and other single-use battle items (optional)
+//	classes.json     character classes (optional)
+package content
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/MaterDev/golang_turnbased_game_spike/internal/equipment"
+	"github.com/MaterDev/golang_turnbased_game_spike/internal/formula"
+	"github.com/MaterDev/golang_turnbased_game_spike/internal/game"
+	"github.com/MaterDev/golang_turnbased_game_spike/internal/progression"
+	"github.com/MaterDev/golang_turnbased_game_spike/internal/validation"
+)
+
+// DefaultGrowth is the key in growth.json of the curve used by classes
+// that have none of their own
+const DefaultGrowth = "default"
+
+// EffectDef is a status effect as written in effects.json
+type EffectDef struct {
+	ID       string            `json:"ID"`
+	Type     game.StatusEffect `json:"Type"`
+	Duration int               `json:"Duration"`
+	Potency  int               `json:"Potency"`
+	Formula  string            `json:"Formula,omitempty"`
+}
+
+// AbilityDef is an ability as written in abilities.json. Effect is the ID of
+// the status effect it applies, if any.
+type AbilityDef struct {
+	ID              string       `json:"ID"`
+	Name            string       `json:"Name"`
+	Damage          int          `json:"Damage"`
+	CooldownMax     int          `json:"CooldownMax"`
+	Effect          string       `json:"Effect,omitempty"`
+	Formula         string       `json:"Formula,omitempty"`
+	SetsWeather     game.Weather `json:"SetsWeather,omitempty"`
+	WeatherDuration int          `json:"WeatherDuration,omitempty"`
+	ClearsWeather   bool         `json:"ClearsWeather,omitempty"`
+	Combo           *game.Combo  `json:"Combo,omitempty"`
+	ChargeRounds    int          `json:"ChargeRounds,omitempty"`
+	ChannelRounds   int          `json:"ChannelRounds,omitempty"`
+	DelayRounds     int          `json:"DelayRounds,omitempty"`
+	InterruptDamage int          `json:"InterruptDamage,omitempty"`
+	Priority        int          `json:"Priority,omitempty"`
+	Summon          *SummonDef   `json:"Summon,omitempty"`
+	Range           int          `json:"Range,omitempty"`
+	Area            *game.Area   `json:"Area,omitempty"`
+}
+
+// SummonDef is the minion a summoning ability brings, as written in
+// abilities.json. Its abilities are referenced by ID and must come earlier
+// in the file.
+type SummonDef struct {
+	Name      string   `json:"Name"`
+	Health    int      `json:"Health"`
+	Attack    int      `json:"Attack"`
+	Defense   int      `json:"Defense"`
+	Speed     int      `json:"Speed"`
+	Abilities []string `json:"Abilities"`
+	Strategy  string   `json:"Strategy,omitempty"`
+	Rounds    int      `json:"Rounds,omitempty"`
+}
+
+// CharacterDef is a character template as written in characters.json.
+// Abilities are referenced by ID.
+type CharacterDef struct {
+	ID        string   `json:"ID"`
+	Name      string   `json:"Name"`
+	Health    int      `json:"Health"`
+	Attack    int      `json:"Attack"`
+	Defense   int      `json:"Defense"`
+	Speed     int      `json:"Speed"`
+	Abilities []string `json:"Abilities"`
+}
+
+// ConsumableDef is a consumable as written in consumables.json. Effect is
+// the ID of the status effect it applies, if any.
+type ConsumableDef struct {
+	ID     string              `json:"ID"`
+	Name   string              `json:"Name"`
+	Target game.ItemTarget     `json:"Target"`
+	Heal   int                 `json:"Heal"`
+	Damage int                 `json:"Damage"`
+	Cures  []game.StatusEffect `json:"Cures,omitempty"`
+	Effect string              `json:"Effect,omitempty"`
+}
+
+// ClassDef is a character class as written in classes.json. Abilities lists
+// every ability the class may use and StartingAbilities those it begins
+// with; Passives are effects, by ID, its characters start each battle with.
+type ClassDef struct {
+	ID                string                    `json:"ID"`
+	Name              string                    `json:"Name"`
+	Health            int                       `json:"Health"`
+	Attack            int                       `json:"Attack"`
+	Defense           int                       `json:"Defense"`
+	Speed             int                       `json:"Speed"`
+	Abilities         []string                  `json:"Abilities"`
+	StartingAbilities []string                  `json:"StartingAbilities"`
+	Resistances       map[game.StatusEffect]int `json:"Resistances,omitempty"`
+	Immunities        []game.StatusEffect       `json:"Immunities,omitempty"`
+	Passives          []string                  `json:"Passives,omitempty"`
+}
+
+// Class is a character class with its passives resolved
+type Class struct {
+	ID   string `json:"ID"`
+	Name string `json:"Name"`
+	// Base holds the stats of a level 1 character of the class
+	Base              progression.Stats         `json:"Base"`
+	Abilities         []string                  `json:"Abilities"`
+	StartingAbilities []string                  `json:"StartingAbilities"`
+	Resistances       map[game.StatusEffect]int `json:"Resistances,omitempty"`
+	Immunities        []game.StatusEffect       `json:"Immunities,omitempty"`
+	Passives          []game.StatusEffectData   `json:"Passives,omitempty"`
+}
+
+// Allows reports whether characters of the class may use the ability
+func (c Class) Allows(abilityID string) bool {
+	for _, id := range c.Abilities {
+		if id == abilityID {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyPassives returns a copy of ch with the class's resistances,
+// immunities and passive effects
+func (c Class) ApplyPassives(ch game.Character) game.Character {
+	ch = ch.Clone()
+	ch.Class = c.ID
+	for effect, percent := range c.Resistances {
+		if ch.Resistances == nil {
+			ch.Resistances = make(map[game.StatusEffect]int)
+		}
+		ch.Resistances[effect] = min(ch.Resistances[effect]+percent, 100)
+	}
+	for _, effect := range c.Immunities {
+		if !slices.Contains(ch.Immunities, effect) {
+			ch.Immunities = append(ch.Immunities, effect)
+		}
+	}
+	ch.StatusEffects = append(ch.StatusEffects, c.Passives...)
+	return ch
+}
+
+// Defaults lists the abilities, by ID, given to each side of a battle when
+// its character arrives without any
+type Defaults struct {
+	Character1Abilities []string `json:"Character1Abilities"`
+	Character2Abilities []string `json:"Character2Abilities"`
+}
+
+// Library holds loaded content with every reference resolved. It is safe for
+// concurrent use, and everything it returns is a copy.
+type Library struct {
+	effects    map[string]game.StatusEffectData
+	abilities  map[string]game.Ability
+	characters map[string]game.Character
+
+	// characterIDs keeps characters in file order
+	characterIDs []string
+
+	defaults [2][]game.Ability
+
+	growth map[string]progression.GrowthCurve
+
+	items   map[string]equipment.Item
+	itemIDs []string
+
+	consumables map[string]game.Consumable
+
+	classes  map[string]Class
+	classIDs []string
+}
+
+// definitions is everything read from a content directory
+type definitions struct {
+	effects     []EffectDef
+	abilities   []AbilityDef
+	characters  []CharacterDef
+	defaults    Defaults
+	growth      map[string]progression.GrowthCurve
+	items       []equipment.Item
+	consumables []ConsumableDef
+	classes     []ClassDef
+}
+
+// Load reads the content directory at dir. Definitions are checked against
+// validation.DefaultLimits; if any fail, the error is a validation.Errors
+// listing every problem.
+func Load(dir string) (*Library, error) {
+	var defs definitions
+	if err := readOptional(filepath.Join(dir, "effects.json"), &defs.effects); err != nil {
+		return nil, err
+	}
+	if err := readJSON(filepath.Join(dir, "abilities.json"), &defs.abilities); err != nil {
+		return nil, err
+	}
+	if err := readJSON(filepath.Join(dir, "characters.json"), &defs.characters); err != nil {
+		return nil, err
+	}
+	if err := readOptional(filepath.Join(dir, "defaults.json"), &defs.defaults); err != nil {
+		return nil, err
+	}
+	if err := readOptional(filepath.Join(dir, "growth.json"), &defs.growth); err != nil {
+		return nil, err
+	}
+	if err := readOptional(filepath.Join(dir, "items.json"), &defs.items); err != nil {
+		return nil, err
+	}
+	if err := readOptional(filepath.Join(dir, "consumables.json"), &defs.consumables); err != nil {
+		return nil, err
+	}
+	if err := readOptional(filepath.Join(dir, "classes.json"), &defs.classes); err != nil {
+		return nil, err
+	}
+	return newLibrary(defs)
+}
+
+func newLibrary(defs definitions) (*Library, error) {
+	l := &Library{
+		effects:    make(map[string]game.StatusEffectData, len(defs.effects)),
+		abilities:  make(map[string]game.Ability, len(defs.abilities)),
+		characters: make(map[string]game.Character, len(defs.characters)),
+		growth:     defs.growth,
+		items:      make(map[string]equipment.Item, len(defs.items)),
+
+		consumables: make(map[string]game.Consumable, len(defs.consumables)),
+		classes:     make(map[string]Class, len(defs.classes)),
+	}
+	v := validation.New(validation.DefaultLimits)
+
+	for i, def := range defs.effects {
+		path, ok := definitionPath(v, "effects", i, def.ID, l.effects)
+		if !ok {
+			continue
+		}
+		effect := game.StatusEffectData{
+			Type:     def.Type,
+			Duration: def.Duration,
+			Potency:  def.Potency,
+			Formula:  parseFormula(v, path+".Formula", def.Formula),
+		}
+		if def.Type == "" {
+			v.Add(path+".Type", "is required")
+		} else {
+			v.StatusEffect(path, effect)
+		}
+		l.effects[def.ID] = effect
+	}
+
+	for i, def := range defs.abilities {
+		path, ok := definitionPath(v, "abilities", i, def.ID, l.abilities)
+		if !ok {
+			continue
+		}
+		ability := game.Ability{
+			Name:        def.Name,
+			Damage:      def.Damage,
+			CooldownMax: def.CooldownMax,
+			Formula:     parseFormula(v, path+".Formula", def.Formula),
+
+			SetsWeather:     def.SetsWeather,
+			WeatherDuration: def.WeatherDuration,
+			ClearsWeather:   def.ClearsWeather,
+			Combo:           def.Combo,
+			ChargeRounds:    def.ChargeRounds,
+			ChannelRounds:   def.ChannelRounds,
+			DelayRounds:     def.DelayRounds,
+			InterruptDamage: def.InterruptDamage,
+			Priority:        def.Priority,
+			Range:           def.Range,
+			Area:            def.Area,
+		}
+		if def.Effect != "" {
+			effect, ok := l.effects[def.Effect]
+			if !ok {
+				v.Add(path+".Effect", "unknown effect %q", def.Effect)
+			}
+			ability.StatusEffect = effect
+		}
+		if s := def.Summon; s != nil {
+			ability.Summon = &game.Summon{
+				Minion: game.Character{
+					Name:      s.Name,
+					Health:    s.Health,
+					Attack:    s.Attack,
+					Defense:   s.Defense,
+					Speed:     s.Speed,
+					Abilities: l.resolveAbilities(v, path+".Summon.Abilities", s.Abilities),
+				},
+				Strategy: s.Strategy,
+				Rounds:   s.Rounds,
+			}
+		}
+		v.Ability(path, ability)
+		l.abilities[def.ID] = ability
+	}
+
+	for i, def := range defs.characters {
+		path, ok := definitionPath(v, "characters", i, def.ID, l.characters)
+		if !ok {
+			continue
+		}
+		c := game.Character{
+			ID:        def.ID,
+			Name:      def.Name,
+			Health:    def.Health,
+			Attack:    def.Attack,
+			Defense:   def.Defense,
+			Speed:     def.Speed,
+			Abilities: l.resolveAbilities(v, path+".Abilities", def.Abilities),
+		}
+		v.Character(path, c)
+		l.characters[def.ID] = c
+		l.characterIDs = append(l.characterIDs, def.ID)
+	}
+
+	l.defaults[0] = l.resolveAbilities(v, "defaults.Character1Abilities", defs.defaults.Character1Abilities)
+	l.defaults[1] = l.resolveAbilities(v, "defaults.Character2Abilities", defs.defaults.Character2Abilities)
+
+	for i, def := range defs.classes {
+		path, ok := definitionPath(v, "classes", i, def.ID, l.classes)
+		if !ok {
+			continue
+		}
+		class := Class{
+			ID:   def.ID,
+			Name: def.Name,
+			Base: progression.Stats{
+				Health:  def.Health,
+				Attack:  def.Attack,
+				Defense: def.Defense,
+				Speed:   def.Speed,
+			},
+			Abilities:         def.Abilities,
+			StartingAbilities: def.StartingAbilities,
+			Resistances:       def.Resistances,
+			Immunities:        def.Immunities,
+		}
+		for j, id := range def.Passives {
+			effect, ok := l.effects[id]
+			if !ok {
+				v.Add(fmt.Sprintf("%s.Passives[%d]", path, j), "unknown effect %q", id)
+				continue
+			}
+			class.Passives = append(class.Passives, effect)
+		}
+		l.resolveAbilities(v, path+".Abilities", def.Abilities)
+		for j, id := range def.StartingAbilities {
+			if !class.Allows(id) {
+				v.Add(fmt.Sprintf("%s.StartingAbilities[%d]", path, j), "ability %q is not allowed for the class", id)
+			}
+		}
+
+		// Check the class as the character it builds
+		c := game.Character{
+			Name:        def.Name,
+			Health:      def.Health,
+			Attack:      def.Attack,
+			Defense:     def.Defense,
+			Speed:       def.Speed,
+			Abilities:   l.resolveAbilities(validation.New(v.Limits), "", def.StartingAbilities),
+			Resistances: def.Resistances,
+			Immunities:  def.Immunities,
+		}
+		v.Character(path, c)
+		l.classes[def.ID] = class
+		l.classIDs = append(l.classIDs, def.ID)
+	}
+
+	classes := make([]string, 0, len(defs.growth))
+	for class := range defs.growth {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	for _, class := range classes {
+		curve := defs.growth[class]
+		path := fmt.Sprintf("growth[%s]", class)
+		gains := []struct {
+			field string
+			gain  int
+		}{{"Health", curve.Health}, {"Attack", curve.Attack}, {"Defense", curve.Defense}, {"Speed", curve.Speed}}
+		for _, g := range gains {
+			if g.gain < 0 {
+				v.Add(path+"."+g.field, "must not be negative, got %d", g.gain)
+			}
+		}
+		for i, unlock := range curve.Unlocks {
+			unlockPath := fmt.Sprintf("%s.Unlocks[%d]", path, i)
+			if unlock.Level < 2 || unlock.Level > progression.MaxLevel {
+				v.Add(unlockPath+".Level", "must be between 2 and %d, got %d", progression.MaxLevel, unlock.Level)
+			}
+			if _, ok := l.abilities[unlock.Ability]; !ok {
+				v.Add(unlockPath+".Ability", "unknown ability %q", unlock.Ability)
+			} else if c, ok := l.classes[class]; ok && !c.Allows(unlock.Ability) {
+				v.Add(unlockPath+".Ability", "ability %q is not allowed for the class", unlock.Ability)
+			}
+		}
+	}
+
+	for i, item := range defs.items {
+		path, ok := definitionPath(v, "items", i, item.ID, l.items)
+		if !ok {
+			continue
+		}
+		l.validateItem(v, path, item)
+		l.items[item.ID] = item
+		l.itemIDs = append(l.itemIDs, item.ID)
+	}
+
+	for i, def := range defs.consumables {
+		path, ok := definitionPath(v, "consumables", i, def.ID, l.consumables)
+		if !ok {
+			continue
+		}
+		consumable := game.Consumable{
+			Name:   def.Name,
+			Target: def.Target,
+			Heal:   def.Heal,
+			Damage: def.Damage,
+			Cures:  def.Cures,
+		}
+		if def.Effect != "" {
+			effect, ok := l.effects[def.Effect]
+			if !ok {
+				v.Add(path+".Effect", "unknown effect %q", def.Effect)
+			}
+			consumable.StatusEffect = effect
+		}
+		v.Consumable(path, consumable)
+		l.consumables[def.ID] = consumable
+	}
+
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// validateItem checks an item's bonuses and that its ability mods name
+// abilities that exist. Bonuses may be negative, for items with a drawback.
+func (l *Library) validateItem(v *validation.Validator, path string, item equipment.Item) {
+	limits := v.Limits
+	if item.Name == "" {
+		v.Add(path+".Name", "is required")
+	}
+	if !item.Slot.IsValid() {
+		v.Add(path+".Slot", "unknown slot %q", item.Slot)
+	}
+	bonuses := []struct {
+		field string
+		bonus int
+		limit int
+	}{
+		{"Health", item.Health, limits.MaxHealth},
+		{"Attack", item.Attack, limits.MaxAttack},
+		{"Defense", item.Defense, limits.MaxDefense},
+		{"Speed", item.Speed, limits.MaxSpeed},
+	}
+	for _, b := range bonuses {
+		if b.bonus < -b.limit || b.bonus > b.limit {
+			v.Add(path+"."+b.field, "must be between %d and %d, got %d", -b.limit, b.limit, b.bonus)
+		}
+	}
+	v.Resistances(path+".Resistances", item.Resistances)
+	for i, effect := range item.Passives {
+		v.ActiveEffect(fmt.Sprintf("%s.Passives[%d]", path, i), effect)
+	}
+	for i, mod := range item.AbilityMods {
+		modPath := fmt.Sprintf("%s.AbilityMods[%d]", path, i)
+		if !l.hasAbilityNamed(mod.Ability) {
+			v.Add(modPath+".Ability", "no ability is named %q", mod.Ability)
+		}
+		if mod.Damage < -limits.MaxDamage || mod.Damage > limits.MaxDamage {
+			v.Add(modPath+".Damage", "must be between %d and %d, got %d", -limits.MaxDamage, limits.MaxDamage, mod.Damage)
+		}
+		if mod.Cooldown < -limits.MaxCooldown || mod.Cooldown > limits.MaxCooldown {
+			v.Add(modPath+".Cooldown", "must be between %d and %d, got %d", -limits.MaxCooldown, limits.MaxCooldown, mod.Cooldown)
+		}
+	}
+}
+
+func (l *Library) hasAbilityNamed(name string) bool {
+	for _, ability := range l.abilities {
+		if ability.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// definitionPath names the i-th definition in a file by its ID for error
+// paths, such as "abilities[fireball]". It reports false for definitions
+// with a missing or duplicate ID, which are skipped.
+func definitionPath[T any](v *validation.Validator, file string, i int, id string, seen map[string]T) (string, bool) {
+	if id == "" {
+		v.Add(fmt.Sprintf("%s[%d].ID", file, i), "is required")
+		return "", false
+	}
+	path := fmt.Sprintf("%s[%s]", file, id)
+	if _, ok := seen[id]; ok {
+		v.Add(path+".ID", "duplicate ID %q", id)
+		return "", false
+	}
+	return path, true
+}
+
+// parseFormula parses an optional formula, recording syntax errors against
+// path. Which variables it may read is checked with the rest of its
+// definition.
+func parseFormula(v *validation.Validator, path, src string) *formula.Expr {
+	if src == "" {
+		return nil
+	}
+	e, err := formula.Parse(src)
+	if err != nil {
+		v.Add(path, "%v", err)
+		return nil
+	}
+	return e
+}
+
+// resolveAbilities looks up ability IDs, recording unknown ones against path
+func (l *Library) resolveAbilities(v *validation.Validator, path string, ids []string) []game.Ability {
+	abilities := make([]game.Ability, 0, len(ids))
+	for i, id := range ids {
+		ability, ok := l.abilities[id]
+		if !ok {
+			v.Add(fmt.Sprintf("%s[%d]", path, i), "unknown ability %q", id)
+			continue
+		}
+		abilities = append(abilities, ability)
+	}
+	return abilities
+}
+
+// Effect returns the status effect with the given ID
+func (l *Library) Effect(id string) (game.StatusEffectData, bool) {
+	effect, ok := l.effects[id]
+	return effect, ok
+}
+
+// Ability returns the ability with the given ID
+func (l *Library) Ability(id string) (game.Ability, bool) {
+	ability, ok := l.abilities[id]
+	return ability, ok
+}
+
+// Abilities resolves a list of ability IDs, failing on the first unknown one
+func (l *Library) Abilities(ids []string) ([]game.Ability, error) {
+	abilities := make([]game.Ability, 0, len(ids))
+	for _, id := range ids {
+		ability, ok := l.abilities[id]
+		if !ok {
+			return nil, fmt.Errorf("unknown ability %q", id)
+		}
+		abilities = append(abilities, ability)
+	}
+	return abilities, nil
+}
+
+// Character returns the character template with the given ID
+func (l *Library) Character(id string) (game.Character, bool) {
+	c, ok := l.characters[id]
+	return c.Clone(), ok
+}
+
+// Characters returns every character template in file order
+func (l *Library) Characters() []game.Character {
+	characters := make([]game.Character, 0, len(l.characterIDs))
+	for _, id := range l.characterIDs {
+		characters = append(characters, l.characters[id].Clone())
+	}
+	return characters
+}
+
+// DefaultAbilities returns the abilities for the character on the given
+// side of a battle, 1 or 2, when it has none of its own
+func (l *Library) DefaultAbilities(side int) []game.Ability {
+	if side < 1 || side > len(l.defaults) {
+		return nil
+	}
+	return append([]game.Ability(nil), l.defaults[side-1]...)
+}
+
+// Growth returns the growth curve of a class, falling back to the default
+// curve, or to no growth at all if there is none
+func (l *Library) Growth(class string) progression.GrowthCurve {
+	if curve, ok := l.growth[class]; ok {
+		return curve
+	}
+	return l.growth[DefaultGrowth]
+}
+
+// Item returns the item with the given ID
+func (l *Library) Item(id string) (equipment.Item, bool) {
+	item, ok := l.items[id]
+	return item.Clone(), ok
+}
+
+// Items returns every item in file order
+func (l *Library) Items() []equipment.Item {
+	items := make([]equipment.Item, 0, len(l.itemIDs))
+	for _, id := range l.itemIDs {
+		items = append(items, l.items[id].Clone())
+	}
+	return items
+}
+
+// Consumable returns a single consumable with the given ID. Set Quantity to
+// carry more.
+func (l *Library) Consumable(id string) (game.Consumable, bool) {
+	consumable, ok := l.consumables[id]
+	if !ok {
+		return game.Consumable{}, false
+	}
+	consumable.Cures = append([]game.StatusEffect(nil), consumable.Cures...)
+	consumable.Quantity = 1
+	return consumable, true
+}
+
+// Class returns the class with the given ID
+func (l *Library) Class(id string) (Class, bool) {
+	class, ok := l.classes[id]
+	return class.clone(), ok
+}
+
+// Classes returns every class in file order
+func (l *Library) Classes() []Class {
+	classes := make([]Class, 0, len(l.classIDs))
+	for _, id := range l.classIDs {
+		classes = append(classes, l.classes[id].clone())
+	}
+	return classes
+}
+
+// NewCharacter builds a level 1 character of a class, with its starting
+// abilities and passives
+func (l *Library) NewCharacter(classID, name string) (game.Character, error) {
+	class, ok := l.classes[classID]
+	if !ok {
+		return game.Character{}, fmt.Errorf("unknown class %q", classID)
+	}
+	abilities, err := l.Abilities(class.StartingAbilities)
+	if err != nil {
+		return game.Character{}, err
+	}
+	c := game.Character{
+		Name:      name,
+		Health:    class.Base.Health,
+		Attack:    class.Base.Attack,
+		Defense:   class.Base.Defense,
+		Speed:     class.Base.Speed,
+		Abilities: abilities,
+	}
+	return class.ApplyPassives(c), nil
+}
+
+func (c Class) clone() Class {
+	c.Abilities = append([]string(nil), c.Abilities...)
+	c.StartingAbilities = append([]string(nil), c.StartingAbilities...)
+	c.Passives = append([]game.StatusEffectData(nil), c.Passives...)
+	c.Immunities = append([]game.StatusEffect(nil), c.Immunities...)
+	if c.Resistances != nil {
+		resistances := make(map[game.StatusEffect]int, len(c.Resistances))
+		for effect, percent := range c.Resistances {
+			resistances[effect] = percent
+		}
+		c.Resistances = resistances
+	}
+	return c
+}
+
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// readOptional is readJSON for files that may be left out
+func readOptional(path string, v any) error {
+	err := readJSON(path, v)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,353 @@
+package content
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MaterDev/golang_turnbased_game_spike/internal/game"
+	"github.com/MaterDev/golang_turnbased_game_spike/internal/progression"
+	"github.com/MaterDev/golang_turnbased_game_spike/internal/validation"
+)
+
+// writeContent creates a content directory from file name to JSON body
+func writeContent(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoad_ShippedContent(t *testing.T) {
+	library, err := Load(filepath.Join("..", "..", "content"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	// The defaults match what the server used to hard-code
+	want := [][]game.Ability{
+		{
+			{Name: "Basic Attack", Damage: 10},
+			{Name: "Power Strike", Damage: 20, CooldownMax: 2, StatusEffect: game.StatusEffectData{Type: game.StatusEnraged, Duration: 2, Potency: 20}},
+		},
+		{
+			{Name: "Basic Attack", Damage: 8},
+			{Name: "Fireball", Damage: 15, CooldownMax: 2, StatusEffect: game.StatusEffectData{Type: game.StatusBurning, Duration: 3, Potency: 5}},
+		},
+	}
+	for side, abilities := range want {
+		got := library.DefaultAbilities(side + 1)
+		if len(got) != len(abilities) {
+			t.Fatalf("DefaultAbilities(%d) = %+v, want %+v", side+1, got, abilities)
+		}
+		for i := range abilities {
+			if got[i] != abilities[i] {
+				t.Errorf("DefaultAbilities(%d)[%d] = %+v, want %+v", side+1, i, got[i], abilities[i])
+			}
+		}
+	}
+
+	if len(library.Characters()) == 0 {
+		t.Error("Expected shipped characters")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := writeContent(t, map[string]string{
+		"effects.json":    `[{"ID": "poison", "Type": "POISON", "Duration": 3, "Potency": 10}]`,
+		"abilities.json":  `[{"ID": "jab", "Name": "Jab", "Damage": 5}, {"ID": "sting", "Name": "Sting", "Damage": 3, "CooldownMax": 1, "Effect": "poison"}]`,
+		"characters.json": `[{"ID": "wasp", "Name": "Wasp", "Health": 30, "Attack": 4, "Defense": 1, "Speed": 20, "Abilities": ["jab", "sting"]}]`,
+	})
+
+	library, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	wasp, ok := library.Character("wasp")
+	if !ok {
+		t.Fatal("Expected character wasp")
+	}
+	if wasp.Name != "Wasp" || wasp.Health != 30 || len(wasp.Abilities) != 2 {
+		t.Errorf("Unexpected character %+v", wasp)
+	}
+	if effect := wasp.Abilities[1].StatusEffect; effect.Type != game.StatusPoisoned || effect.Potency != 10 {
+		t.Errorf("Expected sting to carry the poison effect, got %+v", effect)
+	}
+
+	// Changing a returned character leaves the library alone
+	wasp.Abilities[0].Damage = 100
+	if again, _ := library.Character("wasp"); again.Abilities[0].Damage != 5 {
+		t.Error("Expected Character to return a copy")
+	}
+
+	if abilities := library.DefaultAbilities(1); len(abilities) != 0 {
+		t.Errorf("Expected no defaults without defaults.json, got %+v", abilities)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name:    "missing abilities",
+			files:   map[string]string{"characters.json": `[]`},
+			wantErr: "abilities.json",
+		},
+		{
+			name: "malformed JSON",
+			files: map[string]string{
+				"abilities.json":  `[{"ID": }]`,
+				"characters.json": `[]`,
+			},
+			wantErr: "abilities.json",
+		},
+		{
+			name: "duplicate ability",
+			files: map[string]string{
+				"abilities.json":  `[{"ID": "jab"}, {"ID": "jab"}]`,
+				"characters.json": `[]`,
+			},
+			wantErr: `abilities[jab].ID: duplicate ID "jab"`,
+		},
+		{
+			name: "unknown effect",
+			files: map[string]string{
+				"abilities.json":  `[{"ID": "sting", "Effect": "poison"}]`,
+				"characters.json": `[]`,
+			},
+			wantErr: `abilities[sting].Effect: unknown effect "poison"`,
+		},
+		{
+			name: "unknown ability",
+			files: map[string]string{
+				"abilities.json":  `[]`,
+				"characters.json": `[{"ID": "wasp", "Abilities": ["sting"]}]`,
+			},
+			wantErr: `characters[wasp].Abilities[0]: unknown ability "sting"`,
+		},
+		{
+			name: "unknown minion ability",
+			files: map[string]string{
+				"abilities.json":  `[{"ID": "call_wolf", "Name": "Call Wolf", "Summon": {"Name": "Wolf", "Health": 30, "Speed": 12, "Abilities": ["bite"]}}]`,
+				"characters.json": `[]`,
+			},
+			wantErr: `abilities[call_wolf].Summon.Abilities[0]: unknown ability "bite"`,
+		},
+		{
+			name: "unknown default ability",
+			files: map[string]string{
+				"abilities.json":  `[]`,
+				"characters.json": `[]`,
+				"defaults.json":   `{"Character2Abilities": ["sting"]}`,
+			},
+			wantErr: "defaults.Character2Abilities[0]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeContent(t, tt.files))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_ValidatesDefinitions(t *testing.T) {
+	dir := writeContent(t, map[string]string{
+		"effects.json":    `[{"ID": "doom", "Type": "DOOM", "Duration": 0, "Potency": 10}]`,
+		"abilities.json":  `[{"ID": "jab", "Name": "Jab", "Damage": 5, "CooldownMax": -1}, {"ID": "hex", "Name": "Hex", "Formula": "caster.attack *"}, {"ID": "drain", "Name": "Drain", "Formula": "effect.potency"}]`,
+		"characters.json": `[{"ID": "wasp", "Name": "Wasp", "Health": 30, "Attack": 4, "Defense": 1, "Speed": 20, "Abilities": []}]`,
+	})
+
+	_, err := Load(dir)
+	errs, ok := err.(validation.Errors)
+	if !ok {
+		t.Fatalf("Load() error = %v, want validation.Errors", err)
+	}
+
+	fields := map[string]bool{}
+	for _, e := range errs {
+		fields[e.Field] = true
+	}
+	for _, want := range []string{
+		"effects[doom].Type",
+		"effects[doom].Duration",
+		"abilities[jab].CooldownMax",
+		"characters[wasp].Abilities",
+		"abilities[hex].Formula",
+		"abilities[drain].Formula",
+	} {
+		if !fields[want] {
+			t.Errorf("Expected an error for %s, got %v", want, errs)
+		}
+	}
+}
+
+func TestLoad_Items(t *testing.T) {
+	library, err := Load(filepath.Join("..", "..", "content"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(library.Items()) == 0 {
+		t.Fatal("Expected shipped items")
+	}
+	item, ok := library.Item("ember_staff")
+	if !ok || item.Slot != "WEAPON" || len(item.AbilityMods) != 1 {
+		t.Errorf("Item(ember_staff) = %+v, %v", item, ok)
+	}
+
+	dir := writeContent(t, map[string]string{
+		"abilities.json":  `[{"ID": "jab", "Name": "Jab", "Damage": 5}]`,
+		"characters.json": `[]`,
+		"items.json": `[{"ID": "hat", "Name": "Hat", "Slot": "HEAD", "Attack": 900,
+			"Resistances": {"FROZEN": 10},
+			"Passives": [{"Type": "BURNING", "Duration": 0, "Potency": 5}],
+			"AbilityMods": [{"Ability": "Kick", "Damage": 1}]}]`,
+	})
+	_, err = Load(dir)
+	errs, ok := err.(validation.Errors)
+	if !ok {
+		t.Fatalf("Load() error = %v, want validation.Errors", err)
+	}
+	fields := map[string]bool{}
+	for _, e := range errs {
+		fields[e.Field] = true
+	}
+	for _, want := range []string{
+		"items[hat].Slot",
+		"items[hat].Attack",
+		"items[hat].Resistances[FROZEN]",
+		"items[hat].Passives[0].Duration",
+		"items[hat].AbilityMods[0].Ability",
+	} {
+		if !fields[want] {
+			t.Errorf("Expected an error for %s, got %v", want, errs)
+		}
+	}
+}
+
+func TestLoad_Consumables(t *testing.T) {
+	library, err := Load(filepath.Join("..", "..", "content"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	antidote, ok := library.Consumable("antidote")
+	if !ok || antidote.Quantity != 1 || len(antidote.Cures) != 1 || antidote.Cures[0] != game.StatusPoisoned {
+		t.Errorf("Consumable(antidote) = %+v, %v", antidote, ok)
+	}
+	firebomb, _ := library.Consumable("firebomb")
+	if firebomb.StatusEffect.Type != game.StatusBurning {
+		t.Errorf("Expected firebomb to resolve its effect, got %+v", firebomb)
+	}
+
+	dir := writeContent(t, map[string]string{
+		"abilities.json":   `[{"ID": "jab", "Name": "Jab", "Damage": 5}]`,
+		"characters.json":  `[]`,
+		"consumables.json": `[{"ID": "elixir", "Name": "Elixir", "Target": "ALLY", "Effect": "haste"}]`,
+	})
+	_, err = Load(dir)
+	errs, ok := err.(validation.Errors)
+	if !ok {
+		t.Fatalf("Load() error = %v, want validation.Errors", err)
+	}
+	fields := map[string]bool{}
+	for _, e := range errs {
+		fields[e.Field] = true
+	}
+	for _, want := range []string{"consumables[elixir].Target", "consumables[elixir].Effect"} {
+		if !fields[want] {
+			t.Errorf("Expected an error for %s, got %v", want, errs)
+		}
+	}
+}
+
+func TestLoad_ClassesAtMaxLevel(t *testing.T) {
+	library, err := Load(filepath.Join("..", "..", "content"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	for _, class := range library.Classes() {
+		profile := progression.NewProfile(class.ID, class.Name, class.ID, class.Base, class.StartingAbilities)
+		profile.AddExperience(progression.ExperienceForLevel(progression.MaxLevel))
+		if profile.Level != progression.MaxLevel {
+			t.Fatalf("Expected level %d, got %d", progression.MaxLevel, profile.Level)
+		}
+		c, err := profile.CombatCharacter(library.Growth(class.ID), library)
+		if err != nil {
+			t.Fatalf("CombatCharacter(%s) error = %v", class.ID, err)
+		}
+		v := validation.New(validation.DefaultLimits)
+		v.Character(class.ID, c)
+		if err := v.Err(); err != nil {
+			t.Errorf("A %s at level %d is invalid: %v", class.ID, progression.MaxLevel, err)
+		}
+	}
+}
+
+func TestLoad_Classes(t *testing.T) {
+	library, err := Load(filepath.Join("..", "..", "content"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(library.Classes()) < 4 {
+		t.Errorf("Expected at least four shipped classes, got %d", len(library.Classes()))
+	}
+
+	healer, err := library.NewCharacter("healer", "Ada")
+	if err != nil {
+		t.Fatalf("NewCharacter() error = %v", err)
+	}
+	if healer.Name != "Ada" || healer.Class != "healer" || healer.Health != 90 || len(healer.Abilities) != 2 {
+		t.Errorf("NewCharacter(healer) = %+v", healer)
+	}
+	if len(healer.StatusEffects) != 1 || healer.StatusEffects[0].Type != game.StatusRegenerating {
+		t.Errorf("Expected the healer's passive, got %+v", healer.StatusEffects)
+	}
+	if len(healer.Immunities) != 1 || healer.Immunities[0] != game.StatusPoisoned {
+		t.Errorf("Expected the healer's immunity to poison, got %+v", healer.Immunities)
+	}
+	rogue, _ := library.NewCharacter("rogue", "Vex")
+	if rogue.Resistances[game.StatusPoisoned] != 50 {
+		t.Errorf("Expected the rogue's resistance, got %+v", rogue.Resistances)
+	}
+	if _, err := library.NewCharacter("bard", "Lute"); err == nil {
+		t.Error("Expected an error for an unknown class")
+	}
+
+	dir := writeContent(t, map[string]string{
+		"abilities.json":  `[{"ID": "jab", "Name": "Jab", "Damage": 5}, {"ID": "kick", "Name": "Kick", "Damage": 7}]`,
+		"characters.json": `[]`,
+		"classes.json": `[{"ID": "monk", "Name": "Monk", "Health": 90, "Attack": 10, "Defense": 5, "Speed": 12,
+			"Abilities": ["jab"], "StartingAbilities": ["jab", "kick"], "Passives": ["zen"]}]`,
+		"growth.json": `{"monk": {"Unlocks": [{"Level": 2, "Ability": "kick"}]}}`,
+	})
+	_, err = Load(dir)
+	errs, ok := err.(validation.Errors)
+	if !ok {
+		t.Fatalf("Load() error = %v, want validation.Errors", err)
+	}
+	fields := map[string]bool{}
+	for _, e := range errs {
+		fields[e.Field] = true
+	}
+	for _, want := range []string{
+		"classes[monk].StartingAbilities[1]",
+		"classes[monk].Passives[0]",
+		"growth[monk].Unlocks[0].Ability",
+	} {
+		if !fields[want] {
+			t.Errorf("Expected an error for %s, got %v", want, errs)
+		}
+	}
+}
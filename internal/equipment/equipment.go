@@ -0,0 +1,138 @@
+// Package equipment resolves the items a character wears into the combat
+// copy of that character. The battle engine only ever sees the result, so it
+// stays unaware of inventories.
+package equipment
+
+import (
+	"fmt"
+
+	"github.com/MaterDev/golang_turnbased_game_spike/internal/game"
+)
+
+// Slot is where an item is worn. A character wears at most one item per slot.
+type Slot string
+
+const (
+	SlotWeapon  Slot = "WEAPON"
+	SlotArmor   Slot = "ARMOR"
+	SlotTrinket Slot = "TRINKET"
+)
+
+// Slots lists every slot in the order items are applied
+var Slots = []Slot{SlotWeapon, SlotArmor, SlotTrinket}
+
+// IsValid reports whether the slot is one of Slots
+func (s Slot) IsValid() bool {
+	for _, slot := range Slots {
+		if s == slot {
+			return true
+		}
+	}
+	return false
+}
+
+// AbilityMod changes one of the wearer's abilities, matched by name
+type AbilityMod struct {
+	Ability  string `json:"Ability"`
+	Damage   int    `json:"Damage"`
+	Cooldown int    `json:"Cooldown"` // Added to CooldownMax; negative shortens it
+}
+
+// Item is a piece of equipment
+type Item struct {
+	ID      string `json:"ID"`
+	Name    string `json:"Name"`
+	Slot    Slot   `json:"Slot"`
+	Health  int    `json:"Health"`
+	Attack  int    `json:"Attack"`
+	Defense int    `json:"Defense"`
+	Speed   int    `json:"Speed"`
+	// Resistances are added to the wearer's, to at most 100
+	Resistances map[game.StatusEffect]int `json:"Resistances,omitempty"`
+	// Passives are status effects the wearer starts each battle with
+	Passives    []game.StatusEffectData `json:"Passives,omitempty"`
+	AbilityMods []AbilityMod            `json:"AbilityMods,omitempty"`
+}
+
+// Clone returns a deep copy of the item
+func (i Item) Clone() Item {
+	if i.Resistances != nil {
+		resistances := make(map[game.StatusEffect]int, len(i.Resistances))
+		for effect, percent := range i.Resistances {
+			resistances[effect] = percent
+		}
+		i.Resistances = resistances
+	}
+	i.Passives = append([]game.StatusEffectData(nil), i.Passives...)
+	i.AbilityMods = append([]AbilityMod(nil), i.AbilityMods...)
+	return i
+}
+
+// Loadout maps each slot to the ID of the item worn there
+type Loadout map[Slot]string
+
+// ItemSource looks items up by ID
+type ItemSource interface {
+	Item(id string) (Item, bool)
+}
+
+// Resolve looks up the items in the loadout, in slot order. It fails on an
+// unknown slot or item, or on an item worn in the wrong slot.
+func (l Loadout) Resolve(items ItemSource) ([]Item, error) {
+	for slot := range l {
+		if !slot.IsValid() {
+			return nil, fmt.Errorf("unknown slot %q", slot)
+		}
+	}
+
+	resolved := make([]Item, 0, len(l))
+	for _, slot := range Slots {
+		id, ok := l[slot]
+		if !ok || id == "" {
+			continue
+		}
+		item, ok := items.Item(id)
+		if !ok {
+			return nil, fmt.Errorf("unknown item %q", id)
+		}
+		if item.Slot != slot {
+			return nil, fmt.Errorf("item %q is worn in %s, not %s", id, item.Slot, slot)
+		}
+		resolved = append(resolved, item)
+	}
+	return resolved, nil
+}
+
+// Equip returns a copy of c wearing items. Stats never drop below the
+// engine's minimums, and cooldowns never below zero.
+func Equip(c game.Character, items ...Item) game.Character {
+	c = c.Clone()
+	for _, item := range items {
+		c.Health = max(c.Health+item.Health, 1)
+		c.Attack = max(c.Attack+item.Attack, 0)
+		c.Defense = max(c.Defense+item.Defense, 0)
+		c.Speed = max(c.Speed+item.Speed, 1)
+
+		for effect, percent := range item.Resistances {
+			if c.Resistances == nil {
+				c.Resistances = make(map[game.StatusEffect]int)
+			}
+			c.Resistances[effect] = min(c.Resistances[effect]+percent, 100)
+		}
+
+		c.StatusEffects = append(c.StatusEffects, item.Passives...)
+
+		for _, mod := range item.AbilityMods {
+			for i := range c.Abilities {
+				ability := &c.Abilities[i]
+				if ability.Name != mod.Ability {
+					continue
+				}
+				ability.Damage = max(ability.Damage+mod.Damage, 0)
+				ability.CooldownMax = max(ability.CooldownMax+mod.Cooldown, 0)
+				ability.Cooldown = min(ability.Cooldown, ability.CooldownMax)
+			}
+		}
+	}
+	return c
+}
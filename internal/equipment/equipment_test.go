@@ -0,0 +1,110 @@
+package equipment
+
+import (
+	"testing"
+
+	"github.com/MaterDev/golang_turnbased_game_spike/internal/game"
+)
+
+type itemMap map[string]Item
+
+func (m itemMap) Item(id string) (Item, bool) {
+	item, ok := m[id]
+	return item, ok
+}
+
+func testCharacter() game.Character {
+	return game.Character{
+		ID:      "1",
+		Name:    "Mage",
+		Health:  80,
+		Attack:  20,
+		Defense: 5,
+		Speed:   12,
+		Abilities: []game.Ability{
+			{Name: "Basic Attack", Damage: 8},
+			{Name: "Fireball", Damage: 15, CooldownMax: 2},
+		},
+	}
+}
+
+func TestEquip(t *testing.T) {
+	staff := Item{
+		ID:          "staff",
+		Slot:        SlotWeapon,
+		Attack:      2,
+		AbilityMods: []AbilityMod{{Ability: "Fireball", Damage: 5, Cooldown: -1}},
+	}
+	cloak := Item{
+		ID:          "cloak",
+		Slot:        SlotArmor,
+		Defense:     2,
+		Speed:       -20,
+		Resistances: map[game.StatusEffect]int{game.StatusBurning: 60},
+	}
+	charm := Item{
+		ID:          "charm",
+		Slot:        SlotTrinket,
+		Resistances: map[game.StatusEffect]int{game.StatusBurning: 60},
+		Passives:    []game.StatusEffectData{{Type: game.StatusRegenerating, Duration: 3, Potency: 5}},
+	}
+
+	base := testCharacter()
+	c := Equip(base, staff, cloak, charm)
+
+	if c.Attack != 22 || c.Defense != 7 || c.Health != 80 {
+		t.Errorf("Expected stat bonuses to apply, got %+v", c)
+	}
+	if c.Speed != 1 {
+		t.Errorf("Expected speed to stop at 1, got %d", c.Speed)
+	}
+	if got := c.Resistances[game.StatusBurning]; got != 100 {
+		t.Errorf("Expected resistances to stack up to 100, got %d", got)
+	}
+	if len(c.StatusEffects) != 1 || c.StatusEffects[0].Type != game.StatusRegenerating {
+		t.Errorf("Expected passive effect, got %+v", c.StatusEffects)
+	}
+	fireball := c.Abilities[1]
+	if fireball.Damage != 20 || fireball.CooldownMax != 1 {
+		t.Errorf("Expected Fireball to be modified, got %+v", fireball)
+	}
+	if c.Abilities[0].Damage != 8 {
+		t.Errorf("Expected other abilities untouched, got %+v", c.Abilities[0])
+	}
+
+	// The original is left alone
+	if base.Attack != 20 || base.Abilities[1].Damage != 15 || base.Resistances != nil {
+		t.Errorf("Expected Equip to leave the original untouched, got %+v", base)
+	}
+}
+
+func TestLoadout_Resolve(t *testing.T) {
+	items := itemMap{
+		"sword": {ID: "sword", Slot: SlotWeapon},
+		"mail":  {ID: "mail", Slot: SlotArmor},
+	}
+
+	resolved, err := Loadout{SlotArmor: "mail", SlotWeapon: "sword"}.Resolve(items)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if len(resolved) != 2 || resolved[0].ID != "sword" || resolved[1].ID != "mail" {
+		t.Errorf("Expected items in slot order, got %+v", resolved)
+	}
+
+	tests := []struct {
+		name    string
+		loadout Loadout
+	}{
+		{"unknown item", Loadout{SlotWeapon: "axe"}},
+		{"wrong slot", Loadout{SlotTrinket: "sword"}},
+		{"unknown slot", Loadout{"HAT": "sword"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.loadout.Resolve(items); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
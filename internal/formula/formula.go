@@ -0,0 +1,432 @@
+// Package formula is a small arithmetic expression language for content, such
+// as "caster.attack * 1.5 + target.maxHealth * 0.05 - target.defense".
+//
+// Expressions are parsed once, when content loads, and evaluated as often as
+// needed. They can only read the variables they are given and call a fixed
+// set of functions, so content cannot reach anything else. Evaluation never
+// fails: division by zero gives zero, as does any result that is not a
+// finite number.
+//
+// The grammar, loosest binding first:
+//
+//	expr    = term { ("+" | "-") term }
+//	term    = unary { ("*" | "/" | "%") unary }
+//	unary   = "-" unary | primary
+//	primary = number | variable | function "(" expr { "," expr } ")" | "(" expr ")"
+//
+// Variables are dotted names such as caster.attack. The functions are abs,
+// ceil, clamp(x, lo, hi), floor, max, min and round.
+package formula
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Limits that keep a single expression cheap to evaluate
+const (
+	MaxLength = 256
+	MaxDepth  = 32
+)
+
+// Variables lists every name an expression may read. Which of them are
+// available depends on where the expression is used.
+var Variables = []string{
+	"caster.health", "caster.maxHealth", "caster.attack", "caster.defense", "caster.speed",
+	"target.health", "target.maxHealth", "target.attack", "target.defense", "target.speed",
+	"ability.damage",
+	"effect.potency", "effect.duration",
+}
+
+// Env supplies the value of a variable during evaluation
+type Env func(name string) float64
+
+// Expr is a parsed expression. It is immutable and safe to share.
+type Expr struct {
+	src  string
+	root node
+	vars []string
+}
+
+// Parse parses src, reporting the first syntax error or unknown name
+func Parse(src string) (*Expr, error) {
+	if len(src) > MaxLength {
+		return nil, fmt.Errorf("formula is longer than %d characters", MaxLength)
+	}
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, vars: map[string]bool{}}
+	root, err := p.expr(0)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEnd {
+		return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos+1)
+	}
+
+	e := &Expr{src: src, root: root}
+	for name := range p.vars {
+		e.vars = append(e.vars, name)
+	}
+	sort.Strings(e.vars)
+	return e, nil
+}
+
+// String returns the source of the expression
+func (e *Expr) String() string {
+	if e == nil {
+		return ""
+	}
+	return e.src
+}
+
+// Vars returns the variables the expression reads, sorted
+func (e *Expr) Vars() []string {
+	if e == nil {
+		return nil
+	}
+	return append([]string(nil), e.vars...)
+}
+
+// Eval evaluates the expression. A nil expression evaluates to zero.
+func (e *Expr) Eval(env Env) float64 {
+	if e == nil || e.root == nil {
+		return 0
+	}
+	return finite(e.root.eval(env))
+}
+
+// MarshalJSON writes the expression as its source
+func (e *Expr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.String())
+}
+
+// UnmarshalJSON parses an expression from a JSON string
+func (e *Expr) UnmarshalJSON(data []byte) error {
+	var src string
+	if err := json.Unmarshal(data, &src); err != nil {
+		return err
+	}
+	parsed, err := Parse(src)
+	if err != nil {
+		return err
+	}
+	*e = *parsed
+	return nil
+}
+
+func finite(v float64) float64 {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0
+	}
+	return v
+}
+
+func isVariable(name string) bool {
+	for _, v := range Variables {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Syntax tree
+
+type node interface {
+	eval(env Env) float64
+}
+
+type number float64
+
+func (n number) eval(Env) float64 { return float64(n) }
+
+type variable string
+
+func (v variable) eval(env Env) float64 {
+	if env == nil {
+		return 0
+	}
+	return finite(env(string(v)))
+}
+
+type negate struct{ operand node }
+
+func (n negate) eval(env Env) float64 { return -n.operand.eval(env) }
+
+type binary struct {
+	op          byte
+	left, right node
+}
+
+func (b binary) eval(env Env) float64 {
+	l, r := b.left.eval(env), b.right.eval(env)
+	switch b.op {
+	case '+':
+		return l + r
+	case '-':
+		return l - r
+	case '*':
+		return l * r
+	case '/':
+		if r == 0 {
+			return 0
+		}
+		return l / r
+	case '%':
+		if r == 0 {
+			return 0
+		}
+		return math.Mod(l, r)
+	}
+	return 0
+}
+
+type call struct {
+	fn   function
+	args []node
+}
+
+func (c call) eval(env Env) float64 {
+	args := make([]float64, len(c.args))
+	for i, arg := range c.args {
+		args[i] = arg.eval(env)
+	}
+	return c.fn.apply(args)
+}
+
+type function struct {
+	minArgs, maxArgs int // maxArgs < 0 means any number
+	apply            func(args []float64) float64
+}
+
+var functions = map[string]function{
+	"abs":   {1, 1, func(a []float64) float64 { return math.Abs(a[0]) }},
+	"ceil":  {1, 1, func(a []float64) float64 { return math.Ceil(a[0]) }},
+	"floor": {1, 1, func(a []float64) float64 { return math.Floor(a[0]) }},
+	"round": {1, 1, func(a []float64) float64 { return math.Round(a[0]) }},
+	"clamp": {3, 3, func(a []float64) float64 { return math.Min(math.Max(a[0], a[1]), a[2]) }},
+	"min": {1, -1, func(a []float64) float64 {
+		m := a[0]
+		for _, v := range a[1:] {
+			m = math.Min(m, v)
+		}
+		return m
+	}},
+	"max": {1, -1, func(a []float64) float64 {
+		m := a[0]
+		for _, v := range a[1:] {
+			m = math.Max(m, v)
+		}
+		return m
+	}},
+}
+
+// Lexer
+
+type tokenKind int
+
+const (
+	tokenEnd tokenKind = iota
+	tokenNumber
+	tokenName
+	tokenOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func lex(src string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case isDigit(c) || c == '.':
+			start := i
+			for i < len(src) && (isDigit(src[i]) || src[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{tokenNumber, src[start:i], start})
+		case isLetter(c):
+			start := i
+			for i < len(src) && (isLetter(src[i]) || isDigit(src[i]) || src[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{tokenName, src[start:i], start})
+		case strings.IndexByte("+-*/%(),", c) >= 0:
+			tokens = append(tokens, token{tokenOp, string(c), i})
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected character %q at position %d", c, i+1)
+		}
+	}
+	return append(tokens, token{tokenEnd, "end of formula", len(src)}), nil
+}
+
+func isDigit(c byte) bool  { return c >= '0' && c <= '9' }
+func isLetter(c byte) bool { return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' }
+
+// Parser
+
+type parser struct {
+	tokens []token
+	next   int
+	vars   map[string]bool
+}
+
+func (p *parser) peek() token { return p.tokens[p.next] }
+
+func (p *parser) advance() token {
+	t := p.tokens[p.next]
+	if t.kind != tokenEnd {
+		p.next++
+	}
+	return t
+}
+
+func (p *parser) isOp(ops string) bool {
+	t := p.peek()
+	return t.kind == tokenOp && strings.Contains(ops, t.text)
+}
+
+func (p *parser) expect(op string) error {
+	if t := p.advance(); t.kind != tokenOp || t.text != op {
+		return fmt.Errorf("expected %q at position %d, got %q", op, t.pos+1, t.text)
+	}
+	return nil
+}
+
+func (p *parser) expr(depth int) (node, error) {
+	if depth > MaxDepth {
+		return nil, fmt.Errorf("formula is nested more than %d deep", MaxDepth)
+	}
+	left, err := p.term(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("+-") {
+		op := p.advance().text[0]
+		right, err := p.term(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = binary{op, left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) term(depth int) (node, error) {
+	left, err := p.unary(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("*/%") {
+		op := p.advance().text[0]
+		right, err := p.unary(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = binary{op, left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) unary(depth int) (node, error) {
+	if p.isOp("-") {
+		if depth > MaxDepth {
+			return nil, fmt.Errorf("formula is nested more than %d deep", MaxDepth)
+		}
+		p.advance()
+		operand, err := p.unary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return negate{operand}, nil
+	}
+	return p.primary(depth)
+}
+
+func (p *parser) primary(depth int) (node, error) {
+	t := p.advance()
+	switch t.kind {
+	case tokenNumber:
+		v, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", t.text, t.pos+1)
+		}
+		return number(v), nil
+
+	case tokenName:
+		if p.isOp("(") {
+			return p.call(t, depth)
+		}
+		if !isVariable(t.text) {
+			return nil, fmt.Errorf("unknown variable %q at position %d", t.text, t.pos+1)
+		}
+		p.vars[t.text] = true
+		return variable(t.text), nil
+
+	case tokenOp:
+		if t.text == "(" {
+			inner, err := p.expr(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return inner, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos+1)
+}
+
+func (p *parser) call(name token, depth int) (node, error) {
+	fn, ok := functions[name.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q at position %d", name.text, name.pos+1)
+	}
+	p.advance() // (
+
+	var args []node
+	for {
+		arg, err := p.expr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if !p.isOp(",") {
+			break
+		}
+		p.advance()
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+
+	if len(args) < fn.minArgs || (fn.maxArgs >= 0 && len(args) > fn.maxArgs) {
+		return nil, fmt.Errorf("%s takes %s, got %d", name.text, arity(fn), len(args))
+	}
+	return call{fn, args}, nil
+}
+
+func arity(fn function) string {
+	switch {
+	case fn.maxArgs < 0:
+		return fmt.Sprintf("at least %d arguments", fn.minArgs)
+	case fn.minArgs == 1 && fn.maxArgs == 1:
+		return "1 argument"
+	}
+	return fmt.Sprintf("%d arguments", fn.minArgs)
+}
@@ -0,0 +1,113 @@
+package formula
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func testEnv(name string) float64 {
+	return map[string]float64{
+		"caster.attack":    20,
+		"target.maxHealth": 100,
+		"target.defense":   5,
+		"effect.potency":   10,
+	}[name]
+}
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		src  string
+		want float64
+	}{
+		{"caster.attack * 1.5 + target.maxHealth * 0.05 - target.defense", 30},
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"-2 * -3", 6},
+		{"10 - 4 - 3", 3},
+		{"7 % 4", 3},
+		{"caster.attack / 0", 0},
+		{"5 % 0", 0},
+		{"min(3, 1, 2) + max(4, effect.potency)", 11},
+		{"clamp(caster.attack, 0, 15)", 15},
+		{"floor(2.7) + ceil(2.1) + round(2.5) + abs(-1)", 9},
+		{"target.speed", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			e, err := Parse(tt.src)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got := e.Eval(testEnv); got != tt.want {
+				t.Errorf("Eval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		src     string
+		wantErr string
+	}{
+		{"", "unexpected"},
+		{"1 +", "unexpected"},
+		{"(1 + 2", `expected ")"`},
+		{"1 2", "unexpected"},
+		{"caster.mana", "unknown variable"},
+		{"os.exit(1)", "unknown function"},
+		{"min()", "unexpected"},
+		{"clamp(1, 2)", "clamp takes 3 arguments"},
+		{"1 $ 2", "unexpected character"},
+		{"1.2.3", "invalid number"},
+		{strings.Repeat("(", MaxDepth+2) + "1" + strings.Repeat(")", MaxDepth+2), "nested"},
+		{strings.Repeat("-", MaxDepth+2) + "1", "nested"},
+		{strings.Repeat("1+", MaxLength), "longer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := Parse(tt.src)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse(%q) error = %v, want it to mention %q", tt.src, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExpr_Vars(t *testing.T) {
+	e, err := Parse("target.defense + caster.attack * target.defense")
+	if err != nil {
+		t.Fatal(err)
+	}
+	vars := e.Vars()
+	if len(vars) != 2 || vars[0] != "caster.attack" || vars[1] != "target.defense" {
+		t.Errorf("Vars() = %v", vars)
+	}
+
+	var nilExpr *Expr
+	if nilExpr.Eval(testEnv) != 0 || nilExpr.Vars() != nil {
+		t.Error("Expected a nil expression to evaluate to zero")
+	}
+}
+
+func TestExpr_JSON(t *testing.T) {
+	var holder struct {
+		Formula *Expr `json:"Formula,omitempty"`
+	}
+	if err := json.Unmarshal([]byte(`{"Formula": "caster.attack * 2"}`), &holder); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if got := holder.Formula.Eval(testEnv); got != 40 {
+		t.Errorf("Eval() = %v, want 40", got)
+	}
+
+	data, err := json.Marshal(holder)
+	if err != nil || string(data) != `{"Formula":"caster.attack * 2"}` {
+		t.Errorf("Marshal() = %s, %v", data, err)
+	}
+
+	if err := json.Unmarshal([]byte(`{"Formula": "caster.attack *"}`), &holder); err == nil {
+		t.Error("Expected an invalid formula to fail to unmarshal")
+	}
+}
@@ -1,5 +1,7 @@
 package game
 
+import "github.com/MaterDev/golang_turnbased_game_spike/internal/formula"
+
 // Ability represents a unique move of a character. Characters can have multiple abilities.
 type Ability struct {
 	Name         string          `json:"Name"`
@@ -7,6 +9,36 @@ type Ability struct {
 	Damage       int             `json:"Damage"`
 	CooldownMax  int             `json:"CooldownMax"`
 	Cooldown     int             `json:"Cooldown"`
+	// Formula, when set, gives the damage dealt in place of Damage plus the
+	// caster's Attack, and Defense is not taken off it again
+	Formula *formula.Expr `json:"Formula,omitempty"`
+	// SetsWeather changes the battle's weather for WeatherDuration rounds,
+	// or for the rest of the battle if WeatherDuration is zero.
+	// ClearsWeather ends whatever weather there is.
+	SetsWeather     Weather `json:"SetsWeather,omitempty"`
+	WeatherDuration int     `json:"WeatherDuration,omitempty"`
+	ClearsWeather   bool    `json:"ClearsWeather,omitempty"`
+	// Combo rewards using the ability after the right actions
+	Combo *Combo `json:"Combo,omitempty"`
+	// At most one of ChargeRounds, ChannelRounds and DelayRounds puts the
+	// ability off: it lands when the caster has charged it for that many
+	// rounds, every round it is channelled for, or once the delay is up.
+	// InterruptDamage breaks off a charge or channel once the caster has
+	// lost that much health since starting it; zero leaves only stuns.
+	ChargeRounds    int `json:"ChargeRounds,omitempty"`
+	ChannelRounds   int `json:"ChannelRounds,omitempty"`
+	DelayRounds     int `json:"DelayRounds,omitempty"`
+	InterruptDamage int `json:"InterruptDamage,omitempty"`
+	// Priority puts the ability in a bracket that resolves before (above
+	// zero) or after (below zero) ordinary actions, whatever the Speed
+	Priority int `json:"Priority,omitempty"`
+	// Summon brings a minion into battle on the caster's side in place of
+	// dealing damage
+	Summon *Summon `json:"Summon,omitempty"`
+	// Range is how many cells away on a grid the target may be; zero is
+	// melee, one cell. Area spreads the ability around its target.
+	Range int   `json:"Range,omitempty"`
+	Area  *Area `json:"Area,omitempty"`
 }
 
 // AbilityResult contains the result of using an ability
@@ -15,6 +47,29 @@ type AbilityResult struct {
 	Damage       int              `json:"Damage"`
 	StatusEffect *StatusEffectData `json:"StatusEffect,omitempty"`
 	Message      string           `json:"Message"`
+	// Missed is set when the ability went astray, as it may in fog
+	Missed bool `json:"Missed,omitempty"`
+	// Combo is set when the ability's combo landed
+	Combo bool `json:"Combo,omitempty"`
+	// Pending is set when the ability was put off to land later
+	Pending *PendingAbility `json:"Pending,omitempty"`
+	// EffectOutcome says whether StatusEffect landed in full, weakened or
+	// not at all, and AppliedEffect is what landed
+	EffectOutcome EffectOutcome     `json:"EffectOutcome,omitempty"`
+	AppliedEffect *StatusEffectData `json:"AppliedEffect,omitempty"`
+	// Summoned is the minion the ability brought into battle
+	Summoned *Minion `json:"Summoned,omitempty"`
+	// AreaHits lists the IDs of everyone else the ability's area caught
+	AreaHits []string `json:"AreaHits,omitempty"`
+}
+
+// hit shapes a single use of an ability by the battle it happens in
+type hit struct {
+	model DamageModel
+	// bonus is extra damage in percent, before Defense
+	bonus int
+	// extend lengthens the status effect the ability applies
+	extend int
 }
 
 func (a *Ability) CanUse() bool {
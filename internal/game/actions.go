@@ -0,0 +1,273 @@
+package game
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	// ActionDefend raises the actor's Defense until its next turn
+	ActionDefend ActionKind = "DEFEND"
+	// ActionWait moves the actor to the end of the current round's turn
+	// order without acting
+	ActionWait ActionKind = "WAIT"
+	// ActionSwap brings in the reserve named by TargetID in the actor's place
+	ActionSwap ActionKind = "SWAP"
+	// ActionFlee tries to leave a battle against a computer opponent
+	ActionFlee ActionKind = "FLEE"
+)
+
+// DefendResult reports a Defend action
+type DefendResult struct {
+	DefenseBonus int `json:"DefenseBonus"`
+}
+
+// WaitResult reports a Wait action with the turn order that follows it
+type WaitResult struct {
+	TurnOrder []string `json:"TurnOrder"`
+}
+
+// SwapResult reports a Swap action
+type SwapResult struct {
+	OutID string `json:"OutID"`
+	InID  string `json:"InID"`
+}
+
+// FleeResult reports a Flee attempt and the percentage chance it had
+type FleeResult struct {
+	Escaped bool `json:"Escaped"`
+	Chance  int  `json:"Chance"`
+}
+
+// defenseBonus is what Defend adds: half the character's Defense, and at
+// least 5
+func defenseBonus(c *Character) int {
+	return max(c.Defense/2, 5)
+}
+
+// lowerGuard removes the bonus of an earlier Defend. A defending character's
+// guard drops once it acts again, so every action lowers it as soon as it is
+// sure to go ahead; a rejected action leaves it up.
+func (c *Character) lowerGuard() {
+	c.Defense -= c.DefenseBonus
+	c.DefenseBonus = 0
+}
+
+func (b *Battle) applyDefend(actor *Character) BattleActionResult {
+	actor.lowerGuard()
+	bonus := defenseBonus(actor)
+	actor.DefenseBonus = bonus
+	actor.Defense += bonus
+
+	b.history = append(b.history, ActionRecord{
+		Round:       b.Round,
+		Kind:        ActionDefend,
+		CharacterID: actor.ID,
+	})
+	b.endTurn(actor, -1)
+
+	return BattleActionResult{
+		Success: true,
+		Message: fmt.Sprintf("%s defends, gaining %d defense", actor.Name, bonus),
+		Battle:  b,
+		Defend:  &DefendResult{DefenseBonus: bonus},
+	}
+}
+
+// applyWait lets the actor give up its place in this round and act last.
+// Status effects do not tick and cooldowns do not count down, since the
+// actor has not had its turn yet. Each character may wait once a round.
+func (b *Battle) applyWait(actor *Character) BattleActionResult {
+	if b.TurnMode == TurnModeATB {
+		return b.reject("cannot wait in ATB mode; simply act later")
+	}
+	if actor != b.currentTurn() {
+		return b.reject("you can only wait on your own turn")
+	}
+	if b.waited[actor.ID] {
+		return b.reject("already waited this round")
+	}
+	if b.turnIndex == len(b.turnOrder)-1 {
+		return b.reject("already last to act this round")
+	}
+	actor.lowerGuard()
+
+	order := append([]*Character(nil), b.turnOrder[:b.turnIndex]...)
+	order = append(order, b.turnOrder[b.turnIndex+1:]...)
+	b.turnOrder = append(order, actor)
+	if b.waited == nil {
+		b.waited = make(map[string]bool)
+	}
+	b.waited[actor.ID] = true
+	b.resetTurnTimer(time.Now())
+
+	b.history = append(b.history, ActionRecord{
+		Round:       b.Round,
+		Kind:        ActionWait,
+		CharacterID: actor.ID,
+	})
+
+	result := &WaitResult{}
+	for _, c := range b.turnOrder {
+		result.TurnOrder = append(result.TurnOrder, c.ID)
+	}
+	return BattleActionResult{
+		Success: true,
+		Message: fmt.Sprintf("%s waits", actor.Name),
+		Battle:  b,
+		Wait:    result,
+	}
+}
+
+// applySwap sends the actor to its side's reserves and brings in the
+// reserve with ID targetID, which takes the actor's place in turn order.
+// Swapping takes the actor's turn.
+func (b *Battle) applySwap(actor *Character, targetID string) BattleActionResult {
+	reserves := b.reservesOf(actor)
+	var in *Character
+	for _, r := range *reserves {
+		if r.ID == targetID {
+			in = r
+		}
+	}
+	switch {
+	case in == nil:
+		return b.reject("invalid reserve ID")
+	case in.Health <= 0:
+		return b.reject(fmt.Sprintf("%s is defeated", in.Name))
+	}
+
+	actor.lowerGuard()
+	b.history = append(b.history, ActionRecord{
+		Round:       b.Round,
+		Kind:        ActionSwap,
+		CharacterID: actor.ID,
+		TargetID:    in.ID,
+	})
+	b.replace(actor, in)
+	b.endTurn(in, -1)
+
+	return BattleActionResult{
+		Success: true,
+		Message: fmt.Sprintf("%s swaps out for %s", actor.Name, in.Name),
+		Battle:  b,
+		Swap:    &SwapResult{OutID: actor.ID, InID: in.ID},
+	}
+}
+
+// applyFlee tries to leave the battle. Only characters a human plays may
+// flee, and only from a computer opponent. The chance starts at 50% and
+// moves 5% for every point of Speed the actor has over, or lacks against,
+// the fastest opponent, staying between 10% and 90%. A failed attempt
+// takes the actor's turn.
+func (b *Battle) applyFlee(actor *Character) BattleActionResult {
+	if _, ok := b.controllers[actor]; ok {
+		return b.reject("computer-controlled characters cannot flee")
+	}
+	fastest := 0
+	for _, c := range b.combatants() {
+		if c == actor {
+			continue
+		}
+		if _, ok := b.controllers[c]; !ok {
+			return b.reject("cannot flee from another player")
+		}
+		fastest = max(fastest, c.Speed)
+	}
+	actor.lowerGuard()
+
+	chance := min(max(50+(actor.Speed-fastest)*5, 10), 90)
+	escaped := b.roll(100) < chance
+
+	b.history = append(b.history, ActionRecord{
+		Round:       b.Round,
+		Kind:        ActionFlee,
+		CharacterID: actor.ID,
+	})
+
+	result := &FleeResult{Escaped: escaped, Chance: chance}
+	if escaped {
+		b.Fled = actor
+		b.conclude()
+		return BattleActionResult{
+			Success: true,
+			Message: fmt.Sprintf("%s fled the battle", actor.Name),
+			Battle:  b,
+			Flee:    result,
+		}
+	}
+
+	b.endTurn(actor, -1)
+	return BattleActionResult{
+		Success: true,
+		Message: fmt.Sprintf("%s failed to escape", actor.Name),
+		Battle:  b,
+		Flee:    result,
+	}
+}
+
+// reservesOf returns the reserves on c's side of the battle
+func (b *Battle) reservesOf(c *Character) *[]*Character {
+	if c == b.Character2 {
+		return &b.Reserves2
+	}
+	return &b.Reserves1
+}
+
+// replace puts in, a reserve, into out's place in the battle, and out into
+// in's place among the reserves. A computer controlling out now controls in.
+func (b *Battle) replace(out, in *Character) {
+	reserves := b.reservesOf(out)
+	for i, r := range *reserves {
+		if r == in {
+			(*reserves)[i] = out
+		}
+	}
+	if b.Character1 == out {
+		b.Character1 = in
+	} else {
+		b.Character2 = in
+	}
+	for i, c := range b.turnOrder {
+		if c == out {
+			b.turnOrder[i] = in
+		}
+	}
+	if b.Grid != nil {
+		b.Grid.Positions[in.ID] = b.Grid.Positions[out.ID]
+		delete(b.Grid.Positions, out.ID)
+	}
+	if strategy, ok := b.controllers[out]; ok {
+		delete(b.controllers, out)
+		b.controllers[in] = strategy
+	}
+}
+
+// replaceDefeated brings in the first standing reserve for a defeated
+// character and reports whether there was one
+func (b *Battle) replaceDefeated(c *Character) bool {
+	for _, r := range *b.reservesOf(c) {
+		if r.Health > 0 {
+			b.replace(c, r)
+			return true
+		}
+	}
+	return false
+}
+
+func (b *Battle) reject(message string) BattleActionResult {
+	return BattleActionResult{
+		Success: false,
+		Message: message,
+		Battle:  b,
+	}
+}
+
+// roll returns a random number in [0, n)
+func (b *Battle) roll(n int) int {
+	if b.rng == nil {
+		return rand.IntN(n)
+	}
+	return b.rng.IntN(n)
+}
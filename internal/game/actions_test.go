@@ -0,0 +1,196 @@
+package game
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+// activate readies a battle for actions without starting its loop
+func activate(b *Battle) {
+	b.State = BattleStateActive
+	b.turnOrder = b.initiativeOrder()
+	b.turnIndex = 0
+}
+
+func TestBattle_Defend(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char1.Defense = 20
+	char1.Speed = 20
+	char2 := createTestCharacter("Mage", 100)
+	battle := newBattle(char1, char2, WithTurnMode(TurnModeSequential))
+	activate(battle)
+
+	result := battle.processAction(BattleAction{Kind: ActionDefend, CharacterID: char1.ID})
+	if !result.Success || result.Defend == nil || result.Defend.DefenseBonus != 10 {
+		t.Fatalf("Defend = %+v, want a bonus of 10", result)
+	}
+	if char1.Defense != 30 {
+		t.Errorf("Defense = %d, want 30 while defending", char1.Defense)
+	}
+
+	// Mage hits the defended Warrior, then the guard drops on its next turn
+	result = battle.processAction(BattleAction{CharacterID: char2.ID, AbilityIndex: 0, TargetID: char1.ID})
+	if !result.Success {
+		t.Fatalf("Attack failed: %s", result.Message)
+	}
+	if char1.Defense != 20 || char1.DefenseBonus != 0 {
+		t.Errorf("Defense = %d (bonus %d), want 20 once Warrior's turn comes again", char1.Defense, char1.DefenseBonus)
+	}
+}
+
+func TestBattle_RejectedActionKeepsGuard(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char1.Defense = 20
+	char2 := createTestCharacter("Mage", 100)
+	battle := newBattle(char1, char2)
+	activate(battle)
+
+	if result := battle.processAction(BattleAction{Kind: ActionDefend, CharacterID: char1.ID}); !result.Success {
+		t.Fatalf("Defend failed: %s", result.Message)
+	}
+	char1.Abilities[1].Cooldown = 1
+
+	rejected := map[string]BattleAction{
+		"bad ability index": {CharacterID: char1.ID, AbilityIndex: 5, TargetID: char2.ID},
+		"on cooldown":       {CharacterID: char1.ID, AbilityIndex: 1, TargetID: char2.ID},
+		"invalid target":    {CharacterID: char1.ID, AbilityIndex: 0, TargetID: "nobody"},
+		"bad item index":    {Kind: ActionItem, CharacterID: char1.ID, ItemIndex: 3, TargetID: char1.ID},
+	}
+	for name, action := range rejected {
+		if result := battle.processAction(action); result.Success {
+			t.Fatalf("%s: expected the action to be rejected", name)
+		}
+		if char1.Defense != 30 || char1.DefenseBonus != 10 {
+			t.Errorf("%s: Defense = %d (bonus %d), want the guard still up", name, char1.Defense, char1.DefenseBonus)
+		}
+	}
+
+	if result := battle.processAction(BattleAction{CharacterID: char1.ID, AbilityIndex: 0, TargetID: char2.ID}); !result.Success {
+		t.Fatalf("Attack failed: %s", result.Message)
+	}
+	if char1.Defense != 20 || char1.DefenseBonus != 0 {
+		t.Errorf("Defense = %d (bonus %d), want the guard down after acting", char1.Defense, char1.DefenseBonus)
+	}
+}
+
+func TestBattle_Wait(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char1.Speed = 20
+	char2 := createTestCharacter("Mage", 100)
+	battle := newBattle(char1, char2, WithTurnMode(TurnModeSequential))
+	activate(battle)
+
+	result := battle.processAction(BattleAction{Kind: ActionWait, CharacterID: char1.ID})
+	if !result.Success {
+		t.Fatalf("Wait failed: %s", result.Message)
+	}
+	if got := result.Wait.TurnOrder; len(got) != 2 || got[0] != char2.ID || got[1] != char1.ID {
+		t.Errorf("TurnOrder = %v, want Mage then Warrior", got)
+	}
+	if battle.CurrentTurn() != char2 {
+		t.Errorf("CurrentTurn() = %v, want Mage", battle.CurrentTurn().Name)
+	}
+	if len(battle.History()) != 1 || battle.Round != 1 {
+		t.Errorf("Waiting should be recorded without ending the round")
+	}
+
+	// Now last, the Warrior cannot wait again
+	battle.processAction(BattleAction{CharacterID: char2.ID, AbilityIndex: 0, TargetID: char1.ID})
+	if result := battle.processAction(BattleAction{Kind: ActionWait, CharacterID: char1.ID}); result.Success {
+		t.Error("Expected a second wait in the same round to be rejected")
+	}
+}
+
+func TestBattle_Swap(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char1.Speed = 20
+	reserve := createTestCharacter("Rogue", 60)
+	char2 := createTestCharacter("Mage", 100)
+	battle := newBattle(char1, char2, WithTurnMode(TurnModeSequential), WithReserves(1, reserve))
+	activate(battle)
+
+	if result := battle.processAction(BattleAction{Kind: ActionSwap, CharacterID: char1.ID, TargetID: char2.ID}); result.Success {
+		t.Error("Expected swapping in an opponent to be rejected")
+	}
+
+	result := battle.processAction(BattleAction{Kind: ActionSwap, CharacterID: char1.ID, TargetID: reserve.ID})
+	if !result.Success || result.Swap.OutID != char1.ID || result.Swap.InID != reserve.ID {
+		t.Fatalf("Swap = %+v", result)
+	}
+	if battle.Character1 != reserve || len(battle.Reserves1) != 1 || battle.Reserves1[0] != char1 {
+		t.Errorf("Expected Rogue in front and Warrior in reserve")
+	}
+	if battle.CurrentTurn() != char2 {
+		t.Errorf("Swapping should take the Warrior's turn")
+	}
+}
+
+func TestBattle_ReserveReplacesDefeated(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char1.Speed = 20
+	char2 := createTestCharacter("Mage", 5)
+	reserve := createTestCharacter("Healer", 50)
+	battle := newBattle(char1, char2, WithTurnMode(TurnModeSequential), WithReserves(2, reserve))
+	activate(battle)
+
+	battle.processAction(BattleAction{CharacterID: char1.ID, AbilityIndex: 0, TargetID: char2.ID})
+	if battle.State != BattleStateActive {
+		t.Fatalf("Battle should go on while a reserve stands, got %s", battle.State)
+	}
+	if battle.Character2 != reserve || battle.CurrentTurn() != reserve {
+		t.Errorf("Expected Healer to take the defeated Mage's place")
+	}
+
+	reserve.Health = 5
+	battle.processAction(BattleAction{CharacterID: reserve.ID, AbilityIndex: 0, TargetID: char1.ID})
+	battle.processAction(BattleAction{CharacterID: char1.ID, AbilityIndex: 0, TargetID: reserve.ID})
+	if battle.State != BattleStateComplete || battle.Winner != char1 {
+		t.Errorf("Expected Warrior to win once no reserves remain, got %s", battle.State)
+	}
+}
+
+func TestBattle_Flee(t *testing.T) {
+	tests := []struct {
+		name        string
+		speed       int
+		ai          bool
+		wantSuccess bool
+		wantChance  int
+	}{
+		{"even speed", 10, true, true, 50},
+		{"much faster", 30, true, true, 90},
+		{"much slower", 1, true, true, 10},
+		{"against a player", 10, false, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			char1 := createTestCharacter("Warrior", 100)
+			char1.Speed = tt.speed
+			char2 := createTestCharacter("Mage", 100)
+			opts := []BattleOption{WithRand(rand.New(rand.NewPCG(1, 2)))}
+			if tt.ai {
+				opts = append(opts, WithAI(char2, &RandomStrategy{}))
+			}
+			battle := newBattle(char1, char2, opts...)
+			activate(battle)
+
+			result := battle.processAction(BattleAction{Kind: ActionFlee, CharacterID: char1.ID})
+			if result.Success != tt.wantSuccess {
+				t.Fatalf("Flee success = %v, want %v (%s)", result.Success, tt.wantSuccess, result.Message)
+			}
+			if !tt.wantSuccess {
+				return
+			}
+			if result.Flee.Chance != tt.wantChance {
+				t.Errorf("Chance = %d, want %d", result.Flee.Chance, tt.wantChance)
+			}
+			if result.Flee.Escaped != (battle.State == BattleStateComplete && battle.Fled == char1) {
+				t.Errorf("Escaped = %v but State = %s, Fled = %v", result.Flee.Escaped, battle.State, battle.Fled)
+			}
+			if battle.Winner != nil {
+				t.Errorf("Fleeing should leave no winner")
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package game
+
+import "context"
+
+// TurnModeATB runs the battle in real time. Every tick of the battle loop
+// fills each character's gauge in proportion to its Speed, and a character
+// may act only once its gauge is full, which empties it again. Faster
+// characters therefore act more often.
+const TurnModeATB TurnMode = "ATB"
+
+const (
+	// GaugeFull is the gauge level at which a character may act
+	GaugeFull = 1000
+	// gaugeFillPerSpeed is how far one point of Speed fills a gauge each
+	// tick. At 100ms a tick, a character with Speed 10 acts every 2 seconds.
+	gaugeFillPerSpeed = 5
+)
+
+// Gauges returns every combatant's gauge level by character ID, between 0
+// and GaugeFull. It is empty unless the battle runs in ATB mode.
+func (b *Battle) Gauges() map[string]int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.gaugeLevels()
+}
+
+// gaugeLevels is Gauges for callers that hold b.mu
+func (b *Battle) gaugeLevels() map[string]int {
+	gauges := make(map[string]int)
+	if b.TurnMode != TurnModeATB {
+		return gauges
+	}
+	for _, c := range b.combatants() {
+		gauges[c.ID] = b.gauges[c.ID]
+	}
+	return gauges
+}
+
+// fillGauges advances every standing combatant's gauge by one tick. Those
+// who cannot act pass as soon as their gauge is full.
+func (b *Battle) fillGauges() {
+	if b.gauges == nil {
+		b.gauges = make(map[string]int)
+	}
+	for _, c := range b.combatants() {
+		// Speed already carries any StatusAccelerate, which raises it as it
+		// ticks
+		if c.Health > 0 {
+			b.gauges[c.ID] = min(b.gauges[c.ID]+max(c.Speed, 1)*gaugeFillPerSpeed, GaugeFull)
+		}
+	}
+	for _, c := range b.combatants() {
+		if b.State == BattleStateActive && b.gauges[c.ID] >= GaugeFull && b.locked(c) != "" {
+			b.endTurn(c, -1)
+		}
+	}
+}
+
+// ready returns the combatants whose gauges are full, fastest first, then
+// in the order they joined the battle
+func (b *Battle) ready() []*Character {
+	var ready []*Character
+	for _, c := range b.initiativeOrder() {
+		if b.gauges[c.ID] >= GaugeFull && c.Health > 0 {
+			ready = append(ready, c)
+		}
+	}
+	return ready
+}
+
+// nextReady fills gauges until somebody may act and returns them. The
+// simulator uses it to play ATB battles without waiting on a clock.
+func (b *Battle) nextReady() *Character {
+	for {
+		if ready := b.ready(); len(ready) > 0 {
+			return ready[0]
+		}
+		b.fillGauges()
+	}
+}
+
+// endATBTurn empties the actor's gauge. The round advances once every
+// standing character has acted in it.
+func (b *Battle) endATBTurn(actor *Character) {
+	if b.gauges == nil {
+		b.gauges = make(map[string]int)
+	}
+	b.gauges[actor.ID] = 0
+	if b.acted == nil {
+		b.acted = make(map[string]bool)
+	}
+	b.acted[actor.ID] = true
+	for _, c := range b.combatants() {
+		if !b.acted[c.ID] && c.Health > 0 {
+			return
+		}
+	}
+	b.acted = nil
+	b.nextRound()
+}
+
+// tickATB fills the gauges for one tick of the battle loop. Paused battles
+// stand still.
+func (b *Battle) tickATB() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.State != BattleStateActive {
+		return
+	}
+	b.fillGauges()
+}
+
+// runATBAI lets every computer-controlled character with a full gauge act
+func (b *Battle) runATBAI(ctx context.Context) {
+	b.mu.Lock()
+	if b.State != BattleStateActive {
+		b.mu.Unlock()
+		return
+	}
+	var actors []*Character
+	var strategies []Strategy
+	var views []BattleView
+	for _, c := range b.ready() {
+		if strategy, ok := b.controllers[c]; ok {
+			actors = append(actors, c)
+			strategies = append(strategies, strategy)
+			views = append(views, b.viewFor(c))
+		}
+	}
+	b.mu.Unlock()
+
+	for i, strategy := range strategies {
+		if ctx.Err() != nil {
+			return
+		}
+		// Strategies may take a while, so choose without holding the lock
+		action, ok := strategy.ChooseAction(views[i])
+
+		b.mu.Lock()
+		actor := actors[i]
+		if b.State == BattleStateActive && b.characterByID(actor.ID) != nil && b.gauges[actor.ID] >= GaugeFull {
+			// A strategy with nothing to do, or that picked an illegal move or
+			// one for somebody else, moves closer on a grid or passes
+			if (!ok || action.CharacterID != actor.ID || !b.applyAction(action).Success) && !b.advance(actor) {
+				b.endTurn(actor, -1)
+			}
+		}
+		b.mu.Unlock()
+	}
+}
@@ -0,0 +1,103 @@
+package game
+
+import (
+	"context"
+	"testing"
+)
+
+func TestBattle_ATBGauges(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char1.Speed = 20
+	char2 := createTestCharacter("Mage", 100)
+	battle := newBattle(char1, char2, WithTurnMode(TurnModeATB))
+	activate(battle)
+
+	attack := BattleAction{CharacterID: char1.ID, AbilityIndex: 0, TargetID: char2.ID}
+	if result := battle.processAction(attack); result.Success {
+		t.Fatal("Expected an action on an empty gauge to be rejected")
+	}
+
+	battle.tickATB()
+	if got := battle.Gauges(); got[char1.ID] != 20*gaugeFillPerSpeed || got[char2.ID] != 10*gaugeFillPerSpeed {
+		t.Errorf("Gauges() = %v, want Warrior filling twice as fast", got)
+	}
+
+	if ready := battle.nextReady(); ready != char1 {
+		t.Fatalf("nextReady() = %s, want Warrior", ready.Name)
+	}
+	if result := battle.processAction(attack); !result.Success {
+		t.Fatalf("Action on a full gauge failed: %s", result.Message)
+	}
+	if got := battle.Gauges()[char1.ID]; got != 0 {
+		t.Errorf("Gauge after acting = %d, want 0", got)
+	}
+
+	battle.State = BattleStatePaused
+	before := battle.Gauges()
+	battle.tickATB()
+	if after := battle.Gauges(); after[char2.ID] != before[char2.ID] {
+		t.Error("Gauges should not fill while paused")
+	}
+}
+
+func TestBattle_ATBAccelerateFill(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char1.StatusEffects = []StatusEffectData{{Type: StatusAccelerate, Duration: 10, Potency: 50}}
+	char2 := createTestCharacter("Mage", 100)
+	battle := newBattle(char1, char2, WithTurnMode(TurnModeATB))
+	activate(battle)
+
+	// The effect has not ticked yet, so Speed is still 10
+	battle.tickATB()
+	if got := battle.Gauges()[char1.ID]; got != 10*gaugeFillPerSpeed {
+		t.Errorf("Gauge after one tick = %d, want %d", got, 10*gaugeFillPerSpeed)
+	}
+
+	// Once it ticks, Speed is 15 and the gauge fills by that alone
+	char1.ProcessStatusEffect()
+	battle.tickATB()
+	if got := battle.Gauges()[char1.ID]; got != 25*gaugeFillPerSpeed {
+		t.Errorf("Gauge after two ticks = %d, want %d", got, 25*gaugeFillPerSpeed)
+	}
+}
+
+func TestBattle_ATBAccelerate(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 1000)
+	char1.StatusEffects = []StatusEffectData{{Type: StatusAccelerate, Duration: 10, Potency: 100}}
+	char2 := createTestCharacter("Mage", 1000)
+	for _, c := range []*Character{char1, char2} {
+		c.Abilities = c.Abilities[:1]
+		c.Abilities[0].Damage = 0
+	}
+	battle := newBattle(char1, char2, WithTurnMode(TurnModeATB),
+		WithAI(char1, GreedyStrategy{}), WithAI(char2, GreedyStrategy{}))
+
+	if err := battle.Play(12); err != nil {
+		t.Fatalf("Play() error = %v", err)
+	}
+	counts := map[string]int{}
+	for _, record := range battle.History() {
+		counts[record.CharacterID]++
+	}
+	if counts[char2.ID] == 0 || counts[char1.ID] < 2*counts[char2.ID]-1 {
+		t.Errorf("Accelerated Warrior acted %d times to the Mage's %d, want about twice as often",
+			counts[char1.ID], counts[char2.ID])
+	}
+}
+
+func TestBattle_ATBAIActsForItself(t *testing.T) {
+	human := createTestCharacter("Warrior", 100)
+	human.Speed = 20
+	bot := createTestCharacter("Mage", 100)
+	battle := newBattle(human, bot, WithTurnMode(TurnModeATB), WithAI(bot, hijackStrategy{human}))
+	activate(battle)
+	battle.gauges = map[string]int{human.ID: GaugeFull, bot.ID: GaugeFull}
+
+	battle.runATBAI(context.Background())
+	if got := battle.Gauges(); got[human.ID] != GaugeFull || got[bot.ID] != 0 {
+		t.Errorf("Gauges() = %v, want the Warrior's full and the Mage's spent", got)
+	}
+	if human.Health != 100 || human.Abilities[1].Cooldown != 0 {
+		t.Error("Expected the bot to leave the Warrior's turn alone")
+	}
+}
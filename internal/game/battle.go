@@ -1,7 +1,10 @@
 package game
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"math/rand/v2"
 	"sync"
 	"time"
 
@@ -13,6 +16,7 @@ type BattleState string
 const (
 	BattleStatePending  BattleState = "PENDING"
 	BattleStateActive   BattleState = "ACTIVE"
+	BattleStatePaused   BattleState = "PAUSED"
 	BattleStateComplete BattleState = "COMPLETE"
 )
 
@@ -25,33 +29,177 @@ type Battle struct {
 	Round      int
 	mu         sync.Mutex
 	ActionChan chan BattleAction
+
+	// CreatedAt is when the battle was created
+	CreatedAt time.Time
+
+	// PauseReason explains why a paused battle was paused
+	PauseReason string
+	// Rules the battle is played by. TurnMode repeats Rules.TurnMode.
+	Rules    RuleSet
+	TurnMode TurnMode
+
+	// Reserves1 and Reserves2 wait on each side to be swapped in, and take
+	// over when the character in front of them is defeated
+	Reserves1 []*Character
+	Reserves2 []*Character
+
+	// Fled is the character that escaped the battle, if one did
+	Fled *Character
+
+	// Pending holds abilities cast but yet to land or run their course:
+	// charges, channels and delayed effects
+	Pending []PendingAbility
+
+	// Environment is the weather over the battlefield, or nil for none
+	Environment *Environment
+
+	// Minions are the summoned characters in battle, in the order they were
+	// summoned, and summoned counts every minion ever summoned so that each
+	// gets its own ID
+	Minions  []*Minion
+	summoned int
+
+	// Grid is the map of a tactical battle, or nil for a duel
+	Grid *Grid
+
+	// Initiative for the current round and whose turn it is within it
+	turnOrder []*Character
+	turnIndex int
+
+	// waited holds the IDs of characters that have waited this round
+	waited map[string]bool
+
+	// sealed holds this round's actions in simultaneous mode, by character
+	// ID, and resolving is set while they play out
+	sealed    map[string]sealedAction
+	resolving bool
+
+	// gauges hold each character's progress towards acting in ATB mode, by
+	// character ID, and acted who has acted in the current round
+	gauges map[string]int
+	acted  map[string]bool
+
+	// rng decides chance outcomes such as fleeing; nil uses the global source
+	rng *rand.Rand
+
+	// controllers maps computer-controlled characters to their strategy
+	controllers map[*Character]Strategy
+
+	// history records every action taken, oldest first
+	history []ActionRecord
+
+	// concluded is set when the battle is decided by play rather than
+	// stopped, and onComplete is then called once
+	concluded  bool
+	onComplete func(*Battle)
+
+	// Turn timer. turnRemaining holds the time left on the timer while the
+	// battle is paused so that it can be restored on resume.
+	turnTimeout   time.Duration
+	turnDeadline  time.Time
+	turnRemaining time.Duration
+
+	// cancel stops the battle loop and done is closed once it has exited.
+	// Both are nil until the battle is started.
+	cancel context.CancelFunc
+	done   chan struct{}
 }
 
+var (
+	ErrBattleNotStarted = errors.New("battle not started")
+	ErrBattleNotActive  = errors.New("battle not active")
+)
+
+// ActionKind says what a battle action does
+type ActionKind string
+
+const (
+	// ActionAbility uses the ability at AbilityIndex. It is the default.
+	ActionAbility ActionKind = "ABILITY"
+	// ActionItem uses the consumable at ItemIndex
+	ActionItem ActionKind = "ITEM"
+)
+
 type BattleAction struct {
+	Kind          ActionKind
 	CharacterID   string
 	AbilityIndex  int
+	ItemIndex     int
 	TargetID      string
+	// To is where a Move action goes
+	To            *Position
 	ResponseChan  chan BattleActionResult
 }
 
+// ActionRecord is an entry in a battle's history
+type ActionRecord struct {
+	Round        int        `json:"Round"`
+	Kind         ActionKind `json:"Kind"`
+	CharacterID  string     `json:"CharacterID"`
+	AbilityIndex int        `json:"AbilityIndex"`
+	Ability      string     `json:"Ability,omitempty"` // Name of the ability used
+	ItemIndex    int        `json:"ItemIndex,omitempty"`
+	TargetID     string     `json:"TargetID"`
+	Damage       int        `json:"Damage"` // Health the target lost to the hit itself
+}
+
 type BattleActionResult struct {
 	Success bool
 	Message string
 	Battle  *Battle
+	// What the action did, set according to its kind
+	Ability *AbilityResult
+	Item    *ItemResult
+	Defend  *DefendResult
+	Wait    *WaitResult
+	Swap    *SwapResult
+	Flee    *FleeResult
+	Move    *MoveResult
+
+	// Sealed is set when the action waits for the rest of the round in
+	// simultaneous mode. The action that completes the round carries the
+	// Resolution of every action in it.
+	Sealed     bool
+	Resolution []ResolvedAction
 }
 
-func NewBattle(char1, char2 *Character) *Battle {
-	return &Battle{
+// NewBattle sets up a pending battle between char1 and char2. It fails if
+// the options leave the battle with rules it cannot be played by.
+func NewBattle(char1, char2 *Character, opts ...BattleOption) (*Battle, error) {
+	b := &Battle{
 		ID:         uuid.New().String(),
 		Character1: char1,
 		Character2: char2,
 		State:      BattleStatePending,
 		Round:      1,
-		ActionChan: make(chan BattleAction, 100),
+		Rules:      ClassicRules,
+		CreatedAt:  time.Now(),
 	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	if err := b.Rules.Validate(); err != nil {
+		return nil, err
+	}
+	if b.turnTimeout < 0 {
+		return nil, fmt.Errorf("turn timeout must not be negative, got %v", b.turnTimeout)
+	}
+	b.Rules = b.Rules.normalize()
+	b.TurnMode = b.Rules.TurnMode
+	b.ActionChan = make(chan BattleAction, b.Rules.ActionBuffer)
+	for _, c := range append(append(b.combatants(), b.Reserves1...), b.Reserves2...) {
+		if c.MaxHealth == 0 {
+			c.MaxHealth = c.Health
+		}
+	}
+	return b, nil
 }
 
-func (b *Battle) Start() error {
+// Start activates the battle and runs its loop until the battle completes,
+// Stop is called or ctx is cancelled, whichever happens first. A battle whose
+// loop exits undecided is marked complete without a winner.
+func (b *Battle) Start(ctx context.Context) error {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
@@ -60,43 +208,264 @@ func (b *Battle) Start() error {
 	}
 
 	b.State = BattleStateActive
-	
+	b.applyStartingCooldowns()
+	b.turnOrder = b.initiativeOrder()
+	b.turnIndex = 0
+	b.resetTurnTimer(time.Now())
+
 	// Start battle loop in goroutine
-	go b.battleLoop()
-	
+	ctx, b.cancel = context.WithCancel(ctx)
+	b.done = make(chan struct{})
+	go b.battleLoop(ctx)
+
 	return nil
 }
 
-func (b *Battle) battleLoop() {
+// Stop ends the battle and waits for its loop to exit. A battle that is still
+// pending or active is marked complete without a winner. Stop is safe to call
+// more than once and on battles that were never started.
+func (b *Battle) Stop() {
+	b.mu.Lock()
+	if b.State != BattleStateComplete {
+		b.State = BattleStateComplete
+	}
+	cancel, done := b.cancel, b.done
+	b.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// Pause freezes an active battle. Actions are rejected and the turn timer
+// stops until Resume is called.
+func (b *Battle) Pause(reason string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.State != BattleStateActive {
+		return ErrBattleNotActive
+	}
+
+	b.State = BattleStatePaused
+	b.PauseReason = reason
+	if b.turnTimeout > 0 {
+		b.turnRemaining = time.Until(b.turnDeadline)
+	}
+	return nil
+}
+
+// Resume continues a paused battle with the turn time that was left when it
+// was paused.
+func (b *Battle) Resume() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.State != BattleStatePaused {
+		return errors.New("battle not paused")
+	}
+
+	b.State = BattleStateActive
+	b.PauseReason = ""
+	if b.turnTimeout > 0 {
+		b.turnDeadline = time.Now().Add(b.turnRemaining)
+		b.turnRemaining = 0
+	}
+	return nil
+}
+
+// TurnTimeRemaining returns how long is left before the pending turn is
+// forfeited, or zero if the battle has no turn timer.
+func (b *Battle) TurnTimeRemaining() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.turnTimeRemaining()
+}
+
+// turnTimeRemaining is TurnTimeRemaining for callers that hold b.mu
+func (b *Battle) turnTimeRemaining() time.Duration {
+	switch {
+	case b.turnTimeout <= 0:
+		return 0
+	case b.State == BattleStatePaused:
+		return b.turnRemaining
+	case b.State != BattleStateActive:
+		return 0
+	}
+	if remaining := time.Until(b.turnDeadline); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// Done returns a channel that is closed once the battle loop has exited. It
+// returns nil for a battle that has not been started.
+func (b *Battle) Done() <-chan struct{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.done
+}
+
+func (b *Battle) battleLoop(ctx context.Context) {
 	ticker := time.NewTicker(100 * time.Millisecond)
 	defer ticker.Stop()
+	defer close(b.done)
+	defer b.cancel()
+	defer b.abandon()
+	defer b.notifyComplete()
+
+	// The computer may hold the first turn
+	b.runAITurns(ctx, len(b.combatants()))
 
 	for {
 		select {
 		case action := <-b.ActionChan:
 			result := b.processAction(action)
+
+			// Let the computer reply before answering so the caller sees its move
+			if result.Success {
+				b.runAITurns(ctx, len(b.combatants()))
+			}
+
 			if action.ResponseChan != nil {
-				action.ResponseChan <- result
+				select {
+				case action.ResponseChan <- result:
+				case <-ctx.Done():
+					return
+				}
 			}
-			
-			// Process status effects at the end of each round
-			if b.State == BattleStateActive {
-				b.Character1.ProcessStatusEffect()
-				b.Character2.ProcessStatusEffect()
+
+			if b.isComplete() {
+				return
 			}
 
-		case <-ticker.C:
+		case now := <-ticker.C:
+			if b.TurnMode == TurnModeATB {
+				b.tickATB()
+			}
 			// Check battle state
-			if b.State == BattleStateComplete {
+			if b.checkTurnTimer(now) {
 				return
 			}
+			// Picks up AI turns after a resume and paces AI-only battles
+			b.runAITurns(ctx, 1)
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// runAITurns plays up to max consecutive turns for computer-controlled
+// characters, stopping as soon as a human is due to act.
+func (b *Battle) runAITurns(ctx context.Context, max int) {
+	switch b.TurnMode {
+	case TurnModeSimultaneous:
+		b.sealAIActions(ctx)
+		return
+	case TurnModeATB:
+		b.runATBAI(ctx)
+		return
+	}
+	for i := 0; i < max && ctx.Err() == nil; i++ {
+		b.mu.Lock()
+		if b.State != BattleStateActive {
+			b.mu.Unlock()
+			return
+		}
+		actor := b.currentTurn()
+		strategy, ok := b.controllers[actor]
+		if !ok {
+			b.mu.Unlock()
+			return
+		}
+		view := b.viewFor(actor)
+		b.mu.Unlock()
+
+		// Strategies may take a while, so choose without holding the lock
+		action, ok := strategy.ChooseAction(view)
+
+		b.mu.Lock()
+		if ok {
+			ok = b.applyAction(action).Success
+		}
+		// A strategy with nothing to do, or that picked an illegal move, moves
+		// closer on a grid or passes
+		if !ok && b.State == BattleStateActive && b.currentTurn() == actor && !b.advance(actor) {
+			b.endTurn(actor, -1)
+		}
+		b.mu.Unlock()
+	}
+}
+
+// abandon marks a battle whose loop is exiting complete, so that it never
+// reports itself active without a loop to play it
+func (b *Battle) abandon() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.State != BattleStateComplete {
+		b.State = BattleStateComplete
+	}
+}
+
+// notifyComplete runs the completion callback of a concluded battle
+func (b *Battle) notifyComplete() {
+	b.mu.Lock()
+	concluded, onComplete := b.concluded, b.onComplete
+	b.mu.Unlock()
+
+	if concluded && onComplete != nil {
+		onComplete(b)
+	}
+}
+
+func (b *Battle) isComplete() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.State == BattleStateComplete
+}
+
+// checkTurnTimer forfeits the pending turn once the turn timer has run out
+// and reports whether the battle is complete. Paused battles never time out.
+func (b *Battle) checkTurnTimer(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// ATB battles have no turns to forfeit; characters simply wait when ready
+	if b.State == BattleStateActive && b.turnTimeout > 0 && b.TurnMode != TurnModeATB && now.After(b.turnDeadline) {
+		if b.TurnMode == TurnModeSimultaneous {
+			// Whoever has not sealed an action by now passes
+			b.resolveRound()
+		} else {
+			b.endTurn(b.currentTurn(), -1)
 		}
 	}
+	return b.State == BattleStateComplete
+}
+
+func (b *Battle) resetTurnTimer(now time.Time) {
+	if b.turnTimeout > 0 {
+		b.turnDeadline = now.Add(b.turnTimeout)
+	}
 }
 
 func (b *Battle) processAction(action BattleAction) BattleActionResult {
 	b.mu.Lock()
 	defer b.mu.Unlock()
+	return b.applyAction(action)
+}
+
+// applyAction resolves an action against the battle. The caller must hold
+// b.mu, or own the battle outright as the simulator does.
+func (b *Battle) applyAction(action BattleAction) BattleActionResult {
+	if b.State == BattleStatePaused {
+		return BattleActionResult{
+			Success: false,
+			Message: "battle is paused",
+			Battle:  b,
+		}
+	}
 
 	if b.State != BattleStateActive {
 		return BattleActionResult{
@@ -107,12 +476,8 @@ func (b *Battle) processAction(action BattleAction) BattleActionResult {
 	}
 
 	// Get acting character
-	var actor *Character
-	if b.Character1.ID == action.CharacterID {
-		actor = b.Character1
-	} else if b.Character2.ID == action.CharacterID {
-		actor = b.Character2
-	} else {
+	actor := b.characterByID(action.CharacterID)
+	if actor == nil {
 		return BattleActionResult{
 			Success: false,
 			Message: "invalid character ID",
@@ -120,22 +485,161 @@ func (b *Battle) processAction(action BattleAction) BattleActionResult {
 		}
 	}
 
-	// Get target character based on TargetID
-	var target *Character
-	if b.Character1.ID == action.TargetID {
-		target = b.Character1
-	} else if b.Character2.ID == action.TargetID {
-		target = b.Character2
-	} else {
+	if b.TurnMode == TurnModeSequential && actor != b.currentTurn() {
+		return b.jumpQueue(actor, action)
+	}
+
+	if reason := b.locked(actor); reason != "" {
+		return b.reject(reason)
+	}
+
+	if b.TurnMode == TurnModeSimultaneous && !b.resolving {
+		return b.seal(actor, action)
+	}
+	if b.TurnMode == TurnModeATB && b.gauges[actor.ID] < GaugeFull {
+		return b.reject(fmt.Sprintf("%s is not ready to act", actor.Name))
+	}
+
+	switch action.Kind {
+	case "", ActionAbility, ActionItem:
+		// Get target character based on TargetID
+		target := b.characterByID(action.TargetID)
+		if target == nil {
+			return BattleActionResult{
+				Success: false,
+				Message: "invalid target ID",
+				Battle:  b,
+			}
+		}
+		if action.Kind == ActionItem {
+			return b.applyItem(actor, target, action)
+		}
+		if target == actor && !b.Rules.AllowSelfTarget {
+			return b.reject("abilities cannot target their user under these rules")
+		}
+		return b.applyAbility(actor, target, action)
+	case ActionDefend:
+		return b.applyDefend(actor)
+	case ActionWait:
+		return b.applyWait(actor)
+	case ActionSwap:
+		return b.applySwap(actor, action.TargetID)
+	case ActionFlee:
+		return b.applyFlee(actor)
+	case ActionMove:
+		return b.applyMove(actor, action.To)
+	}
+	return BattleActionResult{
+		Success: false,
+		Message: fmt.Sprintf("unknown action kind %q", action.Kind),
+		Battle:  b,
+	}
+}
+
+func (b *Battle) applyAbility(actor, target *Character, action BattleAction) BattleActionResult {
+	if action.AbilityIndex < 0 || action.AbilityIndex >= len(actor.Abilities) {
+		return b.reject("Invalid ability index.")
+	}
+	ability := actor.Abilities[action.AbilityIndex]
+	if !b.unlocked(actor, target, ability) {
+		return b.reject(fmt.Sprintf("%s is a finisher and its combo is not ready", ability.Name))
+	}
+	if reason := b.outOfReach(actor, target, ability); reason != "" {
+		return b.reject(reason)
+	}
+	if kind, _ := ability.pendingKind(); kind != "" {
+		return b.applyPending(actor, target, action)
+	}
+	if ability.Summon != nil {
+		return b.applySummon(actor, action)
+	}
+	if !ability.CanUse() {
+		return b.reject("Ability on cooldown")
+	}
+	actor.lowerGuard()
+	combo := b.comboLands(actor, target, ability)
+	if b.misses(actor, target) {
+		return b.applyMiss(actor, target, action)
+	}
+
+	// Process the ability
+	healthBefore := target.Health
+	h := b.hitFor(ability)
+	if combo {
+		h.bonus += ability.Combo.Bonus
+	}
+	caught := b.caughtIn(actor, target, ability)
+	result := actor.useAbility(action.AbilityIndex, target, h)
+	if !result.Success {
 		return BattleActionResult{
 			Success: false,
-			Message: "invalid target ID",
+			Message: result.Message,
 			Battle:  b,
 		}
 	}
+	for _, c := range caught {
+		actor.strike(&ability, c, h)
+		result.AreaHits = append(result.AreaHits, c.ID)
+	}
+	if len(caught) > 0 {
+		result.Message += fmt.Sprintf("; %d more caught in the area", len(caught))
+	}
+	if combo {
+		result.Combo = true
+		result.Message += "; combo"
+	}
+	result.Message += b.changeWeather(ability)
 
-	// Process the ability
-	result := actor.UseAbility(action.AbilityIndex, target)
+	b.history = append(b.history, ActionRecord{
+		Round:        b.Round,
+		Kind:         ActionAbility,
+		CharacterID:  actor.ID,
+		AbilityIndex: action.AbilityIndex,
+		Ability:      ability.Name,
+		TargetID:     target.ID,
+		Damage:       healthBefore - target.Health,
+	})
+	b.endTurn(actor, action.AbilityIndex)
+
+	return BattleActionResult{
+		Success: true,
+		Message: result.Message,
+		Battle:  b,
+		Ability: &result,
+	}
+}
+
+// applyMiss spends the ability without effect
+func (b *Battle) applyMiss(actor, target *Character, action BattleAction) BattleActionResult {
+	ability := &actor.Abilities[action.AbilityIndex]
+	ability.Use()
+	b.history = append(b.history, ActionRecord{
+		Round:        b.Round,
+		Kind:         ActionAbility,
+		CharacterID:  actor.ID,
+		AbilityIndex: action.AbilityIndex,
+		Ability:      ability.Name,
+		TargetID:     target.ID,
+	})
+	b.endTurn(actor, action.AbilityIndex)
+
+	result := AbilityResult{
+		Success: true,
+		Missed:  true,
+		Message: fmt.Sprintf("%s misses %s in the fog", ability.Name, target.Name),
+	}
+	return BattleActionResult{
+		Success: true,
+		Message: result.Message,
+		Battle:  b,
+		Ability: &result,
+	}
+}
+
+// applyItem uses a consumable. It takes the actor's turn like an ability,
+// but every cooldown keeps counting down.
+func (b *Battle) applyItem(actor, target *Character, action BattleAction) BattleActionResult {
+	result := actor.UseItem(action.ItemIndex, target)
 	if !result.Success {
 		return BattleActionResult{
 			Success: false,
@@ -143,26 +647,124 @@ func (b *Battle) processAction(action BattleAction) BattleActionResult {
 			Battle:  b,
 		}
 	}
+	actor.lowerGuard()
 
-	// Check for battle end
-	if b.Character1.Health <= 0 {
-		b.Winner = b.Character2
-		b.State = BattleStateComplete
-	} else if b.Character2.Health <= 0 {
-		b.Winner = b.Character1
-		b.State = BattleStateComplete
-	}
+	b.history = append(b.history, ActionRecord{
+		Round:       b.Round,
+		Kind:        ActionItem,
+		CharacterID: actor.ID,
+		ItemIndex:   action.ItemIndex,
+		TargetID:    target.ID,
+		Damage:      result.Damage,
+	})
+	b.endTurn(actor, -1)
 
 	return BattleActionResult{
 		Success: true,
 		Message: result.Message,
 		Battle:  b,
+		Item:    &result,
 	}
 }
 
-func (b *Battle) SubmitAction(action BattleAction) BattleActionResult {
-	responseChan := make(chan BattleActionResult)
+// conclude completes a battle that was decided by play
+func (b *Battle) conclude() {
+	b.State = BattleStateComplete
+	b.concluded = true
+}
+
+// History returns a copy of every action taken so far, oldest first
+func (b *Battle) History() []ActionRecord {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]ActionRecord(nil), b.history...)
+}
+
+// BattleSummary is what sets one battle apart from another in a listing
+type BattleSummary struct {
+	ID    string
+	State BattleState
+	// WinnerID is the ID of the character that won, if any
+	WinnerID string
+	// Participants are the IDs of everyone who fights in the battle, in
+	// front or in reserve, and of whoever fled it
+	Participants []string
+	CreatedAt    time.Time
+}
+
+// Summary returns the battle's summary
+func (b *Battle) Summary() BattleSummary {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	summary := BattleSummary{ID: b.ID, State: b.State, CreatedAt: b.CreatedAt}
+	if b.Winner != nil {
+		summary.WinnerID = b.Winner.ID
+	}
+	everyone := append([]*Character{b.Character1, b.Character2}, b.Reserves1...)
+	everyone = append(everyone, b.Reserves2...)
+	if b.Fled != nil {
+		everyone = append(everyone, b.Fled)
+	}
+	for _, c := range everyone {
+		summary.Participants = append(summary.Participants, c.ID)
+	}
+	return summary
+}
+
+// checkBattleEnd brings in reserves for defeated characters, dismisses the
+// minions of those who fell and completes the battle once a side has nobody
+// left standing
+func (b *Battle) checkBattleEnd() {
+	b.interruptPending()
+	for _, c := range []*Character{b.Character1, b.Character2} {
+		if c.Health <= 0 {
+			b.replaceDefeated(c)
+		}
+	}
+	b.dismissMinions()
+	if b.Character1.Health <= 0 {
+		b.Winner = b.Character2
+		b.conclude()
+	} else if b.Character2.Health <= 0 {
+		b.Winner = b.Character1
+		b.conclude()
+	}
+}
+
+// SubmitAction hands the action to the battle loop and waits for its result.
+// It returns an error if the battle has not been started, if the loop has
+// exited, or if ctx is done before the action is processed.
+func (b *Battle) SubmitAction(ctx context.Context, action BattleAction) (BattleActionResult, error) {
+	done := b.Done()
+	if done == nil {
+		return BattleActionResult{}, ErrBattleNotStarted
+	}
+
+	// Buffered so the loop never blocks on a caller that has given up
+	responseChan := make(chan BattleActionResult, 1)
 	action.ResponseChan = responseChan
-	b.ActionChan <- action
-	return <-responseChan
+
+	select {
+	case b.ActionChan <- action:
+	case <-done:
+		return BattleActionResult{}, ErrBattleNotActive
+	case <-ctx.Done():
+		return BattleActionResult{}, ctx.Err()
+	}
+
+	select {
+	case result := <-responseChan:
+		return result, nil
+	case <-done:
+		// The loop may have answered just before exiting
+		select {
+		case result := <-responseChan:
+			return result, nil
+		default:
+			return BattleActionResult{}, ErrBattleNotActive
+		}
+	case <-ctx.Done():
+		return BattleActionResult{}, ctx.Err()
+	}
 }
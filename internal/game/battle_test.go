@@ -1,10 +1,22 @@
 package game
 
 import (
+	"context"
+	"errors"
+	"runtime"
 	"testing"
 	"time"
 )
 
+// newBattle is NewBattle for tests whose options are known to be good
+func newBattle(char1, char2 *Character, opts ...BattleOption) *Battle {
+	b, err := NewBattle(char1, char2, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
 func createTestCharacter(name string, health int) *Character {
 	return &Character{
 		ID:      name + "_id",
@@ -37,7 +49,7 @@ func TestNewBattle(t *testing.T) {
 	char1 := createTestCharacter("Warrior", 100)
 	char2 := createTestCharacter("Mage", 80)
 
-	battle := NewBattle(char1, char2)
+	battle := newBattle(char1, char2)
 
 	if battle.ID == "" {
 		t.Error("Expected battle ID to be generated")
@@ -56,9 +68,11 @@ func TestNewBattle(t *testing.T) {
 func TestBattle_Start(t *testing.T) {
 	char1 := createTestCharacter("Warrior", 100)
 	char2 := createTestCharacter("Mage", 80)
-	battle := NewBattle(char1, char2)
+	battle := newBattle(char1, char2)
 
-	if err := battle.Start(); err != nil {
+	t.Cleanup(battle.Stop)
+
+	if err := battle.Start(context.Background()); err != nil {
 		t.Errorf("Unexpected error starting battle: %v", err)
 	}
 
@@ -67,7 +81,7 @@ func TestBattle_Start(t *testing.T) {
 	}
 
 	// Test starting an already started battle
-	if err := battle.Start(); err == nil {
+	if err := battle.Start(context.Background()); err == nil {
 		t.Error("Expected error when starting an already started battle")
 	}
 }
@@ -75,10 +89,12 @@ func TestBattle_Start(t *testing.T) {
 func TestBattle_SubmitAction(t *testing.T) {
 	char1 := createTestCharacter("Warrior", 100)
 	char2 := createTestCharacter("Mage", 80)
-	battle := NewBattle(char1, char2)
+	battle := newBattle(char1, char2)
+
+	t.Cleanup(battle.Stop)
 
 	// Start the battle
-	if err := battle.Start(); err != nil {
+	if err := battle.Start(context.Background()); err != nil {
 		t.Fatalf("Failed to start battle: %v", err)
 	}
 
@@ -127,7 +143,10 @@ func TestBattle_SubmitAction(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := battle.SubmitAction(tt.action)
+			result, err := battle.SubmitAction(context.Background(), tt.action)
+			if err != nil {
+				t.Fatalf("SubmitAction() error = %v", err)
+			}
 			if result.Success != tt.wantSuccess {
 				t.Errorf("SubmitAction() success = %v, want %v", result.Success, tt.wantSuccess)
 			}
@@ -142,19 +161,24 @@ func TestBattle_BattleEnd(t *testing.T) {
 
 	char2 := createTestCharacter("Mage", 80)
 	char2.Attack = 50 // Increase attack to ensure lethal damage
-	battle := NewBattle(char1, char2)
+	battle := newBattle(char1, char2)
+
+	t.Cleanup(battle.Stop)
 
 	// Start the battle
-	if err := battle.Start(); err != nil {
+	if err := battle.Start(context.Background()); err != nil {
 		t.Fatalf("Failed to start battle: %v", err)
 	}
 
 	// Use special attack that should deal lethal damage
-	result := battle.SubmitAction(BattleAction{
+	result, err := battle.SubmitAction(context.Background(), BattleAction{
 		CharacterID:  char2.ID,
 		AbilityIndex: 1, // Special Attack
 		TargetID:     char1.ID,
 	})
+	if err != nil {
+		t.Fatalf("SubmitAction() error = %v", err)
+	}
 
 	if !result.Success {
 		t.Fatalf("Attack failed: %v", result.Message)
@@ -177,9 +201,11 @@ func TestBattle_BattleEnd(t *testing.T) {
 func TestBattle_ConcurrentActions(t *testing.T) {
 	char1 := createTestCharacter("Warrior", 100)
 	char2 := createTestCharacter("Mage", 100)
-	battle := NewBattle(char1, char2)
+	battle := newBattle(char1, char2)
+
+	t.Cleanup(battle.Stop)
 
-	if err := battle.Start(); err != nil {
+	if err := battle.Start(context.Background()); err != nil {
 		t.Fatalf("Failed to start battle: %v", err)
 	}
 
@@ -187,7 +213,7 @@ func TestBattle_ConcurrentActions(t *testing.T) {
 	done := make(chan bool)
 	go func() {
 		for i := 0; i < 5; i++ {
-			battle.SubmitAction(BattleAction{
+			battle.SubmitAction(context.Background(), BattleAction{
 				CharacterID:  char1.ID,
 				AbilityIndex: 0,
 				TargetID:     char2.ID,
@@ -198,7 +224,7 @@ func TestBattle_ConcurrentActions(t *testing.T) {
 
 	go func() {
 		for i := 0; i < 5; i++ {
-			battle.SubmitAction(BattleAction{
+			battle.SubmitAction(context.Background(), BattleAction{
 				CharacterID:  char2.ID,
 				AbilityIndex: 0,
 				TargetID:     char1.ID,
@@ -219,3 +245,345 @@ func TestBattle_ConcurrentActions(t *testing.T) {
 		t.Error("Expected char2 to take damage")
 	}
 }
+
+func TestBattle_SubmitActionNotStarted(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char2 := createTestCharacter("Mage", 80)
+	battle := newBattle(char1, char2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_, err := battle.SubmitAction(ctx, BattleAction{
+		CharacterID:  char1.ID,
+		AbilityIndex: 0,
+		TargetID:     char2.ID,
+	})
+	if !errors.Is(err, ErrBattleNotStarted) {
+		t.Errorf("SubmitAction() error = %v, want %v", err, ErrBattleNotStarted)
+	}
+}
+
+func TestBattle_SubmitActionDeadline(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char2 := createTestCharacter("Mage", 80)
+	battle := newBattle(char1, char2)
+	// An unbuffered channel that nobody reads, as if the loop were stuck
+	battle.ActionChan = make(chan BattleAction)
+	battle.done = make(chan struct{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := battle.SubmitAction(ctx, BattleAction{
+		CharacterID:  char1.ID,
+		AbilityIndex: 0,
+		TargetID:     char2.ID,
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("SubmitAction() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestBattle_Stop(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char2 := createTestCharacter("Mage", 80)
+	battle := newBattle(char1, char2)
+
+	if err := battle.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start battle: %v", err)
+	}
+
+	battle.Stop()
+	battle.Stop() // Stopping twice must be safe
+
+	select {
+	case <-battle.Done():
+	default:
+		t.Fatal("Expected battle loop to have exited after Stop")
+	}
+	if battle.State != BattleStateComplete {
+		t.Errorf("Expected battle state to be COMPLETE, got %v", battle.State)
+	}
+
+	_, err := battle.SubmitAction(context.Background(), BattleAction{
+		CharacterID:  char1.ID,
+		AbilityIndex: 0,
+		TargetID:     char2.ID,
+	})
+	if !errors.Is(err, ErrBattleNotActive) {
+		t.Errorf("SubmitAction() error = %v, want %v", err, ErrBattleNotActive)
+	}
+}
+
+func TestBattle_ContextCancel(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char2 := createTestCharacter("Mage", 80)
+	battle := newBattle(char1, char2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := battle.Start(ctx); err != nil {
+		t.Fatalf("Failed to start battle: %v", err)
+	}
+	cancel()
+
+	select {
+	case <-battle.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Expected battle loop to exit after context cancellation")
+	}
+	if battle.State != BattleStateComplete || battle.Winner != nil {
+		t.Errorf("Expected a cancelled battle to be complete without a winner, got %v", battle.State)
+	}
+	_, err := battle.SubmitAction(context.Background(), BattleAction{CharacterID: char1.ID, TargetID: char2.ID})
+	if !errors.Is(err, ErrBattleNotActive) {
+		t.Errorf("SubmitAction() error = %v, want %v", err, ErrBattleNotActive)
+	}
+}
+
+func TestBattle_NoGoroutineLeaks(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	for i := 0; i < 10; i++ {
+		// Stopped mid-battle
+		char1 := createTestCharacter("Warrior", 100)
+		char2 := createTestCharacter("Mage", 80)
+		stopped := newBattle(char1, char2)
+		if err := stopped.Start(context.Background()); err != nil {
+			t.Fatalf("Failed to start battle: %v", err)
+		}
+		stopped.SubmitAction(context.Background(), BattleAction{
+			CharacterID:  char1.ID,
+			AbilityIndex: 0,
+			TargetID:     char2.ID,
+		})
+		stopped.Stop()
+
+		// Cancelled through its context
+		ctx, cancel := context.WithCancel(context.Background())
+		cancelled := newBattle(createTestCharacter("Warrior", 100), createTestCharacter("Mage", 80))
+		if err := cancelled.Start(ctx); err != nil {
+			t.Fatalf("Failed to start battle: %v", err)
+		}
+		cancel()
+		<-cancelled.Done()
+
+		// Played to completion without Stop
+		loser := createTestCharacter("Warrior", 1)
+		loser.Defense = 0
+		winner := createTestCharacter("Mage", 80)
+		finished := newBattle(loser, winner)
+		if err := finished.Start(context.Background()); err != nil {
+			t.Fatalf("Failed to start battle: %v", err)
+		}
+		finished.SubmitAction(context.Background(), BattleAction{
+			CharacterID:  winner.ID,
+			AbilityIndex: 0,
+			TargetID:     loser.ID,
+		})
+		<-finished.Done()
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("Goroutines leaked: %d before, %d after", before, runtime.NumGoroutine())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestBattle_PauseResume(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char2 := createTestCharacter("Mage", 80)
+	battle := newBattle(char1, char2)
+	t.Cleanup(battle.Stop)
+
+	if err := battle.Pause("too early"); err == nil {
+		t.Error("Expected error when pausing a pending battle")
+	}
+
+	if err := battle.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start battle: %v", err)
+	}
+
+	if err := battle.Pause("moderator review"); err != nil {
+		t.Fatalf("Unexpected error pausing battle: %v", err)
+	}
+	if battle.State != BattleStatePaused {
+		t.Errorf("Expected battle state to be PAUSED, got %v", battle.State)
+	}
+	if err := battle.Pause("again"); err == nil {
+		t.Error("Expected error when pausing an already paused battle")
+	}
+
+	action := BattleAction{
+		CharacterID:  char1.ID,
+		AbilityIndex: 0,
+		TargetID:     char2.ID,
+	}
+	result, err := battle.SubmitAction(context.Background(), action)
+	if err != nil {
+		t.Fatalf("SubmitAction() error = %v", err)
+	}
+	if result.Success || result.Message != "battle is paused" {
+		t.Errorf("Expected paused battle to reject action, got %+v", result)
+	}
+	if char2.Health != 80 {
+		t.Errorf("Expected no damage while paused, got health %d", char2.Health)
+	}
+
+	if err := battle.Resume(); err != nil {
+		t.Fatalf("Unexpected error resuming battle: %v", err)
+	}
+	if err := battle.Resume(); err == nil {
+		t.Error("Expected error when resuming an active battle")
+	}
+	if battle.PauseReason != "" {
+		t.Errorf("Expected pause reason to be cleared, got %q", battle.PauseReason)
+	}
+
+	result, err = battle.SubmitAction(context.Background(), action)
+	if err != nil {
+		t.Fatalf("SubmitAction() error = %v", err)
+	}
+	if !result.Success {
+		t.Errorf("Expected action to succeed after resume: %v", result.Message)
+	}
+}
+
+func TestBattle_TurnTimeout(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char1.StatusEffects = []StatusEffectData{{Type: StatusRegenerating, Duration: 10, Potency: 10}}
+	char2 := createTestCharacter("Mage", 80)
+	battle := newBattle(char1, char2, WithTurnTimeout(150*time.Millisecond))
+	t.Cleanup(battle.Stop)
+
+	if err := battle.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start battle: %v", err)
+	}
+	if err := battle.Pause("maintenance"); err != nil {
+		t.Fatalf("Unexpected error pausing battle: %v", err)
+	}
+
+	// The timer is frozen while paused, so no turn is forfeited
+	frozen := battle.TurnTimeRemaining()
+	time.Sleep(400 * time.Millisecond)
+	if remaining := battle.TurnTimeRemaining(); remaining != frozen {
+		t.Errorf("Expected turn timer to stay at %v while paused, got %v", frozen, remaining)
+	}
+	battle.mu.Lock()
+	effects := len(char1.StatusEffects) > 0 && char1.StatusEffects[0].Duration == 10
+	battle.mu.Unlock()
+	if !effects {
+		t.Error("Expected status effects not to tick while paused")
+	}
+
+	if err := battle.Resume(); err != nil {
+		t.Fatalf("Unexpected error resuming battle: %v", err)
+	}
+	time.Sleep(400 * time.Millisecond)
+
+	battle.mu.Lock()
+	defer battle.mu.Unlock()
+	if char1.StatusEffects[0].Duration >= 10 {
+		t.Error("Expected forfeited turns to tick status effects after resume")
+	}
+}
+
+func TestBattle_TurnOrder(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char2 := createTestCharacter("Mage", 100)
+	char2.Speed = 20
+	battle := newBattle(char1, char2, WithTurnMode(TurnModeSequential))
+	t.Cleanup(battle.Stop)
+
+	if err := battle.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start battle: %v", err)
+	}
+	if battle.CurrentTurn() != char2 {
+		t.Fatal("Expected the faster character to act first")
+	}
+
+	submit := func(actor, target *Character, ability int) BattleActionResult {
+		t.Helper()
+		result, err := battle.SubmitAction(context.Background(), BattleAction{
+			CharacterID:  actor.ID,
+			AbilityIndex: ability,
+			TargetID:     target.ID,
+		})
+		if err != nil {
+			t.Fatalf("SubmitAction() error = %v", err)
+		}
+		return result
+	}
+
+	if result := submit(char1, char2, 0); result.Success || result.Message != "not your turn" {
+		t.Errorf("Expected out-of-turn action to be rejected, got %+v", result)
+	}
+	if result := submit(char2, char1, 1); !result.Success {
+		t.Fatalf("Expected action to succeed: %v", result.Message)
+	}
+	if battle.CurrentTurn() != char1 {
+		t.Error("Expected turn to pass to the slower character")
+	}
+	if result := submit(char1, char2, 0); !result.Success {
+		t.Fatalf("Expected action to succeed: %v", result.Message)
+	}
+
+	battle.mu.Lock()
+	if battle.Round != 2 {
+		t.Errorf("Expected round 2 after both characters acted, got %d", battle.Round)
+	}
+	if cooldown := char2.Abilities[1].Cooldown; cooldown != 2 {
+		t.Errorf("Expected cooldown to hold until its owner's next turn, got %d", cooldown)
+	}
+	battle.mu.Unlock()
+
+	// Each of the owner's later turns counts the cooldown down
+	submit(char2, char1, 0)
+	battle.mu.Lock()
+	if cooldown := char2.Abilities[1].Cooldown; cooldown != 1 {
+		t.Errorf("Expected cooldown 1 after another turn, got %d", cooldown)
+	}
+	battle.mu.Unlock()
+}
+
+func TestBattle_OnComplete(t *testing.T) {
+	loser := createTestCharacter("Warrior", 1)
+	loser.Defense = 0
+	winner := createTestCharacter("Mage", 80)
+
+	completed := make(chan *Character, 1)
+	battle := newBattle(loser, winner, WithOnComplete(func(b *Battle) {
+		completed <- b.Winner
+	}))
+
+	if err := battle.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start battle: %v", err)
+	}
+	battle.SubmitAction(context.Background(), BattleAction{
+		CharacterID:  winner.ID,
+		AbilityIndex: 0,
+		TargetID:     loser.ID,
+	})
+	<-battle.Done()
+
+	select {
+	case w := <-completed:
+		if w != winner {
+			t.Errorf("Expected callback to see the winner, got %v", w)
+		}
+	default:
+		t.Fatal("Expected completion callback to run before the loop exits")
+	}
+
+	// Stopping a battle is not a conclusion
+	stopped := newBattle(createTestCharacter("Warrior", 100), createTestCharacter("Mage", 80), WithOnComplete(func(b *Battle) {
+		t.Error("Expected no callback for a stopped battle")
+	}))
+	if err := stopped.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start battle: %v", err)
+	}
+	stopped.Stop()
+}
@@ -8,12 +8,33 @@ import (
 type Character struct {
 	ID            string           `json:"ID"`
 	Name          string           `json:"Name"`
+	// Class is the ID of the character's class, if it has one
+	Class         string           `json:"Class,omitempty"`
 	Abilities     []Ability        `json:"Abilities"`
 	StatusEffects []StatusEffectData `json:"StatusEffects"`
 	Health        int              `json:"Health"`
+	// MaxHealth is the health the character entered battle with
+	MaxHealth     int              `json:"MaxHealth,omitempty"`
 	Attack        int              `json:"Attack"`
 	Defense       int              `json:"Defense"`
+	// DefenseBonus is the part of Defense added by defending, which lasts
+	// until the character's next turn
+	DefenseBonus  int              `json:"DefenseBonus,omitempty"`
 	Speed         int              `json:"Speed"`
+	// Resistances reduce the potency of incoming status effects, or the
+	// duration of crowd control, by a percentage; 100 makes the character
+	// immune
+	Resistances map[StatusEffect]int `json:"Resistances,omitempty"`
+	// Immunities are status effects that never land on the character
+	Immunities []StatusEffect `json:"Immunities,omitempty"`
+	// TemporaryImmunities hold how many more ticks the character shrugs off
+	// crowd control that has just worn off, by effect
+	TemporaryImmunities map[StatusEffect]int `json:"TemporaryImmunities,omitempty"`
+	// Inventory holds the consumables carried into this battle
+	Inventory []Consumable `json:"Inventory,omitempty"`
+	// Movement is how many cells the character may move at a time on a
+	// grid; zero moves DefaultMovement
+	Movement int `json:"Movement,omitempty"`
 }
 
 // IsValid will check if the character has valid stats
@@ -25,19 +46,54 @@ func (c Character) IsValid() bool {
 		c.Speed > 0
 }
 
-func (c *Character) TakeDamage(damage int) {
-	actualDamage := damage - c.Defense
-	if actualDamage < 0 {
-		actualDamage = 0
+// Clone returns a deep copy of the character, so that changes to the copy's
+// abilities and status effects leave the original untouched
+func (c Character) Clone() Character {
+	c.Abilities = append([]Ability(nil), c.Abilities...)
+	c.StatusEffects = append([]StatusEffectData(nil), c.StatusEffects...)
+	if c.Inventory != nil {
+		inventory := make([]Consumable, len(c.Inventory))
+		for i, item := range c.Inventory {
+			item.Cures = append([]StatusEffect(nil), item.Cures...)
+			inventory[i] = item
+		}
+		c.Inventory = inventory
 	}
-	c.Health -= actualDamage
-	if c.Health < 0 {
-		c.Health = 0
+	if c.Resistances != nil {
+		resistances := make(map[StatusEffect]int, len(c.Resistances))
+		for effect, percent := range c.Resistances {
+			resistances[effect] = percent
+		}
+		c.Resistances = resistances
 	}
+	c.Immunities = append([]StatusEffect(nil), c.Immunities...)
+	if c.TemporaryImmunities != nil {
+		immunities := make(map[StatusEffect]int, len(c.TemporaryImmunities))
+		for effect, ticks := range c.TemporaryImmunities {
+			immunities[effect] = ticks
+		}
+		c.TemporaryImmunities = immunities
+	}
+	return c
+}
+
+func (c *Character) TakeDamage(damage int) {
+	c.takeDamage(damage, DamageSubtract)
+}
+
+// takeDamage lowers Health by what is left of damage once model has taken
+// Defense into account
+func (c *Character) takeDamage(damage int, model DamageModel) {
+	c.Health = max(c.Health-model.mitigate(damage, c.Defense), 0)
 }
 
 // Will allow character to use ability on a target
 func (c *Character) UseAbility(abilityIndex int, target *Character) AbilityResult {
+	return c.useAbility(abilityIndex, target, hit{model: DamageSubtract})
+}
+
+// useAbility uses an ability as shaped by the battle around it
+func (c *Character) useAbility(abilityIndex int, target *Character, h hit) AbilityResult {
 	// Make sure the index is within the bounds of the []Abilities
 	if abilityIndex >= len(c.Abilities) {
 		return AbilityResult{
@@ -54,27 +110,93 @@ func (c *Character) UseAbility(abilityIndex int, target *Character) AbilityResul
 		}
 	}
 
-	// Calculate total damage based on ability damage and character's Attack stat
-	damage := ability.Damage + c.Attack
-
-	// Apply the damage to target
-	target.TakeDamage(damage)
-
-	// Apply status effect if present
-	if ability.StatusEffect.Type != "" {
-		target.StatusEffects = append(target.StatusEffects, ability.StatusEffect)
-	}
+	result := c.strike(ability, target, h)
 
 	// Call ability.Use() to set cooldown
 	ability.Use()
+	return result
+}
+
+// strike deals ability's damage and status effect to target
+func (c *Character) strike(ability *Ability, target *Character, h hit) AbilityResult {
+	damage := c.rawDamage(ability, target, h)
+	if ability.Formula != nil {
+		target.Health = max(target.Health-damage, 0)
+	} else {
+		target.takeDamage(damage, h.model)
+	}
 
 	// Return result with information about what happened
-	return AbilityResult{
+	result := AbilityResult{
 		Success:      true,
 		Damage:       damage,
 		StatusEffect: &ability.StatusEffect,
 		Message:      fmt.Sprintf("Ability used successfully for %d damage", damage),
 	}
+
+	// Apply status effect if present and not resisted outright
+	if ability.StatusEffect.Type != "" {
+		effect, outcome := target.resist(ability.StatusEffect)
+		result.EffectOutcome = outcome
+		if outcome != EffectImmune {
+			effect.Duration += h.extend
+			target.StatusEffects = append(target.StatusEffects, effect)
+			result.AppliedEffect = &effect
+		}
+	}
+	return result
+}
+
+// rawDamage is the damage of ability as shaped by h, before Defense: the
+// ability's damage plus the character's Attack, or the ability's own formula
+func (c *Character) rawDamage(ability *Ability, target *Character, h hit) int {
+	var damage int
+	if ability.Formula != nil {
+		damage = amount(ability.Formula.Eval(abilityEnv(c, target, ability)))
+	} else {
+		damage = ability.Damage + c.Attack
+	}
+	return damage + damage*h.bonus/100
+}
+
+// healthLost is the health target loses when c hits it with ability, as
+// strike deals it, without stopping at the target's remaining health.
+// Formula damage already accounts for Defense as the formula sees fit.
+func (c *Character) healthLost(ability *Ability, target *Character, h hit) int {
+	damage := c.rawDamage(ability, target, h)
+	if ability.Formula != nil {
+		return damage
+	}
+	return h.model.mitigate(damage, target.Defense)
+}
+
+// resist weakens an incoming effect by the character's resistance to it and
+// says what became of it. Resistance cuts crowd control short rather than
+// weakening it, though never to nothing.
+func (c *Character) resist(effect StatusEffectData) (StatusEffectData, EffectOutcome) {
+	percent := c.Resistances[effect.Type]
+	if percent >= 100 || c.immuneTo(effect.Type) {
+		return effect, EffectImmune
+	}
+	if percent <= 0 {
+		return effect, EffectApplied
+	}
+	if effect.Type.IsCrowdControl() {
+		effect.Duration = max(effect.Duration*(100-percent)/100, 1)
+	} else {
+		effect.Potency = effect.Potency * (100 - percent) / 100
+	}
+	return effect, EffectResisted
+}
+
+// immuneTo reports whether the effect cannot land on c, for good or for now
+func (c *Character) immuneTo(effect StatusEffect) bool {
+	for _, immunity := range c.Immunities {
+		if immunity == effect {
+			return true
+		}
+	}
+	return c.TemporaryImmunities[effect] > 0
 }
 
 // Process status effect - handle all active status effects.
@@ -82,6 +204,13 @@ func (c *Character) UseAbility(abilityIndex int, target *Character) AbilityResul
 // switch fof each type of StatusEffect
 //
 func (c *Character) ProcessStatusEffect() {
+	// Temporary immunities wear off first, so fresh ones last their full time
+	for effect := range c.TemporaryImmunities {
+		if c.TemporaryImmunities[effect]--; c.TemporaryImmunities[effect] <= 0 {
+			delete(c.TemporaryImmunities, effect)
+		}
+	}
+
 	// Create a new slice to store active effects
 	activeEffects := make([]StatusEffectData, 0)
 
@@ -91,38 +220,22 @@ func (c *Character) ProcessStatusEffect() {
 			continue
 		}
 
-		// Process effect based on type
-		switch effect.Type {
-		case StatusAccelerate:
-			// Increase speed based on potency
-			speedIncrease := c.GetEffectScalingValue("speed", 100, effect.Potency, 1)
-			c.Speed = speedIncrease
-
-		case StatusBurning:
-			// Increasing damage over time
-			burnDamage := c.GetEffectScalingValue("health", 100, effect.Potency*effect.Duration, 10)
-			c.TakeDamage(burnDamage)
-
-		case StatusPoisoned:
-			// Decreasing damage over time
-			poisonDamage := c.GetEffectScalingValue("health", 100, effect.Potency, effect.Duration)
-			c.TakeDamage(poisonDamage)
-
-		case StatusEnraged:
-			// Increase attack based on potency
-			attackIncrease := c.GetEffectScalingValue("attack", 100, effect.Potency, 1)
-			c.Attack = attackIncrease
-
-		case StatusRegenerating:
-			// Heal based on potency
-			healAmount := c.GetEffectScalingValue("health", 100, effect.Potency, 10)
-			c.Health += healAmount
+		if effect.Formula != nil {
+			c.applyEffectFormula(effect)
+		} else {
+			c.applyEffect(effect)
 		}
 
 		// Decrease duration and keep active effects
 		effect.Duration--
 		if effect.Duration > 0 {
 			activeEffects = append(activeEffects, effect)
+		} else if effect.Type.IsCrowdControl() {
+			// Diminishing returns: the same crowd control cannot chain
+			if c.TemporaryImmunities == nil {
+				c.TemporaryImmunities = make(map[StatusEffect]int)
+			}
+			c.TemporaryImmunities[effect.Type] = crowdControlImmunity
 		}
 	}
 
@@ -130,6 +243,53 @@ func (c *Character) ProcessStatusEffect() {
 	c.StatusEffects = activeEffects
 }
 
+// applyEffect applies one round of an effect with the built-in scaling
+func (c *Character) applyEffect(effect StatusEffectData) {
+	// Process effect based on type
+	switch effect.Type {
+	case StatusAccelerate:
+		// Increase speed based on potency
+		speedIncrease := c.GetEffectScalingValue("speed", 100, effect.Potency, 1)
+		c.Speed = speedIncrease
+
+	case StatusBurning:
+		// Increasing damage over time
+		burnDamage := c.GetEffectScalingValue("health", 100, effect.Potency*effect.Duration, 10)
+		c.TakeDamage(burnDamage)
+
+	case StatusPoisoned:
+		// Decreasing damage over time
+		poisonDamage := c.GetEffectScalingValue("health", 100, effect.Potency, effect.Duration)
+		c.TakeDamage(poisonDamage)
+
+	case StatusEnraged:
+		// Increase attack based on potency
+		attackIncrease := c.GetEffectScalingValue("attack", 100, effect.Potency, 1)
+		c.Attack = attackIncrease
+
+	case StatusRegenerating:
+		// Heal based on potency
+		healAmount := c.GetEffectScalingValue("health", 100, effect.Potency, 10)
+		c.Health += healAmount
+	}
+}
+
+// applyEffectFormula applies one round of an effect whose amount comes from
+// its formula. Damage ignores Defense, like an ability formula's.
+func (c *Character) applyEffectFormula(effect StatusEffectData) {
+	n := amount(effect.Formula.Eval(effectEnv(c, effect)))
+	switch effect.Type {
+	case StatusAccelerate:
+		c.Speed += n
+	case StatusBurning, StatusPoisoned:
+		c.Health = max(c.Health-n, 0)
+	case StatusEnraged:
+		c.Attack += n
+	case StatusRegenerating:
+		c.Health += n
+	}
+}
+
 // Get Effect Value
 func (c *Character) GetEffectScalingValue(statName string, scalar int, potency int, divisor int) int {
 	// Get base stat value based on statName
@@ -152,6 +312,5 @@ func (c *Character) GetEffectScalingValue(statName string, scalar int, potency i
 	// Round down and apply divisor
 	newStat := int(modifiedStat / divisor)
 
-	fmt.Printf("newStat: %d\n", newStat)
 	return newStat
 }
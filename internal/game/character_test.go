@@ -1,6 +1,10 @@
 package game
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/MaterDev/golang_turnbased_game_spike/internal/formula"
+)
 
 func TestCharacter_IsValid(t *testing.T) {
 	tests := []struct {
@@ -338,3 +342,134 @@ func TestCharacter_GetEffectScalingValue(t *testing.T) {
 		})
 	}
 }
+
+func TestCharacter_UseAbility_Resistance(t *testing.T) {
+	burn := StatusEffectData{Type: StatusBurning, Duration: 2, Potency: 10}
+	tests := []struct {
+		name        string
+		resistance  int
+		wantEffect  bool
+		wantPotency int
+	}{
+		{"no resistance", 0, true, 10},
+		{"partial resistance", 40, true, 6},
+		{"immune", 100, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attacker := Character{
+				Name:      "Attacker",
+				Attack:    10,
+				Abilities: []Ability{{Name: "Fireball", Damage: 5, StatusEffect: burn}},
+			}
+			target := Character{
+				Name:        "Target",
+				Health:      100,
+				Resistances: map[StatusEffect]int{StatusBurning: tt.resistance},
+			}
+
+			if result := attacker.UseAbility(0, &target); !result.Success {
+				t.Fatalf("UseAbility() failed: %s", result.Message)
+			}
+			if got := len(target.StatusEffects) == 1; got != tt.wantEffect {
+				t.Fatalf("Expected effect applied = %v, got %+v", tt.wantEffect, target.StatusEffects)
+			}
+			if tt.wantEffect && target.StatusEffects[0].Potency != tt.wantPotency {
+				t.Errorf("Expected potency %d, got %d", tt.wantPotency, target.StatusEffects[0].Potency)
+			}
+		})
+	}
+}
+
+func TestCharacter_Formulas(t *testing.T) {
+	smash, err := formula.Parse("caster.attack * 1.5 + target.maxHealth * 0.05 - target.defense")
+	if err != nil {
+		t.Fatal(err)
+	}
+	attacker := Character{Name: "Attacker", Attack: 20, Abilities: []Ability{{Name: "Smash", Damage: 99, Formula: smash}}}
+	target := Character{Name: "Target", Health: 100, MaxHealth: 200, Defense: 5}
+
+	result := attacker.UseAbility(0, &target)
+	if !result.Success || result.Damage != 35 || target.Health != 65 {
+		t.Errorf("Expected formula damage of 35, got %+v and health %d", result, target.Health)
+	}
+
+	// Effects read the character they are on
+	drain, err := formula.Parse("target.health * effect.potency / 100")
+	if err != nil {
+		t.Fatal(err)
+	}
+	target.StatusEffects = []StatusEffectData{{Type: StatusPoisoned, Duration: 2, Potency: 20, Formula: drain}}
+	target.ProcessStatusEffect()
+	if target.Health != 52 {
+		t.Errorf("Expected poison formula to take 13 health ignoring defense, got health %d", target.Health)
+	}
+
+	// Negative results do nothing rather than heal
+	weak, _ := formula.Parse("0 - 10")
+	attacker.Abilities[0].Formula = weak
+	attacker.Abilities[0].Cooldown = 0
+	if result := attacker.UseAbility(0, &target); result.Damage != 0 || target.Health != 52 {
+		t.Errorf("Expected no damage from a negative formula, got %+v and health %d", result, target.Health)
+	}
+}
+
+func TestCharacter_UseAbility_Immunity(t *testing.T) {
+	stun := StatusEffectData{Type: StatusStunned, Duration: 4}
+	tests := []struct {
+		name         string
+		target       Character
+		wantOutcome  EffectOutcome
+		wantDuration int
+	}{
+		{"applied", Character{}, EffectApplied, 4},
+		{"resisted", Character{Resistances: map[StatusEffect]int{StatusStunned: 50}}, EffectResisted, 2},
+		{"resisted to a single tick", Character{Resistances: map[StatusEffect]int{StatusStunned: 90}}, EffectResisted, 1},
+		{"immune", Character{Immunities: []StatusEffect{StatusStunned}}, EffectImmune, 0},
+		{"temporarily immune", Character{TemporaryImmunities: map[StatusEffect]int{StatusStunned: 1}}, EffectImmune, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attacker := Character{Name: "Attacker", Abilities: []Ability{{Name: "Daze", StatusEffect: stun}}}
+			target := tt.target
+			target.Name, target.Health = "Target", 100
+
+			result := attacker.UseAbility(0, &target)
+			if result.EffectOutcome != tt.wantOutcome {
+				t.Errorf("EffectOutcome = %s, want %s", result.EffectOutcome, tt.wantOutcome)
+			}
+			if tt.wantDuration == 0 {
+				if result.AppliedEffect != nil || len(target.StatusEffects) != 0 {
+					t.Errorf("Expected no effect to land, got %+v", target.StatusEffects)
+				}
+				return
+			}
+			if result.AppliedEffect == nil || result.AppliedEffect.Duration != tt.wantDuration || target.StatusEffects[0].Duration != tt.wantDuration {
+				t.Errorf("Expected a stun of %d ticks, got %+v", tt.wantDuration, result.AppliedEffect)
+			}
+		})
+	}
+}
+
+func TestCharacter_CrowdControlDiminishingReturns(t *testing.T) {
+	c := Character{Name: "Target", Health: 100, StatusEffects: []StatusEffectData{{Type: StatusStunned, Duration: 1}}}
+	c.ProcessStatusEffect()
+	if len(c.StatusEffects) != 0 || c.TemporaryImmunities[StatusStunned] != crowdControlImmunity {
+		t.Fatalf("Expected the expired stun to leave an immunity, got %+v", c.TemporaryImmunities)
+	}
+	if _, outcome := c.resist(StatusEffectData{Type: StatusStunned, Duration: 2}); outcome != EffectImmune {
+		t.Errorf("Expected a fresh stun to be shrugged off, got %s", outcome)
+	}
+
+	for range crowdControlImmunity {
+		c.ProcessStatusEffect()
+	}
+	if len(c.TemporaryImmunities) != 0 {
+		t.Errorf("Expected the immunity to wear off, got %+v", c.TemporaryImmunities)
+	}
+	if _, outcome := c.resist(StatusEffectData{Type: StatusStunned, Duration: 2}); outcome != EffectApplied {
+		t.Errorf("Expected a stun to land again, got %s", outcome)
+	}
+}
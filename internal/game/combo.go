@@ -0,0 +1,119 @@
+package game
+
+// MaxComboLength is the most actions a combo may look back on
+const MaxComboLength = 3
+
+// Combo makes an ability play off the actions before it. Every condition set
+// must hold for the combo to land.
+type Combo struct {
+	// TargetHas is a status effect the target must be suffering
+	TargetHas StatusEffect `json:"TargetHas,omitempty"`
+	// After lists abilities, by name, that the caster's latest actions must
+	// have used, oldest first
+	After []string `json:"After,omitempty"`
+	// AllyBefore requires the action just before to have been taken by an
+	// ally, another character on the caster's side
+	AllyBefore bool `json:"AllyBefore,omitempty"`
+	// Bonus is extra damage in percent when the combo lands
+	Bonus int `json:"Bonus"`
+	// Finisher makes the ability unusable unless the combo lands
+	Finisher bool `json:"Finisher,omitempty"`
+}
+
+// recentActions returns up to n of c's latest actions, oldest first
+func (b *Battle) recentActions(c *Character, n int) []ActionRecord {
+	var recent []ActionRecord
+	for i := len(b.history) - 1; i >= 0 && len(recent) < n; i-- {
+		if b.history[i].CharacterID == c.ID {
+			recent = append(recent, b.history[i])
+		}
+	}
+	for i, j := 0, len(recent)-1; i < j; i, j = i+1, j-1 {
+		recent[i], recent[j] = recent[j], recent[i]
+	}
+	return recent
+}
+
+// sideOf returns 1 or 2 for the side the character with the given ID fights
+// on, in front, in reserve or as a minion, and 0 if it is on neither
+func (b *Battle) sideOf(id string) int {
+	for _, m := range b.Minions {
+		if m.Character.ID == id {
+			return m.Side
+		}
+	}
+	for side, team := range [][]*Character{
+		append([]*Character{b.Character1}, b.Reserves1...),
+		append([]*Character{b.Character2}, b.Reserves2...),
+	} {
+		for _, c := range team {
+			if c.ID == id {
+				return side + 1
+			}
+		}
+	}
+	return 0
+}
+
+// comboLands reports whether ability has a combo and every condition of it
+// holds for actor using it on target
+func (b *Battle) comboLands(actor, target *Character, ability Ability) bool {
+	combo := ability.Combo
+	if combo == nil {
+		return false
+	}
+	if combo.TargetHas != "" && !target.suffers(combo.TargetHas) {
+		return false
+	}
+	if len(combo.After) > 0 {
+		recent := b.recentActions(actor, len(combo.After))
+		if len(recent) < len(combo.After) {
+			return false
+		}
+		for i, name := range combo.After {
+			if recent[i].Kind != ActionAbility || recent[i].Ability != name {
+				return false
+			}
+		}
+	}
+	if combo.AllyBefore {
+		if len(b.history) == 0 {
+			return false
+		}
+		last := b.history[len(b.history)-1]
+		if last.CharacterID == actor.ID || b.sideOf(last.CharacterID) != b.sideOf(actor.ID) {
+			return false
+		}
+	}
+	return true
+}
+
+// unlocked reports whether actor may use ability on target, which only a
+// finisher whose combo would not land may not
+func (b *Battle) unlocked(actor, target *Character, ability Ability) bool {
+	return ability.Combo == nil || !ability.Combo.Finisher || b.comboLands(actor, target, ability)
+}
+
+// unlocked reports whether view.Self may use ability i on target, combo and
+// grid permitting. Hand-made views have no history or grid, so every ability
+// is unlocked in them.
+func (v BattleView) unlocked(i int, target Character) bool {
+	if v.sim == nil {
+		return true
+	}
+	actor, t := v.sim.characterByID(v.Self.ID), v.sim.characterByID(target.ID)
+	if actor == nil || t == nil {
+		return true
+	}
+	return v.sim.unlocked(actor, t, actor.Abilities[i]) && v.sim.outOfReach(actor, t, actor.Abilities[i]) == ""
+}
+
+// suffers reports whether c is under an effect of the given type
+func (c *Character) suffers(effect StatusEffect) bool {
+	for _, e := range c.StatusEffects {
+		if e.Type == effect && e.Duration > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package game
+
+import "testing"
+
+func TestBattle_ComboTargetHas(t *testing.T) {
+	followUp := Ability{Name: "Follow-up", Damage: 10, Combo: &Combo{TargetHas: StatusBurning, Bonus: 50}}
+	tests := []struct {
+		name       string
+		effects    []StatusEffectData
+		wantDamage int
+		wantCombo  bool
+	}{
+		{"not burning", nil, 20, false},
+		{"burning", []StatusEffectData{{Type: StatusBurning, Duration: 3, Potency: 1}}, 30, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			char1 := createTestCharacter("Warrior", 100)
+			char1.Abilities = append(char1.Abilities, followUp)
+			char2 := createTestCharacter("Mage", 100)
+			char2.StatusEffects = tt.effects
+			battle := newBattle(char1, char2)
+			activate(battle)
+
+			result := battle.processAction(BattleAction{CharacterID: char1.ID, AbilityIndex: 2, TargetID: char2.ID})
+			if !result.Success {
+				t.Fatalf("Action failed: %s", result.Message)
+			}
+			if result.Ability.Damage != tt.wantDamage || result.Ability.Combo != tt.wantCombo {
+				t.Errorf("Damage = %d, combo %v, want %d, %v", result.Ability.Damage, result.Ability.Combo, tt.wantDamage, tt.wantCombo)
+			}
+		})
+	}
+}
+
+func TestBattle_ComboFinisher(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 1000)
+	char1.Abilities = append(char1.Abilities, Ability{
+		Name:   "Rampage",
+		Damage: 30,
+		Combo:  &Combo{After: []string{"Basic Attack", "Basic Attack"}, Finisher: true},
+	})
+	char2 := createTestCharacter("Mage", 1000)
+	battle := newBattle(char1, char2)
+	activate(battle)
+
+	basic := BattleAction{CharacterID: char1.ID, AbilityIndex: 0, TargetID: char2.ID}
+	finisher := BattleAction{CharacterID: char1.ID, AbilityIndex: 2, TargetID: char2.ID}
+	defend := BattleAction{Kind: ActionDefend, CharacterID: char1.ID}
+	steps := []struct {
+		action      BattleAction
+		wantSuccess bool
+	}{
+		{finisher, false},
+		{basic, true},
+		{defend, true},
+		{basic, true},
+		{finisher, false}, // Defending broke the chain
+		{basic, true},
+		{BattleAction{CharacterID: char2.ID, AbilityIndex: 0, TargetID: char1.ID}, true},
+		{finisher, true}, // The opponent acting in between does not
+		{finisher, false},
+	}
+	for i, step := range steps {
+		if result := battle.processAction(step.action); result.Success != step.wantSuccess {
+			t.Fatalf("Step %d: success = %v, want %v (%s)", i, result.Success, step.wantSuccess, result.Message)
+		}
+	}
+}
+
+func TestBattle_ComboAllyBefore(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	reserve := createTestCharacter("Rogue", 100)
+	reserve.Abilities = append(reserve.Abilities, Ability{Name: "Pincer", Damage: 10, Combo: &Combo{AllyBefore: true, Bonus: 100}})
+	char2 := createTestCharacter("Mage", 100)
+	battle := newBattle(char1, char2, WithReserves(1, reserve))
+	activate(battle)
+
+	battle.processAction(BattleAction{Kind: ActionSwap, CharacterID: char1.ID, TargetID: reserve.ID})
+	result := battle.processAction(BattleAction{CharacterID: reserve.ID, AbilityIndex: 2, TargetID: char2.ID})
+	if !result.Ability.Combo || result.Ability.Damage != 40 {
+		t.Errorf("Pincer after an ally = %+v, want a combo for 40", result.Ability)
+	}
+
+	battle.processAction(BattleAction{CharacterID: char2.ID, AbilityIndex: 0, TargetID: reserve.ID})
+	reserve.Abilities[2].Cooldown = 0
+	result = battle.processAction(BattleAction{CharacterID: reserve.ID, AbilityIndex: 2, TargetID: char2.ID})
+	if result.Ability.Combo {
+		t.Error("Expected no combo after an opponent's action")
+	}
+}
+
+func TestBattle_LegalActionsSkipLockedFinishers(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char1.Speed = 20
+	char1.Abilities = append(char1.Abilities, Ability{Name: "Rampage", Damage: 100, Combo: &Combo{After: []string{"Basic Attack"}, Finisher: true}})
+	char2 := createTestCharacter("Mage", 100)
+	battle := newBattle(char1, char2)
+	activate(battle)
+
+	for _, action := range battle.legalActions() {
+		if action.Kind == "" && action.AbilityIndex == 2 {
+			t.Fatalf("legalActions() offers a locked finisher: %+v", action)
+		}
+	}
+	action, ok := GreedyStrategy{}.ChooseAction(battle.viewFor(char1))
+	if !ok || action.AbilityIndex == 2 {
+		t.Errorf("GreedyStrategy chose %+v, %v, want anything but the locked finisher", action, ok)
+	}
+}
@@ -0,0 +1,149 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ItemTarget restricts who a consumable may be used on
+type ItemTarget string
+
+const (
+	ItemTargetSelf  ItemTarget = "SELF"
+	ItemTargetEnemy ItemTarget = "ENEMY"
+	ItemTargetAny   ItemTarget = "ANY"
+)
+
+// IsValid reports whether the targeting rule is one the engine knows
+func (t ItemTarget) IsValid() bool {
+	switch t {
+	case ItemTargetSelf, ItemTargetEnemy, ItemTargetAny:
+		return true
+	}
+	return false
+}
+
+// Consumable is a stack of single-use items a character carries into a
+// battle, such as potions, antidotes or bombs. Used up stacks stay in the
+// inventory with a Quantity of zero so that indexes remain stable.
+type Consumable struct {
+	Name     string     `json:"Name"`
+	Quantity int        `json:"Quantity"`
+	Target   ItemTarget `json:"Target"`
+	Heal     int        `json:"Heal"`
+	// Damage is dealt outright; a bomb's blast ignores Defense
+	Damage       int              `json:"Damage"`
+	Cures        []StatusEffect   `json:"Cures,omitempty"`
+	StatusEffect StatusEffectData `json:"StatusEffect"`
+}
+
+// ItemResult contains the result of using a consumable
+type ItemResult struct {
+	Success      bool              `json:"Success"`
+	Healed       int               `json:"Healed"`
+	Damage       int               `json:"Damage"`
+	Cured        []StatusEffect    `json:"Cured,omitempty"`
+	StatusEffect *StatusEffectData `json:"StatusEffect,omitempty"`
+	// EffectOutcome says whether the item's status effect landed in full,
+	// weakened or not at all
+	EffectOutcome EffectOutcome `json:"EffectOutcome,omitempty"`
+	Message       string        `json:"Message"`
+}
+
+// UseItem uses one of the consumable at itemIndex in the character's
+// inventory on target
+func (c *Character) UseItem(itemIndex int, target *Character) ItemResult {
+	if itemIndex < 0 || itemIndex >= len(c.Inventory) {
+		return ItemResult{
+			Success: false,
+			Message: "Invalid item index.",
+		}
+	}
+	item := &c.Inventory[itemIndex]
+	if item.Quantity <= 0 {
+		return ItemResult{
+			Success: false,
+			Message: fmt.Sprintf("No %s left", item.Name),
+		}
+	}
+	switch {
+	case item.Target == ItemTargetSelf && target != c:
+		return ItemResult{
+			Success: false,
+			Message: fmt.Sprintf("%s can only be used on yourself", item.Name),
+		}
+	case item.Target == ItemTargetEnemy && target == c:
+		return ItemResult{
+			Success: false,
+			Message: fmt.Sprintf("%s can only be used on an enemy", item.Name),
+		}
+	}
+
+	item.Quantity--
+	result := ItemResult{Success: true}
+
+	if item.Damage > 0 {
+		before := target.Health
+		target.Health = max(target.Health-item.Damage, 0)
+		result.Damage = before - target.Health
+	}
+
+	if item.Heal > 0 && target.Health > 0 {
+		before := target.Health
+		target.Health = min(target.Health+item.Heal, max(target.MaxHealth, target.Health))
+		result.Healed = target.Health - before
+	}
+
+	if len(item.Cures) > 0 {
+		remaining := make([]StatusEffectData, 0, len(target.StatusEffects))
+		for _, effect := range target.StatusEffects {
+			if cures(item.Cures, effect.Type) {
+				result.Cured = append(result.Cured, effect.Type)
+				continue
+			}
+			remaining = append(remaining, effect)
+		}
+		target.StatusEffects = remaining
+	}
+
+	if item.StatusEffect.Type != "" {
+		effect, outcome := target.resist(item.StatusEffect)
+		result.EffectOutcome = outcome
+		if outcome != EffectImmune {
+			target.StatusEffects = append(target.StatusEffects, effect)
+			result.StatusEffect = &effect
+		}
+	}
+
+	result.Message = describeItemUse(item.Name, result)
+	return result
+}
+
+func cures(list []StatusEffect, effect StatusEffect) bool {
+	for _, cured := range list {
+		if cured == effect {
+			return true
+		}
+	}
+	return false
+}
+
+func describeItemUse(name string, result ItemResult) string {
+	var parts []string
+	if result.Damage > 0 {
+		parts = append(parts, fmt.Sprintf("%d damage", result.Damage))
+	}
+	if result.Healed > 0 {
+		parts = append(parts, fmt.Sprintf("%d healed", result.Healed))
+	}
+	for _, cured := range result.Cured {
+		parts = append(parts, fmt.Sprintf("cured %s", cured))
+	}
+	if result.StatusEffect != nil {
+		parts = append(parts, fmt.Sprintf("applied %s", result.StatusEffect.Type))
+	}
+	if len(parts) == 0 {
+		return fmt.Sprintf("Used %s", name)
+	}
+	return fmt.Sprintf("Used %s: %s", name, strings.Join(parts, ", "))
+}
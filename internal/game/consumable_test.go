@@ -0,0 +1,117 @@
+package game
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCharacter_UseItem(t *testing.T) {
+	potion := Consumable{Name: "Potion", Quantity: 1, Target: ItemTargetSelf, Heal: 25}
+	antidote := Consumable{Name: "Antidote", Quantity: 2, Target: ItemTargetSelf, Cures: []StatusEffect{StatusPoisoned}}
+	bomb := Consumable{Name: "Bomb", Quantity: 1, Target: ItemTargetEnemy, Damage: 20}
+
+	tests := []struct {
+		name        string
+		item        Consumable
+		onSelf      bool
+		wantSuccess bool
+		wantHealth  int // of the target
+		wantEffects int // left on the target
+	}{
+		{"potion heals self", potion, true, true, 75, 2},
+		{"potion refuses an enemy", potion, false, false, 50, 2},
+		{"antidote cures only poison", antidote, true, true, 50, 1},
+		{"bomb ignores defense", bomb, false, true, 30, 2},
+		{"bomb refuses self", bomb, true, false, 50, 2},
+		{"empty stack", Consumable{Name: "Potion", Target: ItemTargetAny, Heal: 25}, true, false, 50, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			effects := []StatusEffectData{
+				{Type: StatusPoisoned, Duration: 2, Potency: 10},
+				{Type: StatusBurning, Duration: 2, Potency: 10},
+			}
+			user := Character{Name: "User", Health: 50, MaxHealth: 100, Defense: 50, Inventory: []Consumable{tt.item}, StatusEffects: effects}
+			enemy := Character{Name: "Enemy", Health: 50, MaxHealth: 100, Defense: 50, StatusEffects: append([]StatusEffectData(nil), effects...)}
+			target := &enemy
+			if tt.onSelf {
+				target = &user
+			}
+
+			result := user.UseItem(0, target)
+			if result.Success != tt.wantSuccess {
+				t.Fatalf("UseItem() success = %v, want %v (%s)", result.Success, tt.wantSuccess, result.Message)
+			}
+			if target.Health != tt.wantHealth {
+				t.Errorf("Target health = %d, want %d", target.Health, tt.wantHealth)
+			}
+			if len(target.StatusEffects) != tt.wantEffects {
+				t.Errorf("Target has %d effects, want %d", len(target.StatusEffects), tt.wantEffects)
+			}
+			if tt.wantSuccess && user.Inventory[0].Quantity != tt.item.Quantity-1 {
+				t.Errorf("Quantity = %d, want %d", user.Inventory[0].Quantity, tt.item.Quantity-1)
+			}
+		})
+	}
+
+	user := Character{Name: "User", Health: 50}
+	if result := user.UseItem(0, &user); result.Success {
+		t.Error("Expected failure for an empty inventory")
+	}
+}
+
+func TestCharacter_UseItemHealCap(t *testing.T) {
+	tests := []struct {
+		name       string
+		health     int
+		wantHealth int
+		wantHealed int
+	}{
+		{"at full health", 100, 100, 0},
+		{"nearly full", 90, 100, 10},
+		{"well down", 50, 75, 25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := Character{Name: "User", Health: tt.health, MaxHealth: 100, Inventory: []Consumable{
+				{Name: "Potion", Quantity: 1, Target: ItemTargetSelf, Heal: 25},
+			}}
+			result := user.UseItem(0, &user)
+			if !result.Success || user.Health != tt.wantHealth || result.Healed != tt.wantHealed {
+				t.Errorf("Health = %d, Healed = %d, want %d and %d", user.Health, result.Healed, tt.wantHealth, tt.wantHealed)
+			}
+		})
+	}
+}
+
+func TestBattle_ItemAction(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char1.Inventory = []Consumable{{Name: "Bomb", Quantity: 1, Target: ItemTargetEnemy, Damage: 30}}
+	char2 := createTestCharacter("Mage", 80)
+
+	battle := newBattle(char1, char2)
+	if err := battle.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start battle: %v", err)
+	}
+	defer battle.Stop()
+
+	action := BattleAction{Kind: ActionItem, CharacterID: char1.ID, ItemIndex: 0, TargetID: char2.ID}
+	result, err := battle.SubmitAction(context.Background(), action)
+	if err != nil || !result.Success {
+		t.Fatalf("SubmitAction() = %+v, %v", result, err)
+	}
+	if result.Item == nil || result.Item.Damage != 30 {
+		t.Errorf("Expected the item result to report 30 damage, got %+v", result.Item)
+	}
+
+	result, _ = battle.SubmitAction(context.Background(), action)
+	if result.Success {
+		t.Error("Expected a used up item to be rejected")
+	}
+
+	history := battle.History()
+	if len(history) != 1 || history[0].Kind != ActionItem || history[0].Damage != 30 {
+		t.Errorf("History() = %+v, want one item use", history)
+	}
+}
@@ -0,0 +1,113 @@
+package game
+
+import "fmt"
+
+// Weather is a condition that holds across the whole battlefield
+type Weather string
+
+const (
+	// WeatherHeatwave makes fire abilities, those that inflict
+	// StatusBurning, hit harder and their burns last longer
+	WeatherHeatwave Weather = "HEATWAVE"
+	// WeatherFog makes abilities aimed at somebody else miss now and then
+	WeatherFog Weather = "FOG"
+	// WeatherSanctuary heals every standing character at the end of each
+	// round
+	WeatherSanctuary Weather = "SANCTUARY"
+)
+
+// KnownWeather lists every weather the engine knows how to apply
+var KnownWeather = []Weather{WeatherHeatwave, WeatherFog, WeatherSanctuary}
+
+// IsKnown reports whether the engine knows how to apply the weather
+func (w Weather) IsKnown() bool {
+	for _, known := range KnownWeather {
+		if w == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Weather strengths
+const (
+	// heatwaveDamageBonus is the extra damage of fire abilities, in percent
+	heatwaveDamageBonus = 50
+	// heatwaveBurnRounds is how much longer burns last
+	heatwaveBurnRounds = 1
+	// fogMissChance is the chance of missing, in percent
+	fogMissChance = 25
+	// sanctuaryHealPercent is the share of MaxHealth healed each round
+	sanctuaryHealPercent = 5
+)
+
+// Environment is the weather over a battle
+type Environment struct {
+	Weather Weather `json:"Weather"`
+	// Duration is the number of rounds left, counting the current one. Zero
+	// lasts the whole battle.
+	Duration int `json:"Duration"`
+}
+
+// WithEnvironment starts the battle under the given weather for duration
+// rounds, or for the whole battle if duration is zero
+func WithEnvironment(weather Weather, duration int) BattleOption {
+	return func(b *Battle) {
+		b.Environment = &Environment{Weather: weather, Duration: duration}
+	}
+}
+
+// weatherIs reports whether the given weather holds
+func (b *Battle) weatherIs(weather Weather) bool {
+	return b.Environment != nil && b.Environment.Weather == weather
+}
+
+// hitFor returns how the weather and rules shape a use of ability
+func (b *Battle) hitFor(ability Ability) hit {
+	h := hit{model: b.Rules.DamageModel}
+	if b.weatherIs(WeatherHeatwave) && ability.StatusEffect.Type == StatusBurning {
+		h.bonus = heatwaveDamageBonus
+		h.extend = heatwaveBurnRounds
+	}
+	return h
+}
+
+// misses rolls whether an ability aimed at target goes astray in fog
+func (b *Battle) misses(actor, target *Character) bool {
+	return b.weatherIs(WeatherFog) && target != actor && b.roll(100) < fogMissChance
+}
+
+// changeWeather applies an ability's effect on the weather and describes it
+func (b *Battle) changeWeather(ability Ability) string {
+	switch {
+	case ability.SetsWeather != "":
+		b.Environment = &Environment{Weather: ability.SetsWeather, Duration: ability.WeatherDuration}
+		return fmt.Sprintf("; the weather turns to %s", ability.SetsWeather)
+	case ability.ClearsWeather && b.Environment != nil:
+		b.Environment = nil
+		return "; the weather clears"
+	}
+	return ""
+}
+
+// passWeather ends a round under the current weather, healing everybody in
+// a sanctuary and clearing weather that has run its course
+func (b *Battle) passWeather() {
+	if b.Environment == nil {
+		return
+	}
+	if b.Environment.Weather == WeatherSanctuary {
+		for _, c := range b.combatants() {
+			if c.Health > 0 {
+				heal := max(c.MaxHealth*sanctuaryHealPercent/100, 1)
+				c.Health = min(c.Health+heal, max(c.MaxHealth, c.Health))
+			}
+		}
+	}
+	if b.Environment.Duration > 0 {
+		b.Environment.Duration--
+		if b.Environment.Duration == 0 {
+			b.Environment = nil
+		}
+	}
+}
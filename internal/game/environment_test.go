@@ -0,0 +1,116 @@
+package game
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+func TestBattle_Heatwave(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         []BattleOption
+		wantDamage   int
+		wantDuration int
+	}{
+		{"clear", nil, 30, 1},
+		{"heatwave", []BattleOption{WithEnvironment(WeatherHeatwave, 0)}, 45, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			char1 := createTestCharacter("Warrior", 100)
+			char2 := createTestCharacter("Mage", 100)
+			battle := newBattle(char1, char2, tt.opts...)
+			activate(battle)
+
+			result := battle.processAction(BattleAction{CharacterID: char1.ID, AbilityIndex: 1, TargetID: char2.ID})
+			if !result.Success {
+				t.Fatalf("Action failed: %s", result.Message)
+			}
+			if result.Ability.Damage != tt.wantDamage {
+				t.Errorf("Damage = %d, want %d", result.Ability.Damage, tt.wantDamage)
+			}
+			if got := char2.StatusEffects[0].Duration; got != tt.wantDuration {
+				t.Errorf("Burn duration after one action = %d, want %d", got, tt.wantDuration)
+			}
+		})
+	}
+
+	// Only fire abilities are stoked
+	char1 := createTestCharacter("Warrior", 100)
+	char2 := createTestCharacter("Mage", 100)
+	battle := newBattle(char1, char2, WithEnvironment(WeatherHeatwave, 0))
+	activate(battle)
+	result := battle.processAction(BattleAction{CharacterID: char1.ID, AbilityIndex: 0, TargetID: char2.ID})
+	if result.Ability.Damage != 20 {
+		t.Errorf("Basic Attack damage in a heatwave = %d, want 20", result.Ability.Damage)
+	}
+}
+
+func TestBattle_Fog(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100000)
+	char2 := createTestCharacter("Mage", 100000)
+	battle := newBattle(char1, char2, WithEnvironment(WeatherFog, 0), WithRand(rand.New(rand.NewPCG(1, 2))))
+	activate(battle)
+
+	misses := 0
+	for range 200 {
+		before := char2.Health
+		result := battle.processAction(BattleAction{CharacterID: char1.ID, AbilityIndex: 0, TargetID: char2.ID})
+		if !result.Success {
+			t.Fatalf("Action failed: %s", result.Message)
+		}
+		if result.Ability.Missed {
+			misses++
+			if char2.Health != before {
+				t.Fatal("A miss should deal no damage")
+			}
+		}
+	}
+	if misses < 25 || misses > 75 {
+		t.Errorf("Missed %d of 200 attacks, want about a quarter", misses)
+	}
+}
+
+func TestBattle_Sanctuary(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 50)
+	char1.MaxHealth = 100
+	char2 := createTestCharacter("Mage", 98)
+	char2.MaxHealth = 100
+	battle := newBattle(char1, char2, WithEnvironment(WeatherSanctuary, 2))
+	activate(battle)
+
+	battle.nextRound()
+	if char1.Health != 55 || char2.Health != 100 {
+		t.Errorf("Health after one round = %d and %d, want 55 and 100", char1.Health, char2.Health)
+	}
+	if battle.Environment == nil || battle.Environment.Duration != 1 {
+		t.Fatalf("Environment after one round = %+v, want one round left", battle.Environment)
+	}
+
+	battle.nextRound()
+	if char1.Health != 60 {
+		t.Errorf("Health after two rounds = %d, want 60", char1.Health)
+	}
+	if battle.Environment != nil {
+		t.Errorf("Expected the sanctuary to have passed, got %+v", battle.Environment)
+	}
+}
+
+func TestBattle_AbilityWeather(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char1.Abilities = append(char1.Abilities, Ability{Name: "Heat Wave", SetsWeather: WeatherHeatwave, WeatherDuration: 3})
+	char2 := createTestCharacter("Mage", 100)
+	char2.Abilities = append(char2.Abilities, Ability{Name: "Clear Skies", ClearsWeather: true})
+	battle := newBattle(char1, char2)
+	activate(battle)
+
+	battle.processAction(BattleAction{CharacterID: char1.ID, AbilityIndex: 2, TargetID: char2.ID})
+	if got := battle.Environment; got == nil || *got != (Environment{Weather: WeatherHeatwave, Duration: 3}) {
+		t.Fatalf("Environment = %+v, want a heatwave for 3 rounds", got)
+	}
+
+	battle.processAction(BattleAction{CharacterID: char2.ID, AbilityIndex: 2, TargetID: char1.ID})
+	if battle.Environment != nil {
+		t.Errorf("Expected the weather to clear, got %+v", battle.Environment)
+	}
+}
@@ -0,0 +1,77 @@
+package game
+
+import (
+	"math"
+	"strings"
+
+	"github.com/MaterDev/golang_turnbased_game_spike/internal/formula"
+)
+
+// AbilityFormulaVars are the variables an ability's damage formula may read
+var AbilityFormulaVars = []string{
+	"caster.health", "caster.maxHealth", "caster.attack", "caster.defense", "caster.speed",
+	"target.health", "target.maxHealth", "target.attack", "target.defense", "target.speed",
+	"ability.damage",
+}
+
+// EffectFormulaVars are the variables a status effect's formula may read.
+// The target is the character the effect is on.
+var EffectFormulaVars = []string{
+	"target.health", "target.maxHealth", "target.attack", "target.defense", "target.speed",
+	"effect.potency", "effect.duration",
+}
+
+// abilityEnv exposes the caster, target and ability to a damage formula
+func abilityEnv(caster, target *Character, ability *Ability) formula.Env {
+	return func(name string) float64 {
+		switch {
+		case strings.HasPrefix(name, "caster."):
+			return caster.stat(strings.TrimPrefix(name, "caster."))
+		case strings.HasPrefix(name, "target."):
+			return target.stat(strings.TrimPrefix(name, "target."))
+		case name == "ability.damage":
+			return float64(ability.Damage)
+		}
+		return 0
+	}
+}
+
+// effectEnv exposes the affected character and the effect to its formula
+func effectEnv(c *Character, effect StatusEffectData) formula.Env {
+	return func(name string) float64 {
+		switch {
+		case strings.HasPrefix(name, "target."):
+			return c.stat(strings.TrimPrefix(name, "target."))
+		case name == "effect.potency":
+			return float64(effect.Potency)
+		case name == "effect.duration":
+			return float64(effect.Duration)
+		}
+		return 0
+	}
+}
+
+// stat returns a stat by its formula name
+func (c *Character) stat(name string) float64 {
+	switch name {
+	case "health":
+		return float64(c.Health)
+	case "maxHealth":
+		if c.MaxHealth > 0 {
+			return float64(c.MaxHealth)
+		}
+		return float64(c.Health)
+	case "attack":
+		return float64(c.Attack)
+	case "defense":
+		return float64(c.Defense)
+	case "speed":
+		return float64(c.Speed)
+	}
+	return 0
+}
+
+// amount rounds a formula result down to a whole, non-negative amount
+func amount(v float64) int {
+	return int(math.Max(math.Floor(v), 0))
+}
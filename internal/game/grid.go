@@ -0,0 +1,401 @@
+package game
+
+import "fmt"
+
+// Terrain is what fills a cell of a grid, written as one character of a
+// row
+type Terrain byte
+
+const (
+	// TerrainOpen can be walked on and seen across
+	TerrainOpen Terrain = '.'
+	// TerrainWall blocks movement and line of sight
+	TerrainWall Terrain = '#'
+	// TerrainWater blocks movement but not line of sight
+	TerrainWater Terrain = '~'
+)
+
+// KnownTerrain lists every terrain a grid may hold
+var KnownTerrain = []Terrain{TerrainOpen, TerrainWall, TerrainWater}
+
+// IsKnown reports whether the engine knows the terrain
+func (t Terrain) IsKnown() bool {
+	for _, known := range KnownTerrain {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultMovement is how many cells a character without a Movement stat may
+// move in one action
+const DefaultMovement = 3
+
+// ActionMove moves the actor to the cell To on the battle's grid. Moving
+// takes the actor's turn.
+const ActionMove ActionKind = "MOVE"
+
+// Position is a cell of a grid, counted from the top left
+type Position struct {
+	X int `json:"X"`
+	Y int `json:"Y"`
+}
+
+// distance is the number of steps from p to q, moving in four directions
+func (p Position) distance(q Position) int {
+	return abs(p.X-q.X) + abs(p.Y-q.Y)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// Grid is the map of a tactical battle. Rows hold its terrain, one
+// character per cell, and Positions where each combatant stands by
+// character ID. Battles without a grid are duels in which everyone can
+// reach everyone.
+type Grid struct {
+	Rows      []string            `json:"Rows"`
+	Positions map[string]Position `json:"Positions"`
+}
+
+// Width is the number of cells in a row
+func (g *Grid) Width() int {
+	if len(g.Rows) == 0 {
+		return 0
+	}
+	return len(g.Rows[0])
+}
+
+// Height is the number of rows
+func (g *Grid) Height() int {
+	return len(g.Rows)
+}
+
+// TerrainAt returns the terrain at p. Cells off the map are walls.
+func (g *Grid) TerrainAt(p Position) Terrain {
+	if p.Y < 0 || p.Y >= len(g.Rows) || p.X < 0 || p.X >= len(g.Rows[p.Y]) {
+		return TerrainWall
+	}
+	return Terrain(g.Rows[p.Y][p.X])
+}
+
+// clone returns a copy of the grid whose positions can change on their own
+func (g *Grid) clone() *Grid {
+	positions := make(map[string]Position, len(g.Positions))
+	for id, p := range g.Positions {
+		positions[id] = p
+	}
+	return &Grid{Rows: g.Rows, Positions: positions}
+}
+
+// inSight reports whether nothing blocks the line of sight from p to q. The
+// line is traced cell by cell, and only the cells between the two count.
+func (g *Grid) inSight(p, q Position) bool {
+	dx, dy := abs(q.X-p.X), -abs(q.Y-p.Y)
+	sx, sy := 1, 1
+	if q.X < p.X {
+		sx = -1
+	}
+	if q.Y < p.Y {
+		sy = -1
+	}
+	err := dx + dy
+	for cell := p; cell != q; {
+		if cell != p && g.TerrainAt(cell) == TerrainWall {
+			return false
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			cell.X += sx
+		}
+		if e2 <= dx {
+			err += dx
+			cell.Y += sy
+		}
+	}
+	return true
+}
+
+// WithGrid fights the battle on a map with the given rows of terrain,
+// Character1 starting at start1 and Character2 at start2
+func WithGrid(rows []string, start1, start2 Position) BattleOption {
+	return func(b *Battle) {
+		b.Grid = &Grid{
+			Rows:      append([]string(nil), rows...),
+			Positions: map[string]Position{b.Character1.ID: start1, b.Character2.ID: start2},
+		}
+	}
+}
+
+// AreaShape is the shape of the cells an area ability hits
+type AreaShape string
+
+const (
+	// AreaLine hits a line Size cells long from the caster through the
+	// target
+	AreaLine AreaShape = "LINE"
+	// AreaCone hits a quarter circle Size cells deep spreading from the
+	// caster towards the target
+	AreaCone AreaShape = "CONE"
+	// AreaRadius hits every cell within Size steps of the target
+	AreaRadius AreaShape = "RADIUS"
+)
+
+// KnownAreaShapes lists every shape the engine knows how to apply
+var KnownAreaShapes = []AreaShape{AreaLine, AreaCone, AreaRadius}
+
+// IsKnown reports whether the engine knows the shape
+func (s AreaShape) IsKnown() bool {
+	for _, known := range KnownAreaShapes {
+		if s == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Area spreads an ability over the cells around its target on a grid
+type Area struct {
+	Shape AreaShape `json:"Shape"`
+	Size  int       `json:"Size"`
+}
+
+// covers reports whether the area of an ability cast from caster at target
+// takes in cell. The area stops at walls.
+func (a Area) covers(g *Grid, caster, target, cell Position) bool {
+	if a.Shape == AreaRadius {
+		return target.distance(cell) <= a.Size && g.inSight(target, cell)
+	}
+	d := Position{target.X - caster.X, target.Y - caster.Y}
+	r := Position{cell.X - caster.X, cell.Y - caster.Y}
+	dot := r.X*d.X + r.Y*d.Y
+	if d == (Position{}) || dot <= 0 || caster.distance(cell) > a.Size || !g.inSight(caster, cell) {
+		return false
+	}
+	dd, rr := d.X*d.X+d.Y*d.Y, r.X*r.X+r.Y*r.Y
+	switch a.Shape {
+	case AreaLine:
+		// Within half a cell of the line through the target
+		cross := r.X*d.Y - r.Y*d.X
+		return 4*cross*cross <= dd
+	case AreaCone:
+		// Within 45 degrees either side of the target
+		return 2*dot*dot >= rr*dd
+	}
+	return false
+}
+
+// MoveResult reports a Move action
+type MoveResult struct {
+	From     Position `json:"From"`
+	To       Position `json:"To"`
+	Distance int      `json:"Distance"`
+}
+
+// movement is how many cells c may move in one action
+func (c *Character) movement() int {
+	if c.Movement > 0 {
+		return c.Movement
+	}
+	return DefaultMovement
+}
+
+// reach of an ability: Range cells, or 1 for melee abilities without one
+func (a Ability) reach() int {
+	return max(a.Range, 1)
+}
+
+// occupant returns the combatant standing at p, or nil
+func (b *Battle) occupant(p Position) *Character {
+	for _, c := range b.combatants() {
+		if at, ok := b.Grid.Positions[c.ID]; ok && at == p {
+			return c
+		}
+	}
+	return nil
+}
+
+// steps maps every cell actor can walk to within limit steps to the steps
+// it takes, going round walls, water and other combatants
+func (b *Battle) steps(actor *Character, limit int) map[Position]int {
+	from := b.Grid.Positions[actor.ID]
+	steps := map[Position]int{from: 0}
+	frontier := []Position{from}
+	for len(frontier) > 0 {
+		p := frontier[0]
+		frontier = frontier[1:]
+		if steps[p] == limit {
+			continue
+		}
+		for _, next := range []Position{{p.X + 1, p.Y}, {p.X - 1, p.Y}, {p.X, p.Y + 1}, {p.X, p.Y - 1}} {
+			if _, seen := steps[next]; seen || b.Grid.TerrainAt(next) != TerrainOpen || b.occupant(next) != nil {
+				continue
+			}
+			steps[next] = steps[p] + 1
+			frontier = append(frontier, next)
+		}
+	}
+	return steps
+}
+
+// outOfReach explains why actor cannot use ability on target from where it
+// stands, or returns "" if it can. Without a grid everyone is in reach, and
+// summons ignore their target.
+func (b *Battle) outOfReach(actor, target *Character, ability Ability) string {
+	if b.Grid == nil || target == actor || ability.Summon != nil {
+		return ""
+	}
+	from, ok := b.Grid.Positions[actor.ID]
+	to, ok2 := b.Grid.Positions[target.ID]
+	if !ok || !ok2 {
+		return "not on the grid"
+	}
+	if d := from.distance(to); d > ability.reach() {
+		return fmt.Sprintf("%s is %d cells away and %s reaches %d", target.Name, d, ability.Name, ability.reach())
+	}
+	if !b.Grid.inSight(from, to) {
+		return fmt.Sprintf("%s cannot see %s", actor.Name, target.Name)
+	}
+	return ""
+}
+
+// caughtIn returns the combatants other than actor and target that an area
+// ability used on target also hits, friend or foe
+func (b *Battle) caughtIn(actor, target *Character, ability Ability) []*Character {
+	if b.Grid == nil || ability.Area == nil {
+		return nil
+	}
+	from, ok := b.Grid.Positions[actor.ID]
+	to, ok2 := b.Grid.Positions[target.ID]
+	if !ok || !ok2 {
+		return nil
+	}
+	var caught []*Character
+	for _, c := range b.combatants() {
+		at, ok := b.Grid.Positions[c.ID]
+		if c == actor || c == target || !ok || c.Health <= 0 {
+			continue
+		}
+		if ability.Area.covers(b.Grid, from, to, at) {
+			caught = append(caught, c)
+		}
+	}
+	return caught
+}
+
+// applyMove walks the actor to an open cell within its movement
+func (b *Battle) applyMove(actor *Character, to *Position) BattleActionResult {
+	if b.Grid == nil {
+		return b.reject("there is no grid to move on")
+	}
+	if to == nil {
+		return b.reject("a move needs a To position")
+	}
+	from := b.Grid.Positions[actor.ID]
+	if *to == from {
+		return b.reject(fmt.Sprintf("%s is already there", actor.Name))
+	}
+	if b.Grid.TerrainAt(*to) != TerrainOpen || b.occupant(*to) != nil {
+		return b.reject("cannot move onto that cell")
+	}
+	distance, ok := b.steps(actor, actor.movement())[*to]
+	if !ok {
+		return b.reject(fmt.Sprintf("%s cannot get there in %d steps", actor.Name, actor.movement()))
+	}
+	actor.lowerGuard()
+
+	b.Grid.Positions[actor.ID] = *to
+	b.history = append(b.history, ActionRecord{
+		Round:       b.Round,
+		Kind:        ActionMove,
+		CharacterID: actor.ID,
+	})
+	b.endTurn(actor, -1)
+
+	return BattleActionResult{
+		Success: true,
+		Message: fmt.Sprintf("%s moves %d cells", actor.Name, distance),
+		Battle:  b,
+		Move:    &MoveResult{From: from, To: *to, Distance: distance},
+	}
+}
+
+// advance moves a computer-controlled actor with nothing in reach along the
+// shortest way to a cell beside an opponent, as far as its movement allows,
+// and reports whether it moved
+func (b *Battle) advance(actor *Character) bool {
+	if b.Grid == nil || b.State != BattleStateActive || b.locked(actor) != "" {
+		return false
+	}
+	from, ok := b.Grid.Positions[actor.ID]
+	if !ok {
+		return false
+	}
+	side := b.sideOf(actor.ID)
+	besideOpponent := func(p Position) bool {
+		for _, c := range b.combatants() {
+			at, ok := b.Grid.Positions[c.ID]
+			if ok && c.Health > 0 && b.sideOf(c.ID) != side && p.distance(at) == 1 {
+				return true
+			}
+		}
+		return false
+	}
+	if besideOpponent(from) {
+		return false
+	}
+
+	// Search outwards in a fixed order, so the first goal found is the
+	// nearest and ties always break the same way
+	previous := map[Position]Position{from: from}
+	frontier := []Position{from}
+	for len(frontier) > 0 {
+		p := frontier[0]
+		frontier = frontier[1:]
+		if besideOpponent(p) {
+			route := []Position{p}
+			for previous[p] != from {
+				p = previous[p]
+				route = append([]Position{p}, route...)
+			}
+			to := route[min(actor.movement(), len(route))-1]
+			return b.applyAction(BattleAction{Kind: ActionMove, CharacterID: actor.ID, To: &to}).Success
+		}
+		for _, next := range []Position{{p.X, p.Y - 1}, {p.X - 1, p.Y}, {p.X + 1, p.Y}, {p.X, p.Y + 1}} {
+			if _, seen := previous[next]; seen || b.Grid.TerrainAt(next) != TerrainOpen || b.occupant(next) != nil {
+				continue
+			}
+			previous[next] = p
+			frontier = append(frontier, next)
+		}
+	}
+	return false
+}
+
+// placeNear puts c on the free cell nearest to p, and reports whether there
+// was one
+func (b *Battle) placeNear(c *Character, p Position) bool {
+	seen := map[Position]bool{p: true}
+	frontier := []Position{p}
+	for len(frontier) > 0 {
+		cell := frontier[0]
+		frontier = frontier[1:]
+		if b.Grid.TerrainAt(cell) == TerrainOpen && b.occupant(cell) == nil {
+			b.Grid.Positions[c.ID] = cell
+			return true
+		}
+		for _, next := range []Position{{cell.X + 1, cell.Y}, {cell.X - 1, cell.Y}, {cell.X, cell.Y + 1}, {cell.X, cell.Y - 1}} {
+			if !seen[next] && next.X >= 0 && next.Y >= 0 && next.X < b.Grid.Width() && next.Y < b.Grid.Height() {
+				seen[next] = true
+				frontier = append(frontier, next)
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,168 @@
+package game
+
+import "testing"
+
+// gridBattle sets a Warrior at (0, 0) against a Mage at (4, 0) on the rows,
+// Warrior to move
+func gridBattle(rows []string) (*Battle, *Character, *Character) {
+	char1 := createTestCharacter("Warrior", 100)
+	char1.Speed = 20
+	char2 := createTestCharacter("Mage", 100)
+	battle := newBattle(char1, char2, WithTurnMode(TurnModeSequential), WithGrid(rows, Position{0, 0}, Position{4, 0}))
+	activate(battle)
+	return battle, char1, char2
+}
+
+func TestBattle_Move(t *testing.T) {
+	battle, char1, char2 := gridBattle([]string{
+		".#...",
+		".#...",
+		"...~.",
+	})
+	char1.Movement = 4
+
+	tests := []struct {
+		name string
+		to   Position
+	}{
+		{"wall", Position{1, 0}},
+		{"water", Position{3, 2}},
+		{"occupied", Position{4, 0}},
+		{"off the map", Position{-1, 0}},
+		{"too far round the wall", Position{2, 0}},
+	}
+	for _, tt := range tests {
+		if result := battle.processAction(BattleAction{Kind: ActionMove, CharacterID: char1.ID, To: &tt.to}); result.Success {
+			t.Errorf("Expected a move onto %s to be rejected", tt.name)
+		}
+	}
+
+	to := Position{2, 2}
+	result := battle.processAction(BattleAction{Kind: ActionMove, CharacterID: char1.ID, To: &to})
+	if !result.Success || result.Move.Distance != 4 {
+		t.Fatalf("Expected a 4 step move round the wall, got %+v", result)
+	}
+	if battle.Grid.Positions[char1.ID] != to || battle.currentTurn() != char2 {
+		t.Error("Expected the Warrior to stand at (2, 2) and the Mage to be next")
+	}
+}
+
+func TestBattle_GridReach(t *testing.T) {
+	battle, char1, char2 := gridBattle([]string{
+		".....",
+		"..#..",
+		".....",
+	})
+	char1.Abilities[1].Range = 4
+
+	if result := battle.processAction(BattleAction{CharacterID: char1.ID, AbilityIndex: 0, TargetID: char2.ID}); result.Success {
+		t.Error("Expected a melee ability to fall short")
+	}
+	if result := battle.processAction(BattleAction{CharacterID: char1.ID, AbilityIndex: 1, TargetID: char2.ID}); !result.Success {
+		t.Fatalf("Expected a ranged ability to reach: %s", result.Message)
+	}
+
+	// The wall stands between (0, 1) and (4, 1)
+	battle.Grid.Positions[char1.ID] = Position{0, 1}
+	battle.Grid.Positions[char2.ID] = Position{4, 1}
+	char1.Abilities[1].Cooldown = 0
+	battle.turnIndex = 0
+	if result := battle.processAction(BattleAction{CharacterID: char1.ID, AbilityIndex: 1, TargetID: char2.ID}); result.Success {
+		t.Error("Expected the wall to block line of sight")
+	}
+}
+
+func TestArea_Covers(t *testing.T) {
+	grid := &Grid{Rows: []string{
+		".......",
+		".......",
+		"...#...",
+		".......",
+	}}
+	caster, target := Position{0, 1}, Position{2, 1}
+	tests := []struct {
+		area Area
+		cell Position
+		want bool
+	}{
+		{Area{AreaLine, 4}, Position{4, 1}, true},
+		{Area{AreaLine, 4}, Position{5, 1}, false},
+		{Area{AreaLine, 4}, Position{2, 0}, false},
+		{Area{AreaCone, 3}, Position{2, 0}, true},
+		{Area{AreaCone, 3}, Position{1, 3}, false},
+		{Area{AreaCone, 3}, Position{0, 0}, false},
+		{Area{AreaRadius, 1}, Position{2, 2}, true},
+		{Area{AreaRadius, 1}, Position{3, 2}, false},
+		{Area{AreaRadius, 2}, Position{4, 2}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.area.covers(grid, caster, target, tt.cell); got != tt.want {
+			t.Errorf("%s %d covers %v = %v, want %v", tt.area.Shape, tt.area.Size, tt.cell, got, tt.want)
+		}
+	}
+}
+
+func TestBattle_AreaHitsMinion(t *testing.T) {
+	battle, char1, char2 := gridBattle([]string{
+		".....",
+		".....",
+	})
+	withSummon(char1, 5, 0)
+	char2.Abilities[1].Range = 4
+	char2.Abilities[1].Area = &Area{Shape: AreaRadius, Size: 1}
+
+	battle.processAction(BattleAction{CharacterID: char1.ID, AbilityIndex: 2, TargetID: char2.ID})
+	wolf := battle.Minions[0].Character
+	if at := battle.Grid.Positions[wolf.ID]; at.distance(Position{0, 0}) != 1 {
+		t.Fatalf("Expected the Wolf next to the Warrior, got %v", at)
+	}
+
+	result := battle.processAction(BattleAction{CharacterID: char2.ID, AbilityIndex: 1, TargetID: char1.ID})
+	if !result.Success || len(result.Ability.AreaHits) != 1 || result.Ability.AreaHits[0] != wolf.ID {
+		t.Fatalf("Expected the blast to catch the Wolf, got %+v", result)
+	}
+	if wolf.Health != 0 || len(battle.Minions) != 0 || battle.Grid.Positions[wolf.ID] != (Position{}) {
+		t.Errorf("Expected the Wolf to fall and leave the grid, health %d", wolf.Health)
+	}
+}
+
+func TestBattle_Advance(t *testing.T) {
+	battle, char1, char2 := gridBattle([]string{
+		"......",
+		"......",
+	})
+	battle.Grid.Positions[char2.ID] = Position{5, 1}
+
+	if !battle.advance(char1) {
+		t.Fatal("Expected the Warrior to advance")
+	}
+	if at := battle.Grid.Positions[char1.ID]; at.distance(Position{5, 1}) != 3 {
+		t.Errorf("Expected the Warrior to close 3 cells, got to %v", at)
+	}
+
+	// Already adjacent, there is nowhere better to go
+	battle.Grid.Positions[char2.ID] = Position{4, 1}
+	battle.Grid.Positions[char1.ID] = Position{4, 0}
+	battle.turnOrder, battle.turnIndex = []*Character{char1, char2}, 0
+	if battle.advance(char1) {
+		t.Error("Expected an adjacent Warrior to stay put")
+	}
+}
+
+func TestBattle_PlayOnGrid(t *testing.T) {
+	battle, char1, char2 := gridBattle([]string{
+		"..#....",
+		"..#.~..",
+		".......",
+	})
+	battle.State = BattleStatePending
+	battle.Grid.Positions[char2.ID] = Position{6, 0}
+	battle.controllers = map[*Character]Strategy{char1: GreedyStrategy{}, char2: GreedyStrategy{}}
+
+	if err := battle.Play(200); err != nil {
+		t.Fatal(err)
+	}
+	if battle.Winner == nil {
+		t.Error("Expected the characters to close in and fight it out")
+	}
+}
@@ -0,0 +1,67 @@
+package game
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// BattleOption configures optional behaviour of a battle created by NewBattle
+type BattleOption func(*Battle)
+
+// WithTurnTimeout forfeits the pending turn when no action is accepted within
+// d. A forfeited turn still ticks status effects. Zero disables the timer,
+// and NewBattle rejects a negative d.
+func WithTurnTimeout(d time.Duration) BattleOption {
+	return func(b *Battle) {
+		b.turnTimeout = d
+	}
+}
+
+// WithTurnMode sets how strictly turn order is enforced
+func WithTurnMode(mode TurnMode) BattleOption {
+	return func(b *Battle) {
+		b.Rules.TurnMode = mode
+	}
+}
+
+// WithAI hands control of c to the given strategy. The battle plays c's turns
+// itself, so actions submitted for c are still accepted but rarely needed.
+func WithAI(c *Character, strategy Strategy) BattleOption {
+	return func(b *Battle) {
+		if b.controllers == nil {
+			b.controllers = make(map[*Character]Strategy)
+		}
+		b.controllers[c] = strategy
+	}
+}
+
+// WithOnComplete calls fn once the battle has been decided, after the final
+// action. It is not called for battles ended early with Stop. fn runs on the
+// battle's own goroutine, which exits once fn returns.
+func WithOnComplete(fn func(*Battle)) BattleOption {
+	return func(b *Battle) {
+		b.onComplete = fn
+	}
+}
+
+// WithReserves adds reserves to side 1 or 2 of a team battle. A character
+// may swap one in on its turn, and the first one standing takes over when
+// the character in front is defeated.
+func WithReserves(side int, reserves ...*Character) BattleOption {
+	return func(b *Battle) {
+		switch side {
+		case 1:
+			b.Reserves1 = append(b.Reserves1, reserves...)
+		case 2:
+			b.Reserves2 = append(b.Reserves2, reserves...)
+		}
+	}
+}
+
+// WithRand sets the source of chance outcomes such as fleeing, so that
+// battles can be replayed
+func WithRand(rng *rand.Rand) BattleOption {
+	return func(b *Battle) {
+		b.rng = rng
+	}
+}
@@ -0,0 +1,194 @@
+package game
+
+import "fmt"
+
+// PendingKind says how an ability that does not resolve at once plays out
+type PendingKind string
+
+const (
+	// PendingCharging abilities land once they have charged, unless the
+	// caster is interrupted first. The caster cannot act meanwhile.
+	PendingCharging PendingKind = "CHARGING"
+	// PendingChannelling abilities land at the end of every round they are
+	// channelled, for as long as the caster keeps it up. The caster cannot
+	// act meanwhile.
+	PendingChannelling PendingKind = "CHANNELLING"
+	// PendingDelayed abilities land once their delay runs out, whatever
+	// becomes of the caster
+	PendingDelayed PendingKind = "DELAYED"
+)
+
+// pendingVerbs say what is going on with each kind of pending ability
+var pendingVerbs = map[PendingKind]string{
+	PendingCharging:    "charging",
+	PendingChannelling: "channelling",
+	PendingDelayed:     "counting down",
+}
+
+// PendingAbility is an ability cast but not yet done with, such as a charge
+// or a bomb, and is visible to both sides
+type PendingAbility struct {
+	Kind         PendingKind `json:"Kind"`
+	CasterID     string      `json:"CasterID"`
+	AbilityIndex int         `json:"AbilityIndex"`
+	Ability      string      `json:"Ability"`
+	// TargetSide is 1 or 2 for the side the ability is aimed at. It lands on
+	// whoever is in front on that side at the time.
+	TargetSide int `json:"TargetSide"`
+	// Remaining is the number of rounds left, counting the current one
+	Remaining int `json:"Remaining"`
+	// startHealth is the caster's health when the charge or channel began
+	startHealth int
+	ability     Ability
+}
+
+// pendingKind returns how a's effect is put off and for how many rounds, or
+// "" if it lands at once
+func (a Ability) pendingKind() (PendingKind, int) {
+	switch {
+	case a.ChargeRounds > 0:
+		return PendingCharging, a.ChargeRounds
+	case a.ChannelRounds > 0:
+		return PendingChannelling, a.ChannelRounds
+	case a.DelayRounds > 0:
+		return PendingDelayed, a.DelayRounds
+	}
+	return "", 0
+}
+
+// lockedBy returns the charge or channel actor is locked into, if any
+func (b *Battle) lockedBy(actor *Character) *PendingAbility {
+	for i := range b.Pending {
+		p := &b.Pending[i]
+		if p.CasterID == actor.ID && p.Kind != PendingDelayed {
+			return p
+		}
+	}
+	return nil
+}
+
+// locked explains why actor cannot act, or returns "" if it can
+func (b *Battle) locked(actor *Character) string {
+	if actor.suffers(StatusStunned) {
+		return fmt.Sprintf("%s is stunned", actor.Name)
+	}
+	if p := b.lockedBy(actor); p != nil {
+		return fmt.Sprintf("%s is %s %s", actor.Name, pendingVerbs[p.Kind], p.Ability)
+	}
+	return ""
+}
+
+// applyPending puts the ability on cooldown and starts it, to land later
+func (b *Battle) applyPending(actor, target *Character, action BattleAction) BattleActionResult {
+	ability := &actor.Abilities[action.AbilityIndex]
+	if !ability.CanUse() {
+		return b.reject("Ability on cooldown")
+	}
+	actor.lowerGuard()
+	kind, rounds := ability.pendingKind()
+	pending := PendingAbility{
+		Kind:         kind,
+		CasterID:     actor.ID,
+		AbilityIndex: action.AbilityIndex,
+		Ability:      ability.Name,
+		TargetSide:   b.sideOf(target.ID),
+		Remaining:    rounds,
+		startHealth:  actor.Health,
+		ability:      *ability,
+	}
+	ability.Use()
+	b.Pending = append(b.Pending, pending)
+	b.history = append(b.history, ActionRecord{
+		Round:        b.Round,
+		Kind:         ActionAbility,
+		CharacterID:  actor.ID,
+		AbilityIndex: action.AbilityIndex,
+		Ability:      ability.Name,
+		TargetID:     target.ID,
+	})
+	b.endTurn(actor, action.AbilityIndex)
+
+	result := AbilityResult{
+		Success: true,
+		Message: fmt.Sprintf("%s begins %s", ability.Name, pendingVerbs[kind]),
+		Pending: &pending,
+	}
+	return BattleActionResult{
+		Success: true,
+		Message: result.Message,
+		Battle:  b,
+		Ability: &result,
+	}
+}
+
+// interruptPending breaks off the charges and channels of casters who have
+// fallen, left the front, been stunned or taken too much damage
+func (b *Battle) interruptPending() {
+	kept := b.Pending[:0]
+	for _, p := range b.Pending {
+		if p.Kind == PendingDelayed {
+			kept = append(kept, p)
+			continue
+		}
+		caster := b.characterByID(p.CasterID)
+		threshold := p.ability.InterruptDamage
+		if caster == nil || caster.Health <= 0 || caster.suffers(StatusStunned) ||
+			(threshold > 0 && p.startHealth-caster.Health >= threshold) {
+			continue
+		}
+		kept = append(kept, p)
+	}
+	b.Pending = kept
+}
+
+// resolvePending lands every pending ability that is due at the end of the
+// round and counts down the rest
+func (b *Battle) resolvePending() {
+	kept := b.Pending[:0]
+	for _, p := range b.Pending {
+		p.Remaining--
+		if p.Kind == PendingChannelling || p.Remaining <= 0 {
+			b.landPending(p)
+		}
+		if p.Remaining > 0 {
+			kept = append(kept, p)
+		}
+	}
+	b.Pending = kept
+}
+
+// landPending deals a pending ability's damage and effect to whoever is in
+// front on the side it was aimed at, and to anyone else in its area. A
+// caster that has since left the front still lends its stats to a delayed
+// ability.
+func (b *Battle) landPending(p PendingAbility) {
+	caster := b.anyCharacterByID(p.CasterID)
+	target := b.Character1
+	if p.TargetSide == 2 {
+		target = b.Character2
+	}
+	if caster == nil || target.Health <= 0 {
+		return
+	}
+	h := b.hitFor(p.ability)
+	caster.strike(&p.ability, target, h)
+	for _, c := range b.caughtIn(caster, target, p.ability) {
+		caster.strike(&p.ability, c, h)
+	}
+	b.changeWeather(p.ability)
+}
+
+// anyCharacterByID returns the character with the given ID, whether in
+// front, in reserve or fled, or nil
+func (b *Battle) anyCharacterByID(id string) *Character {
+	everyone := append(append(b.combatants(), b.Reserves1...), b.Reserves2...)
+	if b.Fled != nil {
+		everyone = append(everyone, b.Fled)
+	}
+	for _, c := range everyone {
+		if c.ID == id {
+			return c
+		}
+	}
+	return nil
+}
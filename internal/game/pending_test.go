@@ -0,0 +1,154 @@
+package game
+
+import "testing"
+
+func TestBattle_ChargedAbility(t *testing.T) {
+	meteor := Ability{Name: "Meteor", Damage: 40, CooldownMax: 3, ChargeRounds: 1, InterruptDamage: 20}
+	tests := []struct {
+		name       string
+		reply      Ability // char2's answer while char1 charges
+		wantHealth int     // char2's health once the round is over
+	}{
+		{"lands", Ability{Name: "Jab", Damage: 5}, 55},
+		{"interrupted by damage", Ability{Name: "Wallop", Damage: 20}, 100},
+		{"interrupted by stun", Ability{Name: "Daze", StatusEffect: StatusEffectData{Type: StatusStunned, Duration: 2}}, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			char1 := createTestCharacter("Warrior", 100)
+			char1.Abilities = append(char1.Abilities, meteor)
+			char2 := createTestCharacter("Mage", 100)
+			char2.Abilities = append(char2.Abilities, tt.reply)
+			battle := newBattle(char1, char2)
+			activate(battle)
+
+			result := battle.processAction(BattleAction{CharacterID: char1.ID, AbilityIndex: 2, TargetID: char2.ID})
+			if !result.Success || result.Ability.Pending == nil || len(battle.Pending) != 1 {
+				t.Fatalf("Expected Meteor to start charging, got %+v", result)
+			}
+			if char2.Health != 100 {
+				t.Errorf("Meteor landed at once, Mage health %d", char2.Health)
+			}
+			if result := battle.processAction(BattleAction{CharacterID: char1.ID, AbilityIndex: 0, TargetID: char2.ID}); result.Success {
+				t.Error("Expected a charging caster to be unable to act")
+			}
+
+			battle.processAction(BattleAction{CharacterID: char2.ID, AbilityIndex: 2, TargetID: char1.ID})
+			if char2.Health != tt.wantHealth || len(battle.Pending) != 0 {
+				t.Errorf("Mage health = %d with %d pending, want %d and none", char2.Health, len(battle.Pending), tt.wantHealth)
+			}
+		})
+	}
+}
+
+func TestBattle_ChannelledAbility(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char1.Abilities = append(char1.Abilities, Ability{Name: "Beam", ChannelRounds: 2})
+	char2 := createTestCharacter("Mage", 100)
+	battle := newBattle(char1, char2, WithTurnMode(TurnModeSequential))
+	activate(battle)
+
+	battle.processAction(BattleAction{CharacterID: char1.ID, AbilityIndex: 2, TargetID: char2.ID})
+	battle.processAction(BattleAction{CharacterID: char2.ID, AbilityIndex: 0, TargetID: char1.ID})
+	if char2.Health != 95 || len(battle.Pending) != 1 || battle.Pending[0].Remaining != 1 {
+		t.Fatalf("After one round: health %d, pending %+v, want 95 and one round left", char2.Health, battle.Pending)
+	}
+	// The channelling Warrior's turn passes by itself
+	if battle.currentTurn() != char2 {
+		t.Fatalf("Expected the channelling Warrior's turn to be skipped, current turn %s", battle.currentTurn().Name)
+	}
+
+	battle.processAction(BattleAction{CharacterID: char2.ID, AbilityIndex: 0, TargetID: char1.ID})
+	if char2.Health != 90 || len(battle.Pending) != 0 {
+		t.Errorf("After two rounds: health %d, pending %+v, want 90 and none", char2.Health, battle.Pending)
+	}
+	if battle.currentTurn() != char1 {
+		t.Errorf("Expected the Warrior to act again once the channel ended")
+	}
+}
+
+func TestBattle_DelayedAbility(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char1.Abilities = append(char1.Abilities, Ability{Name: "Time Bomb", Damage: 20, DelayRounds: 2})
+	char2 := createTestCharacter("Mage", 100)
+	reserve := createTestCharacter("Rogue", 100)
+	battle := newBattle(char1, char2, WithReserves(2, reserve))
+	activate(battle)
+
+	battle.processAction(BattleAction{CharacterID: char1.ID, AbilityIndex: 2, TargetID: char2.ID})
+	battle.processAction(BattleAction{Kind: ActionSwap, CharacterID: char2.ID, TargetID: reserve.ID})
+	if reserve.Health != 100 || len(battle.Pending) != 1 {
+		t.Fatalf("Time Bomb went off early")
+	}
+	// A delayed ability leaves the caster free to act
+	if result := battle.processAction(BattleAction{Kind: ActionDefend, CharacterID: char1.ID}); !result.Success {
+		t.Fatalf("Expected the caster to act while the bomb ticks: %s", result.Message)
+	}
+	battle.processAction(BattleAction{CharacterID: reserve.ID, AbilityIndex: 0, TargetID: char1.ID})
+
+	if reserve.Health != 75 || char2.Health != 100 || len(battle.Pending) != 0 {
+		t.Errorf("Rogue health %d, Mage health %d, want the bomb to hit the Rogue in front for 25", reserve.Health, char2.Health)
+	}
+}
+
+func TestBattle_StunnedSkipsTurns(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char1.Abilities = append(char1.Abilities, Ability{Name: "Daze", StatusEffect: StatusEffectData{Type: StatusStunned, Duration: 2}})
+	char2 := createTestCharacter("Mage", 100)
+	battle := newBattle(char1, char2, WithTurnMode(TurnModeSequential))
+	activate(battle)
+
+	battle.processAction(BattleAction{CharacterID: char1.ID, AbilityIndex: 2, TargetID: char2.ID})
+	if battle.currentTurn() != char1 || battle.Round != 2 {
+		t.Errorf("Expected the stunned Mage's turn to be skipped, current turn %s in round %d", battle.currentTurn().Name, battle.Round)
+	}
+	if result := battle.processAction(BattleAction{CharacterID: char1.ID, AbilityIndex: 0, TargetID: char2.ID}); !result.Success {
+		t.Fatal(result.Message)
+	}
+	if battle.currentTurn() != char2 {
+		t.Error("Expected the Mage to act once the stun wore off")
+	}
+}
+
+func TestBattle_SimultaneousWhileCharging(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char1.Abilities = append(char1.Abilities, Ability{Name: "Meteor", Damage: 40, ChargeRounds: 2})
+	char2 := createTestCharacter("Mage", 100)
+	battle := newBattle(char1, char2, WithTurnMode(TurnModeSimultaneous))
+	activate(battle)
+
+	battle.processAction(BattleAction{CharacterID: char1.ID, AbilityIndex: 2, TargetID: char2.ID})
+	battle.processAction(BattleAction{CharacterID: char2.ID, AbilityIndex: 0, TargetID: char1.ID})
+	// The charging Warrior cannot seal, so the Mage's action alone resolves
+	// the round
+	result := battle.processAction(BattleAction{CharacterID: char2.ID, AbilityIndex: 0, TargetID: char1.ID})
+	if !result.Success || len(result.Resolution) == 0 || battle.Round != 3 {
+		t.Fatalf("Expected the round to resolve on the Mage's action, got round %d: %+v", battle.Round, result)
+	}
+	if char2.Health != 55 {
+		t.Errorf("Mage health = %d, want 55 after Meteor", char2.Health)
+	}
+}
+
+func TestBattle_ATBWhileCharging(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char1.Speed = 20
+	char1.Abilities = append(char1.Abilities, Ability{Name: "Meteor", Damage: 40, ChargeRounds: 2})
+	char2 := createTestCharacter("Mage", 100)
+	battle := newBattle(char1, char2, WithTurnMode(TurnModeATB))
+	activate(battle)
+
+	if battle.nextReady() != char1 {
+		t.Fatal("Expected the faster Warrior to be ready first")
+	}
+	battle.processAction(BattleAction{CharacterID: char1.ID, AbilityIndex: 2, TargetID: char2.ID})
+	for i := 0; i < 10 && len(battle.Pending) > 0; i++ {
+		if actor := battle.nextReady(); actor != char2 {
+			t.Fatalf("The charging Warrior should pass, not be ready to act")
+		}
+		battle.processAction(BattleAction{CharacterID: char2.ID, AbilityIndex: 0, TargetID: char1.ID})
+	}
+	if len(battle.Pending) != 0 || char2.Health != 55 {
+		t.Errorf("Pending %+v, Mage health %d, want Meteor to have landed for 45", battle.Pending, char2.Health)
+	}
+}
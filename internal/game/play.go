@@ -0,0 +1,68 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Play runs a pending battle to completion on the calling goroutine. Every
+// character must have a strategy set with WithAI. Unlike Start, Play never
+// runs the battle loop or its ticker, so it suits running many battles
+// offline. A battle still undecided after maxTurns turns ends with no winner.
+// The WithOnComplete callback runs before Play returns.
+func (b *Battle) Play(maxTurns int) error {
+	if err := b.playTurns(maxTurns); err != nil {
+		return err
+	}
+	b.notifyComplete()
+	return nil
+}
+
+func (b *Battle) playTurns(maxTurns int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.State != BattleStatePending {
+		return errors.New("battle already started")
+	}
+	for _, c := range b.combatants() {
+		if _, ok := b.controllers[c]; !ok {
+			return fmt.Errorf("no strategy for %s", c.Name)
+		}
+	}
+
+	b.State = BattleStateActive
+	b.applyStartingCooldowns()
+	b.turnOrder = b.initiativeOrder()
+	b.turnIndex = 0
+
+	for turn := 0; turn < maxTurns && b.State == BattleStateActive; turn++ {
+		actor := b.currentTurn()
+		switch b.TurnMode {
+		case TurnModeSimultaneous:
+			// Nobody may be able to act, such as when everyone is stunned
+			unsealed := b.unsealed()
+			if len(unsealed) == 0 {
+				b.resolveRound()
+				continue
+			}
+			actor = unsealed[0]
+		case TurnModeATB:
+			actor = b.nextReady()
+		}
+		action, ok := b.controllers[actor].ChooseAction(b.viewFor(actor))
+		if (ok && b.applyAction(action).Success) || b.advance(actor) {
+			continue
+		}
+		if b.TurnMode == TurnModeSimultaneous {
+			b.pass(actor)
+		} else {
+			b.endTurn(actor, -1)
+		}
+	}
+
+	if b.State == BattleStateActive {
+		b.conclude()
+	}
+	return nil
+}
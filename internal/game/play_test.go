@@ -0,0 +1,91 @@
+package game
+
+import (
+	"context"
+	"testing"
+)
+
+func TestBattle_Play(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char2 := createTestCharacter("Mage", 60)
+	battle := newBattle(char1, char2, WithAI(char1, GreedyStrategy{}), WithAI(char2, GreedyStrategy{}))
+
+	if err := battle.Play(100); err != nil {
+		t.Fatalf("Play() error = %v", err)
+	}
+
+	if battle.State != BattleStateComplete {
+		t.Errorf("Expected battle state to be COMPLETE, got %v", battle.State)
+	}
+	if battle.Winner != char1 {
+		t.Errorf("Expected the healthier character to win, got %v", battle.Winner)
+	}
+	if battle.Done() != nil {
+		t.Error("Expected Play not to start the battle loop")
+	}
+
+	history := battle.History()
+	if len(history) == 0 {
+		t.Fatal("Expected abilities to be recorded")
+	}
+	dealt := 0
+	for _, record := range history {
+		if record.TargetID == char2.ID {
+			dealt += record.Damage
+		}
+	}
+	if dealt == 0 || dealt > 60 {
+		t.Errorf("Expected recorded damage to char2 between 1 and 60, got %d", dealt)
+	}
+
+	if err := battle.Play(100); err == nil {
+		t.Error("Expected error when playing a finished battle")
+	}
+}
+
+func TestBattle_PlayTurnLimit(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 1000)
+	char2 := createTestCharacter("Mage", 1000)
+	battle := newBattle(char1, char2, WithAI(char1, GreedyStrategy{}), WithAI(char2, GreedyStrategy{}))
+
+	if err := battle.Play(4); err != nil {
+		t.Fatalf("Play() error = %v", err)
+	}
+	if battle.State != BattleStateComplete || battle.Winner != nil {
+		t.Errorf("Expected a draw after the turn limit, got %v won by %v", battle.State, battle.Winner)
+	}
+	if battle.Round != 3 {
+		t.Errorf("Expected 4 turns to reach round 3, got %d", battle.Round)
+	}
+}
+
+func TestBattle_PlayRequiresStrategies(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char2 := createTestCharacter("Mage", 60)
+	battle := newBattle(char1, char2, WithAI(char1, GreedyStrategy{}))
+
+	if err := battle.Play(100); err == nil {
+		t.Error("Expected error when a character has no strategy")
+	}
+	if err := battle.Start(context.Background()); err != nil {
+		t.Errorf("Expected failed Play to leave the battle pending: %v", err)
+	}
+	battle.Stop()
+}
+
+func TestBattle_PlaySimultaneousAllStunned(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char2 := createTestCharacter("Mage", 60)
+	for _, c := range []*Character{char1, char2} {
+		c.StatusEffects = []StatusEffectData{{Type: StatusStunned, Duration: 2}}
+	}
+	battle := newBattle(char1, char2, WithTurnMode(TurnModeSimultaneous),
+		WithAI(char1, GreedyStrategy{}), WithAI(char2, GreedyStrategy{}))
+
+	if err := battle.Play(100); err != nil {
+		t.Fatalf("Play() error = %v", err)
+	}
+	if battle.State != BattleStateComplete || battle.Winner != char1 {
+		t.Errorf("Expected the Warrior to win once the stun wore off, got %v won by %v", battle.State, battle.Winner)
+	}
+}
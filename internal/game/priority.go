@@ -0,0 +1,65 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+)
+
+// MaxPriority bounds an ability's priority bracket either way
+const MaxPriority = 3
+
+// priority returns the bracket action belongs in: its ability's Priority, or
+// 0 for anything else
+func (c *Character) priority(action *BattleAction) int {
+	if action == nil || (action.Kind != "" && action.Kind != ActionAbility) {
+		return 0
+	}
+	if action.AbilityIndex < 0 || action.AbilityIndex >= len(c.Abilities) {
+		return 0
+	}
+	return c.Abilities[action.AbilityIndex].Priority
+}
+
+// resolutionOrder orders the characters who sealed actions by the bracket of
+// their action, highest first, and by initiative within a bracket
+func (b *Battle) resolutionOrder(sealed map[string]sealedAction) []*Character {
+	order := b.initiativeOrder()
+	bracket := func(c *Character) int {
+		return c.priority(sealed[c.ID].action)
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return bracket(order[i]) > bracket(order[j])
+	})
+	return order
+}
+
+// jumpQueue lets a character whose turn is yet to come this round use an
+// ability with a positive priority now, taking the place of the character
+// whose turn it is, who then goes next. The order is left as it was if the
+// action fails.
+func (b *Battle) jumpQueue(actor *Character, action BattleAction) BattleActionResult {
+	if actor.priority(&action) <= 0 {
+		return b.reject("not your turn")
+	}
+	at := -1
+	for i := b.turnIndex + 1; i < len(b.turnOrder); i++ {
+		if b.turnOrder[i] == actor {
+			at = i
+		}
+	}
+	if at < 0 {
+		return b.reject(fmt.Sprintf("%s has already acted this round", actor.Name))
+	}
+
+	order := b.turnOrder
+	jumped := append([]*Character(nil), order[:b.turnIndex]...)
+	jumped = append(jumped, actor)
+	jumped = append(jumped, order[b.turnIndex:at]...)
+	b.turnOrder = append(jumped, order[at+1:]...)
+
+	result := b.applyAction(action)
+	if !result.Success {
+		b.turnOrder = order
+	}
+	return result
+}
@@ -0,0 +1,72 @@
+package game
+
+import "testing"
+
+func TestBattle_SimultaneousPriority(t *testing.T) {
+	tests := []struct {
+		name      string
+		priority  int
+		speed     int
+		wantFirst string
+	}{
+		{"faster goes first", 0, 5, "Warrior_id"},
+		{"priority beats speed", 1, 5, "Mage_id"},
+		{"negative priority goes last", -1, 50, "Warrior_id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			char1 := createTestCharacter("Warrior", 100)
+			char1.Speed = 20
+			char2 := createTestCharacter("Mage", 100)
+			char2.Speed = tt.speed
+			char2.Abilities = append(char2.Abilities, Ability{Name: "Counter", Damage: 5, Priority: tt.priority})
+			battle := newBattle(char1, char2, WithTurnMode(TurnModeSimultaneous))
+			activate(battle)
+
+			battle.processAction(BattleAction{CharacterID: char1.ID, AbilityIndex: 0, TargetID: char2.ID})
+			result := battle.processAction(BattleAction{CharacterID: char2.ID, AbilityIndex: 2, TargetID: char1.ID})
+			if len(result.Resolution) != 2 {
+				t.Fatalf("Expected the round to resolve, got %+v", result)
+			}
+			if got := result.Resolution[0].CharacterID; got != tt.wantFirst {
+				t.Errorf("First to resolve = %s, want %s", got, tt.wantFirst)
+			}
+		})
+	}
+}
+
+func TestBattle_SequentialPriority(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char1.Speed = 20
+	char2 := createTestCharacter("Mage", 100)
+	char2.Abilities = append(char2.Abilities, Ability{Name: "Counter", Damage: 5, CooldownMax: 2, Priority: 1})
+	battle := newBattle(char1, char2, WithTurnMode(TurnModeSequential))
+	activate(battle)
+
+	if result := battle.processAction(BattleAction{CharacterID: char2.ID, AbilityIndex: 0, TargetID: char1.ID}); result.Success {
+		t.Error("Expected an ordinary ability out of turn to be rejected")
+	}
+	if result := battle.processAction(BattleAction{CharacterID: char2.ID, AbilityIndex: 2, TargetID: char1.ID}); !result.Success {
+		t.Fatalf("Expected the priority ability to jump the queue: %s", result.Message)
+	}
+	if battle.currentTurn() != char1 || battle.Round != 1 {
+		t.Fatalf("Expected the Warrior to act next in round 1, got %s in round %d", battle.currentTurn().Name, battle.Round)
+	}
+	char2.Abilities[2].Cooldown = 0
+	if result := battle.processAction(BattleAction{CharacterID: char2.ID, AbilityIndex: 2, TargetID: char1.ID}); result.Success {
+		t.Error("Expected the Mage to be unable to act twice in a round")
+	}
+	battle.processAction(BattleAction{CharacterID: char1.ID, AbilityIndex: 0, TargetID: char2.ID})
+	if battle.Round != 2 || battle.currentTurn() != char1 {
+		t.Errorf("Expected round 2 to start with the Warrior, got round %d with %s", battle.Round, battle.currentTurn().Name)
+	}
+
+	// A priority ability that fails leaves the order as it was
+	char2.Abilities[2].Cooldown = 2
+	if result := battle.processAction(BattleAction{CharacterID: char2.ID, AbilityIndex: 2, TargetID: char1.ID}); result.Success {
+		t.Fatal("Expected an ability on cooldown to be rejected")
+	}
+	if battle.currentTurn() != char1 || battle.turnOrder[1] != char2 {
+		t.Error("Expected a failed jump to leave the turn order alone")
+	}
+}
@@ -0,0 +1,185 @@
+package game
+
+import "fmt"
+
+// DamageModel decides how Defense softens an ability's damage
+type DamageModel string
+
+const (
+	// DamageSubtract takes Defense straight off the damage
+	DamageSubtract DamageModel = "SUBTRACT"
+	// DamagePercent cuts damage by Defense/(100+Defense), so every point of
+	// Defense helps but none makes a character untouchable
+	DamagePercent DamageModel = "PERCENT"
+)
+
+// EffectTick decides when status effects take hold
+type EffectTick string
+
+const (
+	// EffectTickAction ticks every effect after every action
+	EffectTickAction EffectTick = "ACTION"
+	// EffectTickRound ticks every effect once, at the end of each round
+	EffectTickRound EffectTick = "ROUND"
+)
+
+// RuleSet gathers the rules a battle is played by
+type RuleSet struct {
+	// Name is the preset the rules came from, if any
+	Name     string   `json:"Name,omitempty"`
+	TurnMode TurnMode `json:"TurnMode"`
+	// MaxRounds ends the battle as a draw once that many rounds have been
+	// played. Zero plays until somebody wins.
+	MaxRounds   int         `json:"MaxRounds"`
+	DamageModel DamageModel `json:"DamageModel"`
+	EffectTick  EffectTick  `json:"EffectTick"`
+	// AllowSelfTarget lets characters use abilities on themselves. Items
+	// follow their own Target regardless.
+	AllowSelfTarget bool `json:"AllowSelfTarget"`
+	// StartingCooldowns puts every ability on its full cooldown when the
+	// battle starts, so openers cannot lead with their strongest move
+	StartingCooldowns bool `json:"StartingCooldowns"`
+	// ActionBuffer is how many submitted actions may queue for the loop
+	ActionBuffer int `json:"ActionBuffer"`
+	// Visibility is what each player is kept from seeing of the other side
+	Visibility Visibility `json:"Visibility"`
+}
+
+// ClassicRules are the rules battles have always been played by
+var ClassicRules = RuleSet{
+	Name:            "classic",
+	TurnMode:        TurnModeFree,
+	DamageModel:     DamageSubtract,
+	EffectTick:      EffectTickAction,
+	AllowSelfTarget: true,
+	ActionBuffer:    100,
+}
+
+var presets = []RuleSet{
+	ClassicRules,
+	{
+		Name:              "competitive",
+		TurnMode:          TurnModeSequential,
+		MaxRounds:         30,
+		DamageModel:       DamagePercent,
+		EffectTick:        EffectTickRound,
+		StartingCooldowns: true,
+		ActionBuffer:      100,
+		Visibility: Visibility{
+			HideCooldowns:           true,
+			HideUnrevealedAbilities: true,
+			HealthBuckets:           4,
+		},
+	},
+	{
+		Name:         "blitz",
+		TurnMode:     TurnModeSimultaneous,
+		MaxRounds:    10,
+		DamageModel:  DamageSubtract,
+		EffectTick:   EffectTickRound,
+		ActionBuffer: 100,
+	},
+	{
+		Name:            "realtime",
+		TurnMode:        TurnModeATB,
+		DamageModel:     DamagePercent,
+		EffectTick:      EffectTickAction,
+		AllowSelfTarget: true,
+		ActionBuffer:    100,
+	},
+}
+
+// Presets returns the named rule sets
+func Presets() []RuleSet {
+	return append([]RuleSet(nil), presets...)
+}
+
+// Preset returns the rule set with the given name
+func Preset(name string) (RuleSet, bool) {
+	for _, r := range presets {
+		if r.Name == name {
+			return r, true
+		}
+	}
+	return RuleSet{}, false
+}
+
+// TurnModes lists every turn mode
+var TurnModes = []TurnMode{TurnModeFree, TurnModeSequential, TurnModeSimultaneous, TurnModeATB}
+
+// IsValid reports whether the battle knows how to run the turn mode
+func (m TurnMode) IsValid() bool {
+	for _, known := range TurnModes {
+		if m == known {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValid reports whether the damage model is known
+func (m DamageModel) IsValid() bool {
+	return m == DamageSubtract || m == DamagePercent
+}
+
+// IsValid reports whether the tick phase is known
+func (t EffectTick) IsValid() bool {
+	return t == EffectTickAction || t == EffectTickRound
+}
+
+// Validate reports the first problem with r that keeps a battle from being
+// played by it. Empty fields are fine, since they take the classic rule.
+func (r RuleSet) Validate() error {
+	switch {
+	case r.TurnMode != "" && !r.TurnMode.IsValid():
+		return fmt.Errorf("unknown turn mode %q", r.TurnMode)
+	case r.DamageModel != "" && !r.DamageModel.IsValid():
+		return fmt.Errorf("unknown damage model %q", r.DamageModel)
+	case r.EffectTick != "" && !r.EffectTick.IsValid():
+		return fmt.Errorf("unknown effect tick %q", r.EffectTick)
+	case r.MaxRounds < 0:
+		return fmt.Errorf("max rounds must not be negative, got %d", r.MaxRounds)
+	case r.ActionBuffer < 0:
+		return fmt.Errorf("action buffer must not be negative, got %d", r.ActionBuffer)
+	case r.Visibility.HealthBuckets < 0:
+		return fmt.Errorf("health buckets must not be negative, got %d", r.Visibility.HealthBuckets)
+	}
+	return nil
+}
+
+// mitigate returns what is left of damage after the target's Defense
+func (m DamageModel) mitigate(damage, defense int) int {
+	if m == DamagePercent {
+		if defense <= 0 {
+			return max(damage, 0)
+		}
+		return max(damage*100/(100+defense), 0)
+	}
+	return max(damage-defense, 0)
+}
+
+// WithRules plays the battle by r. An empty turn mode, damage model, tick
+// phase or action buffer takes the classic rule; NewBattle rejects rules
+// that fail Validate.
+func WithRules(r RuleSet) BattleOption {
+	return func(b *Battle) {
+		b.Rules = r
+	}
+}
+
+// normalize fills empty fields of r from the classic rules
+func (r RuleSet) normalize() RuleSet {
+	if r.TurnMode == "" {
+		r.TurnMode = ClassicRules.TurnMode
+	}
+	if r.DamageModel == "" {
+		r.DamageModel = ClassicRules.DamageModel
+	}
+	if r.EffectTick == "" {
+		r.EffectTick = ClassicRules.EffectTick
+	}
+	if r.ActionBuffer <= 0 {
+		r.ActionBuffer = ClassicRules.ActionBuffer
+	}
+	return r
+}
@@ -0,0 +1,143 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPreset(t *testing.T) {
+	for _, want := range Presets() {
+		got, ok := Preset(want.Name)
+		if !ok || got != want {
+			t.Errorf("Preset(%q) = %+v, %v", want.Name, got, ok)
+		}
+	}
+	if _, ok := Preset("chess"); ok {
+		t.Error("Expected no preset named chess")
+	}
+}
+
+func TestNewBattle_Rules(t *testing.T) {
+	battle := newBattle(createTestCharacter("Warrior", 100), createTestCharacter("Mage", 100))
+	if battle.Rules != ClassicRules || battle.TurnMode != TurnModeFree || cap(battle.ActionChan) != 100 {
+		t.Errorf("Expected classic rules by default, got %+v", battle.Rules)
+	}
+
+	battle = newBattle(createTestCharacter("Warrior", 100), createTestCharacter("Mage", 100),
+		WithRules(RuleSet{MaxRounds: 5, ActionBuffer: 8}), WithTurnMode(TurnModeSequential))
+	want := RuleSet{TurnMode: TurnModeSequential, MaxRounds: 5, DamageModel: DamageSubtract, EffectTick: EffectTickAction, ActionBuffer: 8}
+	if battle.Rules != want || battle.TurnMode != TurnModeSequential || cap(battle.ActionChan) != 8 {
+		t.Errorf("Rules = %+v, want %+v", battle.Rules, want)
+	}
+}
+
+func TestNewBattle_InvalidRules(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  BattleOption
+	}{
+		{"unknown turn mode", WithTurnMode("CHAOS")},
+		{"unknown damage model", WithRules(RuleSet{DamageModel: "DOUBLE"})},
+		{"unknown effect tick", WithRules(RuleSet{EffectTick: "NEVER"})},
+		{"negative max rounds", WithRules(RuleSet{MaxRounds: -1})},
+		{"negative action buffer", WithRules(RuleSet{ActionBuffer: -1})},
+		{"negative health buckets", WithRules(RuleSet{Visibility: Visibility{HealthBuckets: -4}})},
+		{"negative turn timeout", WithTurnTimeout(-time.Second)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			battle, err := NewBattle(createTestCharacter("Warrior", 100), createTestCharacter("Mage", 100), tt.opt)
+			if err == nil || battle != nil {
+				t.Errorf("NewBattle() = %v, %v, want an error", battle, err)
+			}
+		})
+	}
+
+	for _, preset := range Presets() {
+		if _, err := NewBattle(createTestCharacter("Warrior", 100), createTestCharacter("Mage", 100), WithRules(preset)); err != nil {
+			t.Errorf("NewBattle() with the %s preset: %v", preset.Name, err)
+		}
+	}
+}
+
+func TestBattle_RuleDamageModel(t *testing.T) {
+	tests := []struct {
+		model      DamageModel
+		wantHealth int
+	}{
+		{DamageSubtract, 75}, // 10 + 20 attack - 5 defense
+		{DamagePercent, 72},  // 30 * 100/105
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.model), func(t *testing.T) {
+			char1 := createTestCharacter("Warrior", 100)
+			char1.Attack = 20
+			char2 := createTestCharacter("Mage", 100)
+			battle := newBattle(char1, char2, WithRules(RuleSet{DamageModel: tt.model}))
+			activate(battle)
+
+			battle.processAction(BattleAction{CharacterID: char1.ID, AbilityIndex: 0, TargetID: char2.ID})
+			if char2.Health != tt.wantHealth {
+				t.Errorf("Health = %d, want %d", char2.Health, tt.wantHealth)
+			}
+		})
+	}
+}
+
+func TestBattle_RuleEffectTick(t *testing.T) {
+	burning := func() []StatusEffectData {
+		return []StatusEffectData{{Type: StatusBurning, Duration: 5, Potency: 10}}
+	}
+	remaining := map[EffectTick]int{}
+	for _, tick := range []EffectTick{EffectTickAction, EffectTickRound} {
+		char1 := createTestCharacter("Warrior", 100)
+		char2 := createTestCharacter("Mage", 100)
+		char2.StatusEffects = burning()
+		battle := newBattle(char1, char2, WithRules(RuleSet{EffectTick: tick}))
+		activate(battle)
+
+		battle.processAction(BattleAction{Kind: ActionDefend, CharacterID: char1.ID})
+		battle.processAction(BattleAction{Kind: ActionDefend, CharacterID: char2.ID})
+		remaining[tick] = char2.StatusEffects[0].Duration
+	}
+	if remaining[EffectTickAction] != 3 || remaining[EffectTickRound] != 4 {
+		t.Errorf("Durations left after one round = %v, want 3 per action and 4 per round", remaining)
+	}
+}
+
+func TestBattle_RuleMaxRounds(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 1000)
+	char2 := createTestCharacter("Mage", 1000)
+	battle := newBattle(char1, char2, WithRules(RuleSet{MaxRounds: 3}),
+		WithAI(char1, GreedyStrategy{}), WithAI(char2, GreedyStrategy{}))
+
+	if err := battle.Play(100); err != nil {
+		t.Fatalf("Play() error = %v", err)
+	}
+	if battle.State != BattleStateComplete || battle.Winner != nil || battle.Round != 3 {
+		t.Errorf("Expected a draw after round 3, got %s, winner %v, round %d", battle.State, battle.Winner, battle.Round)
+	}
+	if len(battle.History()) != 6 {
+		t.Errorf("Expected 6 actions in 3 rounds, got %d", len(battle.History()))
+	}
+}
+
+func TestBattle_RuleSelfTargetAndCooldowns(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char2 := createTestCharacter("Mage", 100)
+	battle := newBattle(char1, char2, WithRules(RuleSet{StartingCooldowns: true}))
+	activate(battle)
+	battle.applyStartingCooldowns()
+
+	if char1.Abilities[1].Cooldown != 2 || char2.Abilities[1].Cooldown != 2 {
+		t.Error("Expected abilities to start on cooldown")
+	}
+	if result := battle.processAction(BattleAction{CharacterID: char1.ID, AbilityIndex: 0, TargetID: char1.ID}); result.Success {
+		t.Error("Expected self-targeting to be rejected")
+	}
+	for _, action := range battle.legalActions() {
+		if action.Kind == "" && action.TargetID == action.CharacterID {
+			t.Errorf("legalActions() offers self-targeting: %+v", action)
+		}
+	}
+}
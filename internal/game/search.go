@@ -0,0 +1,467 @@
+package game
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+// SearchBudget bounds how much work a search strategy does per move. The
+// search stops at whichever limit it reaches first; zero means no limit, but
+// at least one of the two should be set.
+type SearchBudget struct {
+	MaxNodes    int
+	MaxDuration time.Duration
+}
+
+// DefaultSearchBudget keeps a move under a fifth of a second
+var DefaultSearchBudget = SearchBudget{MaxNodes: 50000, MaxDuration: 200 * time.Millisecond}
+
+// budgetClock tracks a search against its budget
+type budgetClock struct {
+	budget   SearchBudget
+	deadline time.Time
+	nodes    int
+}
+
+func newBudgetClock(budget SearchBudget) *budgetClock {
+	c := &budgetClock{budget: budget}
+	if budget.MaxDuration > 0 {
+		c.deadline = time.Now().Add(budget.MaxDuration)
+	}
+	return c
+}
+
+// spend counts one node and reports whether the budget still allows more
+func (c *budgetClock) spend() bool {
+	c.nodes++
+	if c.budget.MaxNodes > 0 && c.nodes > c.budget.MaxNodes {
+		return false
+	}
+	// Reading the clock is comparatively slow, so only do it now and then
+	if !c.deadline.IsZero() && c.nodes%64 == 0 && time.Now().After(c.deadline) {
+		return false
+	}
+	return true
+}
+
+// simulationCopy returns an active copy of the battle with its own characters
+// and no loop, which can be played forward with applyAction. The copy moves
+// whoever the battle has to move; a search deciding for someone else must
+// moveAs them.
+func (b *Battle) simulationCopy() *Battle {
+	char1, char2 := b.Character1.Clone(), b.Character2.Clone()
+	sim := &Battle{
+		ID:         b.ID,
+		Character1: &char1,
+		Character2: &char2,
+		State:      b.State,
+		Round:      b.Round,
+		Rules:      b.Rules,
+		TurnMode:   TurnModeSequential,
+		turnIndex:  b.turnIndex,
+	}
+	sim.Rules.TurnMode = TurnModeSequential
+	if sim.State == BattleStatePaused {
+		sim.State = BattleStateActive
+	}
+
+	copies := map[*Character]*Character{b.Character1: sim.Character1, b.Character2: sim.Character2}
+	for _, r := range b.Reserves1 {
+		c := r.Clone()
+		copies[r] = &c
+		sim.Reserves1 = append(sim.Reserves1, &c)
+	}
+	for _, r := range b.Reserves2 {
+		c := r.Clone()
+		copies[r] = &c
+		sim.Reserves2 = append(sim.Reserves2, &c)
+	}
+	for _, m := range b.Minions {
+		c := m.Character.Clone()
+		copies[m.Character] = &c
+		minion := *m
+		minion.Character = &c
+		sim.Minions = append(sim.Minions, &minion)
+	}
+	sim.summoned = b.summoned
+	for _, c := range b.turnOrder {
+		// Minions dismissed this round still hold their place
+		if copies[c] == nil {
+			gone := c.Clone()
+			copies[c] = &gone
+		}
+		sim.turnOrder = append(sim.turnOrder, copies[c])
+	}
+	if b.Winner != nil {
+		sim.Winner = copies[b.Winner]
+	}
+	if b.Fled != nil {
+		sim.Fled = copies[b.Fled]
+	}
+	if b.Environment != nil {
+		environment := *b.Environment
+		sim.Environment = &environment
+	}
+	if b.Grid != nil {
+		sim.Grid = b.Grid.clone()
+	}
+	sim.Pending = append([]PendingAbility(nil), b.Pending...)
+	// Combos look back no further than MaxComboLength actions per character
+	if start := len(b.history) - 2*MaxComboLength; start > 0 {
+		sim.history = append([]ActionRecord(nil), b.history[start:]...)
+	} else {
+		sim.history = append([]ActionRecord(nil), b.history...)
+	}
+	for id := range b.waited {
+		if sim.waited == nil {
+			sim.waited = make(map[string]bool)
+		}
+		sim.waited[id] = true
+	}
+	return sim
+}
+
+// simulation returns a battle to search from. Views built by a running battle
+// carry an exact copy; hand-made views are rebuilt with Self to move.
+func (v BattleView) simulation() *Battle {
+	if v.sim != nil {
+		sim := v.sim.simulationCopy()
+		sim.moveAs(v.Self.ID)
+		return sim
+	}
+
+	self := v.Self.Clone()
+	sim := &Battle{
+		Character1: &self,
+		State:      BattleStateActive,
+		Round:      v.Round,
+		Rules:      ClassicRules,
+		TurnMode:   TurnModeSequential,
+	}
+	sim.Rules.TurnMode = TurnModeSequential
+	if len(v.Opponents) > 0 {
+		opponent := v.Opponents[0].Clone()
+		sim.Character2 = &opponent
+	} else {
+		sim.Character2 = &Character{}
+	}
+	sim.turnOrder = sim.initiativeOrder()
+	sim.moveAs(self.ID)
+	return sim
+}
+
+// moveAs makes the character with the given ID the one to move, whoever the
+// copied battle had to move, and gives it a place at the end of the turn
+// order if it had none
+func (b *Battle) moveAs(id string) {
+	c := b.characterByID(id)
+	if c == nil {
+		return
+	}
+	if i := slices.Index(b.turnOrder, c); i >= 0 {
+		b.turnIndex = i
+		return
+	}
+	b.turnOrder = append(b.turnOrder, c)
+	b.turnIndex = len(b.turnOrder) - 1
+}
+
+// legalActions lists every ready ability and usable consumable of the
+// character to move against every target it may be used on
+func (b *Battle) legalActions() []BattleAction {
+	actor := b.currentTurn()
+	if actor == nil || b.State != BattleStateActive || b.locked(actor) != "" {
+		return nil
+	}
+	var actions []BattleAction
+	for _, i := range readyAbilities(*actor) {
+		for _, target := range b.combatants() {
+			if target == actor && !b.Rules.AllowSelfTarget {
+				continue
+			}
+			if !b.unlocked(actor, target, actor.Abilities[i]) || b.outOfReach(actor, target, actor.Abilities[i]) != "" {
+				continue
+			}
+			actions = append(actions, BattleAction{
+				CharacterID:  actor.ID,
+				AbilityIndex: i,
+				TargetID:     target.ID,
+			})
+		}
+	}
+	for i, item := range actor.Inventory {
+		if item.Quantity <= 0 {
+			continue
+		}
+		for _, target := range b.combatants() {
+			if (item.Target == ItemTargetSelf && target != actor) || (item.Target == ItemTargetEnemy && target == actor) {
+				continue
+			}
+			actions = append(actions, BattleAction{
+				Kind:        ActionItem,
+				CharacterID: actor.ID,
+				ItemIndex:   i,
+				TargetID:    target.ID,
+			})
+		}
+	}
+	return actions
+}
+
+// playMove applies action, or passes the turn if action is nil
+func (b *Battle) playMove(action *BattleAction) {
+	if action == nil {
+		b.endTurn(b.currentTurn(), -1)
+		return
+	}
+	b.applyAction(*action)
+}
+
+// winScore outweighs any difference in health
+const winScore = 1e6
+
+// evaluate scores the battle for the character with ID self: a win or loss
+// for its side is decisive, otherwise the health lead of its side counts
+func (b *Battle) evaluate(self string) float64 {
+	side := b.sideOf(self)
+	if b.State == BattleStateComplete {
+		switch {
+		case b.Winner == nil:
+			return 0
+		case b.sideOf(b.Winner.ID) == side:
+			return winScore
+		default:
+			return -winScore
+		}
+	}
+
+	score := 0.0
+	for _, c := range b.combatants() {
+		if b.sideOf(c.ID) == side {
+			score += float64(c.Health)
+		} else {
+			score -= float64(c.Health)
+		}
+	}
+	return score
+}
+
+// MinimaxStrategy searches the game tree with alpha-beta pruning, deepening
+// one turn at a time until its budget runs out, and plays the best move of
+// the deepest search it finished. Opponents are assumed to play perfectly.
+type MinimaxStrategy struct {
+	Budget SearchBudget
+	// MaxDepth caps the search depth in turns. Zero means no cap.
+	MaxDepth int
+}
+
+func (s MinimaxStrategy) ChooseAction(view BattleView) (BattleAction, bool) {
+	root := view.simulation()
+	actions := root.legalActions()
+	if len(actions) == 0 {
+		return BattleAction{}, false
+	}
+
+	clock := newBudgetClock(s.Budget)
+	best := actions[0]
+	for depth := 1; s.MaxDepth == 0 || depth <= s.MaxDepth; depth++ {
+		move, score, complete := s.searchRoot(root, actions, depth, view.Self.ID, clock)
+		if !complete {
+			break
+		}
+		best = move
+		// A forced win or loss will not change with more depth
+		if math.Abs(score) >= winScore/2 {
+			break
+		}
+	}
+	return best, true
+}
+
+// searchRoot runs one depth-limited search and reports whether it finished
+// within budget
+func (s MinimaxStrategy) searchRoot(root *Battle, actions []BattleAction, depth int, self string, clock *budgetClock) (BattleAction, float64, bool) {
+	best, bestScore := actions[0], math.Inf(-1)
+	alpha, beta := math.Inf(-1), math.Inf(1)
+	for _, action := range actions {
+		child := root.simulationCopy()
+		child.playMove(&action)
+		score, ok := s.alphaBeta(child, depth-1, alpha, beta, self, clock)
+		if !ok {
+			return BattleAction{}, 0, false
+		}
+		if score > bestScore {
+			best, bestScore = action, score
+		}
+		alpha = math.Max(alpha, bestScore)
+	}
+	return best, bestScore, true
+}
+
+func (s MinimaxStrategy) alphaBeta(b *Battle, depth int, alpha, beta float64, self string, clock *budgetClock) (float64, bool) {
+	if !clock.spend() {
+		return 0, false
+	}
+	if depth == 0 || b.State != BattleStateActive {
+		return b.evaluate(self), true
+	}
+
+	// Allies, minions included, play for self; everyone else against
+	maximising := b.sideOf(b.currentTurn().ID) == b.sideOf(self)
+	actions := b.legalActions()
+	if len(actions) == 0 {
+		child := b.simulationCopy()
+		child.playMove(nil)
+		return s.alphaBeta(child, depth-1, alpha, beta, self, clock)
+	}
+
+	best := math.Inf(1)
+	if maximising {
+		best = math.Inf(-1)
+	}
+	for _, action := range actions {
+		child := b.simulationCopy()
+		child.playMove(&action)
+		score, ok := s.alphaBeta(child, depth-1, alpha, beta, self, clock)
+		if !ok {
+			return 0, false
+		}
+		if maximising {
+			best = math.Max(best, score)
+			alpha = math.Max(alpha, best)
+		} else {
+			best = math.Min(best, score)
+			beta = math.Min(beta, best)
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+	return best, true
+}
+
+// MCTSStrategy runs Monte Carlo tree search: it grows a tree of moves by
+// playing random games to the end from promising positions and picks the
+// move it explored most.
+type MCTSStrategy struct {
+	Budget SearchBudget
+	// MaxPlayoutTurns stops a random playout that drags on. Unfinished
+	// playouts are judged by their health lead.
+	MaxPlayoutTurns int
+	rng             *rand.Rand
+}
+
+// NewMCTSStrategy returns an MCTS strategy using rng for its playouts. nil
+// seeds a fresh generator.
+func NewMCTSStrategy(budget SearchBudget, rng *rand.Rand) *MCTSStrategy {
+	if rng == nil {
+		rng = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	}
+	return &MCTSStrategy{Budget: budget, MaxPlayoutTurns: 100, rng: rng}
+}
+
+// mctsNode is a position in the search tree, reached by action
+type mctsNode struct {
+	action   *BattleAction
+	mover    int // Side of the character that played action
+	parent   *mctsNode
+	children []*mctsNode
+	untried  []BattleAction
+	visits   float64
+	reward   float64 // Sum of playout rewards for mover's side
+}
+
+// explorationWeight is the usual UCT constant, sqrt(2)
+var explorationWeight = math.Sqrt2
+
+func (s *MCTSStrategy) ChooseAction(view BattleView) (BattleAction, bool) {
+	root := view.simulation()
+	actions := root.legalActions()
+	if len(actions) == 0 {
+		return BattleAction{}, false
+	}
+	if len(actions) == 1 {
+		return actions[0], true
+	}
+
+	side := root.sideOf(view.Self.ID)
+	tree := &mctsNode{untried: actions}
+	clock := newBudgetClock(s.Budget)
+	for clock.spend() {
+		node, state := tree, root.simulationCopy()
+
+		// Selection: follow the most promising fully expanded nodes
+		for len(node.untried) == 0 && len(node.children) > 0 {
+			node = node.bestChild()
+			state.playMove(node.action)
+		}
+
+		// Expansion: try one move not yet in the tree
+		if len(node.untried) > 0 && state.State == BattleStateActive {
+			i := s.rng.IntN(len(node.untried))
+			action := node.untried[i]
+			node.untried = append(node.untried[:i], node.untried[i+1:]...)
+
+			mover := state.sideOf(state.currentTurn().ID)
+			state.playMove(&action)
+			child := &mctsNode{action: &action, mover: mover, parent: node, untried: state.legalActions()}
+			node.children = append(node.children, child)
+			node = child
+		}
+
+		// Simulation and backpropagation
+		reward := s.playout(state, view.Self.ID)
+		for ; node != nil; node = node.parent {
+			node.visits++
+			if node.mover == side {
+				node.reward += reward
+			} else {
+				node.reward += 1 - reward
+			}
+		}
+	}
+
+	best := tree.children[0]
+	for _, child := range tree.children[1:] {
+		if child.visits > best.visits {
+			best = child
+		}
+	}
+	return *best.action, true
+}
+
+// bestChild picks the child with the highest upper confidence bound
+func (n *mctsNode) bestChild() *mctsNode {
+	var best *mctsNode
+	bestScore := math.Inf(-1)
+	for _, child := range n.children {
+		score := child.reward/child.visits + explorationWeight*math.Sqrt(math.Log(n.visits)/child.visits)
+		if score > bestScore {
+			best, bestScore = child, score
+		}
+	}
+	return best
+}
+
+// playout plays random moves until the battle ends and returns the reward for
+// self between 0 (loss) and 1 (win)
+func (s *MCTSStrategy) playout(b *Battle, self string) float64 {
+	for turn := 0; turn < s.MaxPlayoutTurns && b.State == BattleStateActive; turn++ {
+		actions := b.legalActions()
+		if len(actions) == 0 {
+			b.playMove(nil)
+			continue
+		}
+		b.playMove(&actions[s.rng.IntN(len(actions))])
+	}
+
+	score := b.evaluate(self)
+	if b.State == BattleStateComplete {
+		return (score/winScore + 1) / 2
+	}
+
+	// Squash an unfinished battle's health lead into (0, 1)
+	return 1 / (1 + math.Exp(-score/50))
+}
@@ -0,0 +1,135 @@
+package game
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+	"time"
+)
+
+func TestSearchStrategies_FindFinishingMove(t *testing.T) {
+	view := createTestView()
+	// Only the Special Attack (20 + 10 - 5 = 25) finishes the opponent
+	view.Opponents[0].Health = 25
+
+	strategies := map[string]Strategy{
+		"minimax": MinimaxStrategy{Budget: SearchBudget{MaxNodes: 5000}},
+		"mcts":    NewMCTSStrategy(SearchBudget{MaxNodes: 5000}, rand.New(rand.NewPCG(1, 2))),
+	}
+	for name, strategy := range strategies {
+		t.Run(name, func(t *testing.T) {
+			action, ok := strategy.ChooseAction(view)
+			if !ok {
+				t.Fatal("Expected strategy to choose an action")
+			}
+			if action.AbilityIndex != 1 || action.TargetID != view.Opponents[0].ID {
+				t.Errorf("Expected Special Attack on the opponent, got %+v", action)
+			}
+		})
+	}
+}
+
+func TestSearchStrategies_LeaveBattleUntouched(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char2 := createTestCharacter("Mage", 80)
+	battle := newBattle(char1, char2)
+	battle.State = BattleStateActive
+	battle.turnOrder = battle.initiativeOrder()
+
+	view := battle.viewFor(char1)
+	MinimaxStrategy{Budget: SearchBudget{MaxNodes: 2000}}.ChooseAction(view)
+	NewMCTSStrategy(SearchBudget{MaxNodes: 2000}, nil).ChooseAction(view)
+
+	if char1.Health != 100 || char2.Health != 80 || battle.Round != 1 {
+		t.Error("Expected search to leave the real battle unchanged")
+	}
+	if char2.Abilities[1].Cooldown != 0 || len(char2.StatusEffects) != 0 {
+		t.Error("Expected search to leave the real characters unchanged")
+	}
+}
+
+func TestSearchStrategies_MoveAsSelf(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char2 := createTestCharacter("Mage", 80)
+	char2.Speed = 20
+	battle := newBattle(char1, char2)
+	battle.State = BattleStateActive
+	battle.turnOrder = battle.initiativeOrder()
+
+	// The Mage moves first in the live battle, but the search is for the Warrior
+	view := battle.viewFor(char1)
+	for name, strategy := range map[string]Strategy{
+		"minimax": MinimaxStrategy{Budget: SearchBudget{MaxNodes: 2000}},
+		"mcts":    NewMCTSStrategy(SearchBudget{MaxNodes: 2000}, rand.New(rand.NewPCG(1, 2))),
+	} {
+		action, ok := strategy.ChooseAction(view)
+		if !ok || action.CharacterID != char1.ID {
+			t.Errorf("%s: Expected a move for the Warrior, got %+v", name, action)
+		}
+	}
+}
+
+func TestMinimax_AlliesPlayForSelf(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char1.Speed = 20
+	withSummon(char1, 30, 0)
+	char2 := createTestCharacter("Mage", 100)
+	battle := newBattle(char1, char2, WithTurnMode(TurnModeSequential))
+	activate(battle)
+	battle.processAction(BattleAction{CharacterID: char1.ID, AbilityIndex: 2, TargetID: char2.ID})
+	battle.processAction(BattleAction{CharacterID: char2.ID, AbilityIndex: 0, TargetID: char1.ID})
+
+	// The Warrior's Wolf is to move, and is expected to help its summoner
+	sim := battle.simulationCopy()
+	if sim.currentTurn().Name != "Wolf" {
+		t.Fatalf("Expected the Wolf to move, got %s", sim.currentTurn().Name)
+	}
+	before := sim.evaluate(char1.ID)
+	score, ok := MinimaxStrategy{}.alphaBeta(sim, 1, math.Inf(-1), math.Inf(1), char1.ID, newBudgetClock(SearchBudget{MaxNodes: 1000}))
+	if !ok || score <= before {
+		t.Errorf("Expected the Wolf's best move to raise the Warrior's score above %v, got %v", before, score)
+	}
+}
+
+func TestSearchStrategies_RespectBudget(t *testing.T) {
+	view := createTestView()
+	budget := SearchBudget{MaxDuration: 20 * time.Millisecond}
+
+	for name, strategy := range map[string]Strategy{
+		"minimax": MinimaxStrategy{Budget: budget},
+		"mcts":    NewMCTSStrategy(budget, nil),
+	} {
+		start := time.Now()
+		strategy.ChooseAction(view)
+		if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
+			t.Errorf("%s: took %v with a %v budget", name, elapsed, budget.MaxDuration)
+		}
+	}
+}
+
+func TestSearchStrategies_BeatRandom(t *testing.T) {
+	budget := SearchBudget{MaxNodes: 3000}
+	for name, search := range map[string]Strategy{
+		"minimax": MinimaxStrategy{Budget: budget},
+		"mcts":    NewMCTSStrategy(budget, rand.New(rand.NewPCG(3, 4))),
+	} {
+		t.Run(name, func(t *testing.T) {
+			wins := 0
+			for game := 0; game < 10; game++ {
+				random, _ := NewStrategy("random", rand.New(rand.NewPCG(uint64(game), 0)))
+				char1 := createTestCharacter("Searcher", 100)
+				char2 := createTestCharacter("Random", 100)
+				battle := newBattle(char1, char2, WithAI(char1, search), WithAI(char2, random))
+				if err := battle.Play(200); err != nil {
+					t.Fatalf("Play() error = %v", err)
+				}
+				if battle.Winner == char1 {
+					wins++
+				}
+			}
+			if wins < 8 {
+				t.Errorf("Expected search to beat random play, won %d of 10", wins)
+			}
+		})
+	}
+}
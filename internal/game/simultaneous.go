@@ -0,0 +1,252 @@
+package game
+
+import (
+	"context"
+	"fmt"
+)
+
+// TurnModeSimultaneous has every character seal an action for the round
+// before any of them resolves. The round then plays out in initiative
+// order, so nobody sees what the others chose until it is too late.
+const TurnModeSimultaneous TurnMode = "SIMULTANEOUS"
+
+// sealedAction is an action waiting for the round to resolve. A nil action
+// passes.
+type sealedAction struct {
+	action *BattleAction
+	// side is 1 or 2 for the side of the intended target, so that the
+	// action can follow the target's replacement if the target falls
+	side int
+}
+
+// ResolvedAction is how one character's sealed action played out
+type ResolvedAction struct {
+	CharacterID string
+	Result      BattleActionResult
+}
+
+// Sealed returns the IDs of the characters that have sealed an action for
+// the current round, in the order they joined the battle
+func (b *Battle) Sealed() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.sealedIDs()
+}
+
+// sealedIDs is Sealed for callers that hold b.mu
+func (b *Battle) sealedIDs() []string {
+	var ids []string
+	for _, c := range b.combatants() {
+		if _, ok := b.sealed[c.ID]; ok {
+			ids = append(ids, c.ID)
+		}
+	}
+	return ids
+}
+
+// seal holds the actor's action until every standing character has sealed
+// one, then resolves the round. Actions are checked when sealed, and again
+// when they resolve since the battle may have changed by then.
+func (b *Battle) seal(actor *Character, action BattleAction) BattleActionResult {
+	if _, ok := b.sealed[actor.ID]; ok {
+		return b.reject("already sealed an action this round")
+	}
+
+	side := 0
+	switch action.Kind {
+	case "", ActionAbility, ActionItem:
+		target := b.characterByID(action.TargetID)
+		if target == nil {
+			return b.reject("invalid target ID")
+		}
+		// Minions are not replaced when they fall, so actions aimed at them
+		// stay with them
+		switch target {
+		case b.Character1:
+			side = 1
+		case b.Character2:
+			side = 2
+		}
+		if action.Kind == ActionItem {
+			if action.ItemIndex < 0 || action.ItemIndex >= len(actor.Inventory) {
+				return b.reject("invalid item index")
+			}
+		} else {
+			if action.AbilityIndex < 0 || action.AbilityIndex >= len(actor.Abilities) {
+				return b.reject("invalid ability index")
+			}
+			if !actor.Abilities[action.AbilityIndex].CanUse() {
+				return b.reject("ability is on cooldown")
+			}
+			if target == actor && !b.Rules.AllowSelfTarget {
+				return b.reject("abilities cannot target their user under these rules")
+			}
+			if reason := b.outOfReach(actor, target, actor.Abilities[action.AbilityIndex]); reason != "" {
+				return b.reject(reason)
+			}
+		}
+	case ActionMove:
+		if b.Grid == nil || action.To == nil {
+			return b.reject("a move needs a grid and a To position")
+		}
+	case ActionWait:
+		return b.reject("cannot wait when turns are simultaneous")
+	case ActionDefend, ActionSwap, ActionFlee:
+	default:
+		return b.reject(fmt.Sprintf("unknown action kind %q", action.Kind))
+	}
+
+	action.ResponseChan = nil
+	b.sealAction(actor, sealedAction{action: &action, side: side})
+
+	result := BattleActionResult{
+		Success: true,
+		Message: fmt.Sprintf("%s sealed an action", actor.Name),
+		Battle:  b,
+		Sealed:  true,
+	}
+	if b.allSealed() {
+		result.Resolution = b.resolveRound()
+	}
+	return result
+}
+
+func (b *Battle) sealAction(actor *Character, sealed sealedAction) {
+	if b.sealed == nil {
+		b.sealed = make(map[string]sealedAction)
+	}
+	b.sealed[actor.ID] = sealed
+}
+
+// allSealed reports whether every standing character has sealed an action.
+// Characters who cannot act pass.
+func (b *Battle) allSealed() bool {
+	return len(b.unsealed()) == 0
+}
+
+// resolveRound plays out the sealed actions by priority bracket, then in
+// initiative order. Characters
+// that sealed nothing, such as when the turn timer runs out or they cannot
+// act, pass. An action
+// aimed at a character that has fallen goes to whoever now stands in its
+// place, or fails if nobody does.
+func (b *Battle) resolveRound() []ResolvedAction {
+	sealed := b.sealed
+	b.sealed = nil
+	b.resolving = true
+	defer func() { b.resolving = false }()
+
+	var resolution []ResolvedAction
+	round := b.Round
+	b.turnOrder = b.resolutionOrder(sealed)
+	b.turnIndex = 0
+	for b.State == BattleStateActive && b.Round == round {
+		actor := b.currentTurn()
+		entry, ok := sealed[actor.ID]
+		if !ok || entry.action == nil {
+			b.endTurn(actor, -1)
+			resolution = append(resolution, ResolvedAction{
+				CharacterID: actor.ID,
+				Result:      BattleActionResult{Success: true, Message: fmt.Sprintf("%s passes", actor.Name), Battle: b},
+			})
+			continue
+		}
+
+		action := *entry.action
+		var result BattleActionResult
+		if entry.side != 0 {
+			target := b.Character1
+			if entry.side == 2 {
+				target = b.Character2
+			}
+			if target.Health <= 0 {
+				result = b.reject("target has already fallen")
+			} else {
+				action.TargetID = target.ID
+			}
+		}
+		if result.Message == "" {
+			result = b.applyAction(action)
+		}
+		if !result.Success && b.State == BattleStateActive && b.currentTurn() == actor {
+			b.endTurn(actor, -1)
+		}
+		resolution = append(resolution, ResolvedAction{CharacterID: actor.ID, Result: result})
+	}
+	// Nobody may be able to act in the next round either, such as when
+	// everyone is charging
+	if b.State == BattleStateActive && b.allSealed() {
+		resolution = append(resolution, b.resolveRound()...)
+	}
+	return resolution
+}
+
+// pass seals a pass for the actor, resolving the round if it was the last
+// to choose
+func (b *Battle) pass(actor *Character) {
+	b.sealAction(actor, sealedAction{})
+	if b.allSealed() {
+		b.resolveRound()
+	}
+}
+
+// unsealed returns the standing characters able to act that have yet to seal
+// an action this round
+func (b *Battle) unsealed() []*Character {
+	var pending []*Character
+	for _, c := range b.combatants() {
+		if _, ok := b.sealed[c.ID]; !ok && c.Health > 0 && b.locked(c) == "" {
+			pending = append(pending, c)
+		}
+	}
+	return pending
+}
+
+// sealAIActions has every computer-controlled character that has not yet
+// sealed an action this round choose one
+func (b *Battle) sealAIActions(ctx context.Context) {
+	b.mu.Lock()
+	if b.State != BattleStateActive {
+		b.mu.Unlock()
+		return
+	}
+	round := b.Round
+	var actors []*Character
+	var strategies []Strategy
+	var views []BattleView
+	for _, c := range b.unsealed() {
+		if strategy, ok := b.controllers[c]; ok {
+			actors = append(actors, c)
+			strategies = append(strategies, strategy)
+			views = append(views, b.viewFor(c))
+		}
+	}
+	b.mu.Unlock()
+
+	// Strategies may take a while, so choose without holding the lock
+	actions := make([]BattleAction, len(actors))
+	chosen := make([]bool, len(actors))
+	for i, strategy := range strategies {
+		if ctx.Err() != nil {
+			return
+		}
+		actions[i], chosen[i] = strategy.ChooseAction(views[i])
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, actor := range actors {
+		// Sealing the last action resolves the round
+		if b.State != BattleStateActive || b.Round != round {
+			return
+		}
+		if _, ok := b.sealed[actor.ID]; ok || b.characterByID(actor.ID) == nil {
+			continue
+		}
+		// A strategy with nothing to do, or that picked an illegal move or
+		// one for somebody else, moves closer on a grid or passes
+		if (!chosen[i] || actions[i].CharacterID != actor.ID || !b.applyAction(actions[i]).Success) && !b.advance(actor) {
+			b.pass(actor)
+		}
+	}
+}
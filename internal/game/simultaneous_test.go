@@ -0,0 +1,155 @@
+package game
+
+import (
+	"context"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/MaterDev/golang_turnbased_game_spike/internal/formula"
+)
+
+func TestBattle_Simultaneous(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char2 := createTestCharacter("Mage", 100)
+	char2.Speed = 20
+	battle := newBattle(char1, char2, WithTurnMode(TurnModeSimultaneous))
+	activate(battle)
+
+	result := battle.processAction(BattleAction{CharacterID: char1.ID, AbilityIndex: 0, TargetID: char2.ID})
+	if !result.Success || !result.Sealed || result.Resolution != nil {
+		t.Fatalf("First action = %+v, want it sealed without resolving", result)
+	}
+	if char2.Health != 100 || len(battle.History()) != 0 {
+		t.Error("Nothing should resolve until every action is in")
+	}
+	if got := battle.Sealed(); len(got) != 1 || got[0] != char1.ID {
+		t.Errorf("Sealed() = %v, want only Warrior", got)
+	}
+	if result := battle.processAction(BattleAction{Kind: ActionDefend, CharacterID: char1.ID}); result.Success {
+		t.Error("Expected a second action in the same round to be rejected")
+	}
+	if result := battle.processAction(BattleAction{Kind: ActionWait, CharacterID: char2.ID}); result.Success {
+		t.Error("Expected waiting to be rejected")
+	}
+
+	result = battle.processAction(BattleAction{CharacterID: char2.ID, AbilityIndex: 0, TargetID: char1.ID})
+	if !result.Success || len(result.Resolution) != 2 {
+		t.Fatalf("Last action = %+v, want the round resolved", result)
+	}
+	if result.Resolution[0].CharacterID != char2.ID || result.Resolution[1].CharacterID != char1.ID {
+		t.Errorf("Resolution should follow Speed, got %s then %s",
+			result.Resolution[0].CharacterID, result.Resolution[1].CharacterID)
+	}
+	if char1.Health == 100 || char2.Health == 100 {
+		t.Error("Both attacks should have landed")
+	}
+	if battle.Round != 2 || len(battle.Sealed()) != 0 {
+		t.Errorf("Round = %d, want a fresh round 2", battle.Round)
+	}
+}
+
+func TestBattle_SimultaneousRetargets(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char2 := createTestCharacter("Mage", 1)
+	char2.Speed = 20
+	burn, err := formula.Parse("10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	char2.StatusEffects = []StatusEffectData{{Type: StatusBurning, Duration: 2, Formula: burn}}
+	reserve := createTestCharacter("Healer", 50)
+	battle := newBattle(char1, char2, WithTurnMode(TurnModeSimultaneous), WithReserves(2, reserve))
+	activate(battle)
+
+	// The Mage burns to death after defending, before the Warrior's attack lands
+	battle.processAction(BattleAction{CharacterID: char1.ID, AbilityIndex: 0, TargetID: char2.ID})
+	result := battle.processAction(BattleAction{Kind: ActionDefend, CharacterID: char2.ID})
+	if len(result.Resolution) != 2 {
+		t.Fatalf("Resolution = %+v", result.Resolution)
+	}
+	if battle.Character2 != reserve {
+		t.Fatal("Expected Healer to replace the fallen Mage")
+	}
+	if !result.Resolution[1].Result.Success || reserve.Health == 50 {
+		t.Errorf("The Warrior's attack should land on the Healer, got %+v", result.Resolution[1].Result)
+	}
+}
+
+func TestBattle_SimultaneousTimeout(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char2 := createTestCharacter("Mage", 100)
+	battle := newBattle(char1, char2, WithTurnMode(TurnModeSimultaneous), WithTurnTimeout(50*time.Millisecond))
+	if err := battle.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start battle: %v", err)
+	}
+	defer battle.Stop()
+
+	if _, err := battle.SubmitAction(context.Background(), BattleAction{CharacterID: char1.ID, AbilityIndex: 0, TargetID: char2.ID}); err != nil {
+		t.Fatalf("SubmitAction() error = %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		battle.mu.Lock()
+		round := battle.Round
+		battle.mu.Unlock()
+		if round > 1 {
+			if char2.Health == 100 {
+				t.Error("The sealed attack should resolve when time runs out")
+			}
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("Round did not resolve after the turn timer ran out")
+}
+
+func TestBattle_SimultaneousAI(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char2 := createTestCharacter("Mage", 100)
+	battle := newBattle(char1, char2, WithTurnMode(TurnModeSimultaneous), WithAI(char2, &GreedyStrategy{}))
+	if err := battle.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start battle: %v", err)
+	}
+	defer battle.Stop()
+
+	result, err := battle.SubmitAction(context.Background(), BattleAction{CharacterID: char1.ID, AbilityIndex: 0, TargetID: char2.ID})
+	if err != nil || !result.Success {
+		t.Fatalf("SubmitAction() = %+v, %v", result, err)
+	}
+	if len(result.Resolution) != 2 {
+		t.Errorf("Expected the computer to have sealed its action already, got %+v", result.Resolution)
+	}
+}
+
+// hijackStrategy plays for whoever it is given instead of its own character
+type hijackStrategy struct{ victim *Character }
+
+func (s hijackStrategy) ChooseAction(view BattleView) (BattleAction, bool) {
+	return BattleAction{CharacterID: s.victim.ID, AbilityIndex: 1, TargetID: s.victim.ID}, true
+}
+
+func TestBattle_SimultaneousAISealsOwnMove(t *testing.T) {
+	strategies := map[string]func(human *Character) Strategy{
+		"minimax": func(*Character) Strategy { return MinimaxStrategy{Budget: SearchBudget{MaxNodes: 2000}} },
+		"mcts": func(*Character) Strategy {
+			return NewMCTSStrategy(SearchBudget{MaxNodes: 2000}, rand.New(rand.NewPCG(1, 2)))
+		},
+		"hijack": func(human *Character) Strategy { return hijackStrategy{human} },
+	}
+	for name, strategy := range strategies {
+		t.Run(name, func(t *testing.T) {
+			human := createTestCharacter("Warrior", 100)
+			human.Speed = 20
+			bot := createTestCharacter("Mage", 100)
+			battle := newBattle(human, bot, WithTurnMode(TurnModeSimultaneous), WithAI(bot, strategy(human)))
+			activate(battle)
+
+			battle.sealAIActions(context.Background())
+			if sealed := battle.Sealed(); len(sealed) != 1 || sealed[0] != bot.ID {
+				t.Errorf("Sealed() = %v, want only the bot", sealed)
+			}
+		})
+	}
+}
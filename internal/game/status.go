@@ -1,5 +1,7 @@
 package game
 
+import "github.com/MaterDev/golang_turnbased_game_spike/internal/formula"
+
 // Status effects are strings and there are many types
 type StatusEffect string
 
@@ -9,6 +11,7 @@ const (
 	StatusPoisoned     StatusEffect = "POISON"     // Damage over time, damage decreases by some formula that uses the duration each time.
 	StatusEnraged      StatusEffect = "ENRAGED"    // Each round increase Attack power by percentage based on potency.
 	StatusRegenerating StatusEffect = "REGENERATING"
+	StatusStunned      StatusEffect = "STUNNED" // Cannot act, and any charge or channel is broken off.
 )
 
 // EVery effect will hold various attributes
@@ -16,4 +19,57 @@ type StatusEffectData struct {
 	Type     StatusEffect `json:"Type"`
 	Duration int         `json:"Duration"` // Number of remaining turns
 	Potency  int         `json:"Potency"`  // The strength of the effect
+	// Formula, when set, gives the amount the effect deals, heals or adds
+	// each round in place of the built-in scaling
+	Formula *formula.Expr `json:"Formula,omitempty"`
+}
+
+// KnownStatusEffects lists every status effect the engine knows how to process
+var KnownStatusEffects = []StatusEffect{
+	StatusAccelerate,
+	StatusBurning,
+	StatusPoisoned,
+	StatusEnraged,
+	StatusRegenerating,
+	StatusStunned,
+}
+
+// crowdControl lists the effects that stop a character acting. Resistance
+// shortens them, and once one wears off the character is briefly immune.
+var crowdControl = []StatusEffect{StatusStunned}
+
+// crowdControlImmunity is how many ticks a character is immune to crowd
+// control that has just worn off
+const crowdControlImmunity = 3
+
+// IsCrowdControl reports whether the effect stops a character acting
+func (s StatusEffect) IsCrowdControl() bool {
+	for _, cc := range crowdControl {
+		if s == cc {
+			return true
+		}
+	}
+	return false
+}
+
+// EffectOutcome says what became of a status effect aimed at a character
+type EffectOutcome string
+
+const (
+	// EffectApplied landed in full
+	EffectApplied EffectOutcome = "APPLIED"
+	// EffectResisted landed weakened, or shortened for crowd control
+	EffectResisted EffectOutcome = "RESISTED"
+	// EffectImmune did not land at all
+	EffectImmune EffectOutcome = "IMMUNE"
+)
+
+// IsKnown reports whether the engine knows how to process the effect
+func (s StatusEffect) IsKnown() bool {
+	for _, known := range KnownStatusEffects {
+		if s == known {
+			return true
+		}
+	}
+	return false
 }
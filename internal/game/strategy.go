@@ -0,0 +1,236 @@
+package game
+
+import (
+	"fmt"
+	"math/rand/v2"
+)
+
+// Strategy decides what a computer-controlled character does on its turn
+type Strategy interface {
+	// ChooseAction picks the next action for view.Self. It returns false if
+	// the character has nothing it can do and should pass the turn.
+	ChooseAction(view BattleView) (BattleAction, bool)
+}
+
+// Difficulty is a named strength of computer opponent
+type Difficulty string
+
+const (
+	DifficultyEasy   Difficulty = "easy"
+	DifficultyNormal Difficulty = "normal"
+	DifficultyHard   Difficulty = "hard"
+	DifficultyExpert Difficulty = "expert"
+)
+
+// difficultyStrategies maps each difficulty to the strategy that plays it
+var difficultyStrategies = map[Difficulty]string{
+	DifficultyEasy:   "random",
+	DifficultyNormal: "greedy",
+	DifficultyHard:   "effect",
+	DifficultyExpert: "minimax",
+}
+
+// NewStrategy returns the built-in strategy with the given name: "random",
+// "greedy", "cooldown", "effect", or the search strategies "minimax" and
+// "mcts" with DefaultSearchBudget. rng only matters to strategies that make
+// random choices; nil seeds a fresh generator.
+func NewStrategy(name string, rng *rand.Rand) (Strategy, error) {
+	if rng == nil {
+		rng = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	}
+	switch name {
+	case "random":
+		return &RandomStrategy{rng: rng}, nil
+	case "greedy":
+		return GreedyStrategy{}, nil
+	case "cooldown":
+		return CooldownAwareStrategy{}, nil
+	case "effect":
+		return EffectAwareStrategy{}, nil
+	case "minimax":
+		return MinimaxStrategy{Budget: DefaultSearchBudget}, nil
+	case "mcts":
+		return NewMCTSStrategy(DefaultSearchBudget, rng), nil
+	}
+	return nil, fmt.Errorf("unknown strategy %q", name)
+}
+
+// StrategyForDifficulty returns the strategy that plays at difficulty d
+func StrategyForDifficulty(d Difficulty, rng *rand.Rand) (Strategy, error) {
+	name, ok := difficultyStrategies[d]
+	if !ok {
+		return nil, fmt.Errorf("unknown difficulty %q", d)
+	}
+	return NewStrategy(name, rng)
+}
+
+// RandomStrategy uses a random ready ability on a random opponent
+type RandomStrategy struct {
+	rng *rand.Rand
+}
+
+func (s *RandomStrategy) ChooseAction(view BattleView) (BattleAction, bool) {
+	if len(view.Opponents) == 0 {
+		return BattleAction{}, false
+	}
+	target := view.Opponents[s.rng.IntN(len(view.Opponents))]
+	var ready []int
+	for _, i := range readyAbilities(view.Self) {
+		if view.unlocked(i, target) {
+			ready = append(ready, i)
+		}
+	}
+	if len(ready) == 0 {
+		return BattleAction{}, false
+	}
+	return BattleAction{
+		CharacterID:  view.Self.ID,
+		AbilityIndex: ready[s.rng.IntN(len(ready))],
+		TargetID:     target.ID,
+	}, true
+}
+
+// GreedyStrategy deals as much damage as it can right now, preferring the
+// weakest opponent when damage is tied
+type GreedyStrategy struct{}
+
+func (GreedyStrategy) ChooseAction(view BattleView) (BattleAction, bool) {
+	best, bestDamage, bestHealth, found := BattleAction{}, 0, 0, false
+	for _, i := range readyAbilities(view.Self) {
+		for _, target := range view.Opponents {
+			if !view.unlocked(i, target) {
+				continue
+			}
+			damage := view.expectedDamage(view.Self.Abilities[i], target)
+			if !found || damage > bestDamage || (damage == bestDamage && target.Health < bestHealth) {
+				best = BattleAction{CharacterID: view.Self.ID, AbilityIndex: i, TargetID: target.ID}
+				bestDamage, bestHealth, found = damage, target.Health, true
+			}
+		}
+	}
+	return best, found
+}
+
+// CooldownAwareStrategy plays greedily but finishes opponents off with the
+// ability that has the shortest cooldown, keeping its big abilities ready
+type CooldownAwareStrategy struct{}
+
+func (CooldownAwareStrategy) ChooseAction(view BattleView) (BattleAction, bool) {
+	if action, ok := finishingMove(view); ok {
+		return action, true
+	}
+	return GreedyStrategy{}.ChooseAction(view)
+}
+
+// EffectAwareStrategy weighs the status effect each ability applies as well
+// as its damage. It will target itself when an ability's effect is worth
+// more than the damage it takes, and avoids handing opponents buffs.
+type EffectAwareStrategy struct{}
+
+func (EffectAwareStrategy) ChooseAction(view BattleView) (BattleAction, bool) {
+	if action, ok := finishingMove(view); ok {
+		return action, true
+	}
+
+	best, bestScore, found := BattleAction{}, 0, false
+	for _, i := range readyAbilities(view.Self) {
+		ability := view.Self.Abilities[i]
+
+		for _, target := range view.Opponents {
+			if !view.unlocked(i, target) {
+				continue
+			}
+			score := view.expectedDamage(ability, target) + effectValue(ability.StatusEffect, target)
+			if !found || score > bestScore {
+				best = BattleAction{CharacterID: view.Self.ID, AbilityIndex: i, TargetID: target.ID}
+				bestScore, found = score, true
+			}
+		}
+
+		// Self-targeting only makes sense for abilities with an effect
+		if ability.StatusEffect.Type == "" || !view.unlocked(i, view.Self) {
+			continue
+		}
+		score := -view.expectedDamage(ability, view.Self) - effectValue(ability.StatusEffect, view.Self)
+		if !found || score > bestScore {
+			best = BattleAction{CharacterID: view.Self.ID, AbilityIndex: i, TargetID: view.Self.ID}
+			bestScore, found = score, true
+		}
+	}
+	return best, found
+}
+
+// finishingMove returns the ready ability with the shortest cooldown that
+// defeats an opponent outright, if there is one
+func finishingMove(view BattleView) (BattleAction, bool) {
+	best, bestCooldown, found := BattleAction{}, 0, false
+	for _, i := range readyAbilities(view.Self) {
+		ability := view.Self.Abilities[i]
+		for _, target := range view.Opponents {
+			if view.expectedDamage(ability, target) < target.Health || !view.unlocked(i, target) {
+				continue
+			}
+			if !found || ability.CooldownMax < bestCooldown {
+				best = BattleAction{CharacterID: view.Self.ID, AbilityIndex: i, TargetID: target.ID}
+				bestCooldown, found = ability.CooldownMax, true
+			}
+		}
+	}
+	return best, found
+}
+
+// readyAbilities returns the indexes of c's abilities that are off cooldown
+func readyAbilities(c Character) []int {
+	var ready []int
+	for i, ability := range c.Abilities {
+		if ability.CanUse() {
+			ready = append(ready, i)
+		}
+	}
+	return ready
+}
+
+// expectedDamage is the health target loses when view.Self hits it with
+// ability, under the battle's damage model, weather and combos. Hand-made
+// views have no battle and play by the classic rules.
+func (v BattleView) expectedDamage(ability Ability, target Character) int {
+	if ability.Summon != nil {
+		return 0
+	}
+	h := hit{model: DamageSubtract}
+	if v.sim != nil {
+		h = v.sim.hitFor(ability)
+		actor, t := v.sim.characterByID(v.Self.ID), v.sim.characterByID(target.ID)
+		if actor != nil && t != nil && v.sim.comboLands(actor, t, ability) {
+			h.bonus += ability.Combo.Bonus
+		}
+	}
+	return v.Self.healthLost(&ability, &target, h)
+}
+
+// effectValue estimates how much applying effect to target hurts it, in
+// health points, by playing the effect out on a copy of the target. Buffs
+// come out negative. Effects the target already has are worth half as much.
+func effectValue(effect StatusEffectData, target Character) int {
+	if effect.Type == "" {
+		return 0
+	}
+
+	after := target.Clone()
+	after.StatusEffects = []StatusEffectData{effect}
+	for i := 0; i < effect.Duration; i++ {
+		after.ProcessStatusEffect()
+	}
+
+	// Extra attack pays off on every later hit, extra speed only in initiative
+	value := (target.Health - after.Health) -
+		2*(after.Attack-target.Attack) -
+		(after.Speed - target.Speed)
+
+	for _, active := range target.StatusEffects {
+		if active.Type == effect.Type {
+			return value / 2
+		}
+	}
+	return value
+}
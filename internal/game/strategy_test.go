@@ -0,0 +1,207 @@
+package game
+
+import (
+	"context"
+	"math/rand/v2"
+	"testing"
+	"time"
+)
+
+func createTestView() BattleView {
+	self := createTestCharacter("Warrior", 100)
+	self.Abilities = append(self.Abilities, Ability{
+		Name:        "Rage",
+		Damage:      0,
+		CooldownMax: 3,
+		StatusEffect: StatusEffectData{
+			Type:     StatusEnraged,
+			Duration: 3,
+			Potency:  50,
+		},
+	})
+	opponent := createTestCharacter("Mage", 80)
+	return BattleView{
+		Round:     1,
+		Self:      *self,
+		Opponents: []Character{*opponent},
+	}
+}
+
+func TestStrategy_OnlyReadyAbilities(t *testing.T) {
+	view := createTestView()
+	view.Self.Abilities[1].Cooldown = 1
+
+	for _, name := range []string{"random", "greedy", "cooldown", "effect"} {
+		t.Run(name, func(t *testing.T) {
+			strategy, err := NewStrategy(name, rand.New(rand.NewPCG(1, 2)))
+			if err != nil {
+				t.Fatalf("NewStrategy(%q) error = %v", name, err)
+			}
+			for i := 0; i < 20; i++ {
+				action, ok := strategy.ChooseAction(view)
+				if !ok {
+					t.Fatal("Expected strategy to choose an action")
+				}
+				if action.CharacterID != view.Self.ID {
+					t.Errorf("CharacterID = %q, want %q", action.CharacterID, view.Self.ID)
+				}
+				if action.AbilityIndex == 1 {
+					t.Error("Strategy chose an ability that is on cooldown")
+				}
+			}
+		})
+	}
+}
+
+func TestStrategy_PassesWithoutReadyAbilities(t *testing.T) {
+	view := createTestView()
+	for i := range view.Self.Abilities {
+		view.Self.Abilities[i].Cooldown = 1
+	}
+
+	for _, name := range []string{"random", "greedy", "cooldown", "effect"} {
+		strategy, _ := NewStrategy(name, nil)
+		if _, ok := strategy.ChooseAction(view); ok {
+			t.Errorf("%s: expected strategy to pass", name)
+		}
+	}
+}
+
+func TestGreedyStrategy_MaxDamage(t *testing.T) {
+	view := createTestView()
+
+	action, _ := GreedyStrategy{}.ChooseAction(view)
+	if action.AbilityIndex != 1 || action.TargetID != view.Opponents[0].ID {
+		t.Errorf("Expected Special Attack on the opponent, got %+v", action)
+	}
+}
+
+func TestBattleView_ExpectedDamage(t *testing.T) {
+	for _, model := range []DamageModel{DamageSubtract, DamagePercent} {
+		t.Run(string(model), func(t *testing.T) {
+			char1 := createTestCharacter("Warrior", 100)
+			char1.Speed = 20
+			char2 := createTestCharacter("Mage", 100)
+			char2.Defense = 50
+			rules := ClassicRules
+			rules.TurnMode = TurnModeSequential
+			rules.DamageModel = model
+			battle := newBattle(char1, char2, WithRules(rules), WithEnvironment(WeatherHeatwave, 0))
+			char1.Abilities[1].StatusEffect = StatusEffectData{Type: StatusBurning, Duration: 1, Potency: 1}
+			activate(battle)
+
+			view := battle.viewFor(char1)
+			want := view.expectedDamage(char1.Abilities[1], *char2)
+			battle.processAction(BattleAction{CharacterID: char1.ID, AbilityIndex: 1, TargetID: char2.ID})
+			if got := 100 - char2.Health; got != want {
+				t.Errorf("expectedDamage = %d, but the hit took %d", want, got)
+			}
+		})
+	}
+}
+
+func TestCooldownAwareStrategy_SavesBigAbilities(t *testing.T) {
+	view := createTestView()
+	// Basic Attack already finishes the opponent off
+	view.Opponents[0].Health = 10
+
+	action, _ := CooldownAwareStrategy{}.ChooseAction(view)
+	if action.AbilityIndex != 0 {
+		t.Errorf("Expected Basic Attack as finishing move, got ability %d", action.AbilityIndex)
+	}
+
+	// Otherwise it plays greedily
+	view.Opponents[0].Health = 80
+	action, _ = CooldownAwareStrategy{}.ChooseAction(view)
+	if action.AbilityIndex != 1 {
+		t.Errorf("Expected Special Attack, got ability %d", action.AbilityIndex)
+	}
+}
+
+func TestEffectAwareStrategy_NeverBuffsOpponent(t *testing.T) {
+	view := createTestView()
+	view.Self.Abilities[1].Cooldown = 1
+
+	action, _ := EffectAwareStrategy{}.ChooseAction(view)
+	if action.AbilityIndex == 2 && action.TargetID != view.Self.ID {
+		t.Errorf("Expected Rage never to target the opponent, got %+v", action)
+	}
+}
+
+func TestEffectAwareStrategy_BuffsWithNoOpponentInReach(t *testing.T) {
+	view := createTestView()
+	view.Opponents = nil
+
+	action, ok := EffectAwareStrategy{}.ChooseAction(view)
+	if !ok || action.AbilityIndex != 2 || action.TargetID != view.Self.ID {
+		t.Errorf("Expected Rage on itself, got %+v, %v", action, ok)
+	}
+}
+
+func TestStrategyForDifficulty(t *testing.T) {
+	for _, d := range []Difficulty{DifficultyEasy, DifficultyNormal, DifficultyHard} {
+		if _, err := StrategyForDifficulty(d, nil); err != nil {
+			t.Errorf("StrategyForDifficulty(%q) error = %v", d, err)
+		}
+	}
+	if _, err := StrategyForDifficulty("impossible", nil); err == nil {
+		t.Error("Expected error for unknown difficulty")
+	}
+}
+
+func TestBattle_AIOpponent(t *testing.T) {
+	human := createTestCharacter("Warrior", 100)
+	human.Speed = 20
+	computer := createTestCharacter("Mage", 100)
+	strategy, _ := StrategyForDifficulty(DifficultyNormal, nil)
+	battle := newBattle(human, computer, WithTurnMode(TurnModeSequential), WithAI(computer, strategy))
+	t.Cleanup(battle.Stop)
+
+	if err := battle.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start battle: %v", err)
+	}
+
+	result, err := battle.SubmitAction(context.Background(), BattleAction{
+		CharacterID:  human.ID,
+		AbilityIndex: 0,
+		TargetID:     computer.ID,
+	})
+	if err != nil || !result.Success {
+		t.Fatalf("SubmitAction() = %+v, %v", result, err)
+	}
+
+	// The computer replies before the result comes back
+	battle.mu.Lock()
+	defer battle.mu.Unlock()
+	if human.Health >= 100 {
+		t.Error("Expected the computer to have attacked")
+	}
+	if battle.currentTurn() != human {
+		t.Error("Expected the turn to be back with the human")
+	}
+	if battle.Round != 2 {
+		t.Errorf("Expected round 2, got %d", battle.Round)
+	}
+}
+
+func TestBattle_AIVersusAI(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 60)
+	char2 := createTestCharacter("Mage", 60)
+	easy, _ := StrategyForDifficulty(DifficultyEasy, rand.New(rand.NewPCG(1, 2)))
+	hard, _ := StrategyForDifficulty(DifficultyHard, nil)
+	battle := newBattle(char1, char2, WithAI(char1, easy), WithAI(char2, hard))
+	t.Cleanup(battle.Stop)
+
+	if err := battle.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start battle: %v", err)
+	}
+
+	select {
+	case <-battle.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected computer-only battle to finish")
+	}
+	if battle.Winner == nil {
+		t.Error("Expected a winner")
+	}
+}
@@ -0,0 +1,154 @@
+package game
+
+import "fmt"
+
+// MaxMinions is the most minions a side may have in battle at once
+const MaxMinions = 3
+
+// Summon is what a summoning ability brings into battle
+type Summon struct {
+	// Minion is the template of the summoned character. Its ID is assigned
+	// when it is summoned.
+	Minion Character `json:"Minion"`
+	// Strategy names the built-in strategy that plays the minion's turns, as
+	// taken by NewStrategy; empty is "greedy"
+	Strategy string `json:"Strategy,omitempty"`
+	// Rounds is how many rounds the minion stays, counting the one it is
+	// summoned in. Zero keeps it for as long as its summoner stands.
+	Rounds int `json:"Rounds,omitempty"`
+}
+
+// Minion is a summoned character fighting on its summoner's side. It takes
+// turns and can be targeted like any other combatant, but never holds a side
+// up on its own: only the characters in front decide the battle.
+type Minion struct {
+	Character  *Character `json:"Character"`
+	SummonerID string     `json:"SummonerID"`
+	// Side is 1 or 2 for the side the minion fights on
+	Side int `json:"Side"`
+	// Remaining is the number of rounds left, counting the current one, or
+	// zero if the minion stays until its summoner falls
+	Remaining int `json:"Remaining,omitempty"`
+}
+
+// minionsOn returns how many minions fight on the given side
+func (b *Battle) minionsOn(side int) int {
+	n := 0
+	for _, m := range b.Minions {
+		if m.Side == side {
+			n++
+		}
+	}
+	return n
+}
+
+// applySummon puts the ability on cooldown and brings its minion into the
+// battle on the actor's side, next to the actor on a grid. The minion first
+// acts in the next round, or once its gauge fills in ATB mode. The target is
+// not affected.
+func (b *Battle) applySummon(actor *Character, action BattleAction) BattleActionResult {
+	ability := &actor.Abilities[action.AbilityIndex]
+	if !ability.CanUse() {
+		return b.reject("Ability on cooldown")
+	}
+	side := b.sideOf(actor.ID)
+	if b.minionsOn(side) >= MaxMinions {
+		return b.reject(fmt.Sprintf("side %d already has %d minions", side, MaxMinions))
+	}
+	name := ability.Summon.Strategy
+	if name == "" {
+		name = "greedy"
+	}
+	strategy, err := NewStrategy(name, b.rng)
+	if err != nil {
+		return b.reject(err.Error())
+	}
+
+	c := ability.Summon.Minion.Clone()
+	c.ID = fmt.Sprintf("%s-minion-%d", actor.ID, b.summoned+1)
+	c.MaxHealth = c.Health
+	if b.Grid != nil && !b.placeNear(&c, b.Grid.Positions[actor.ID]) {
+		return b.reject("there is no room on the grid for a minion")
+	}
+	actor.lowerGuard()
+	b.summoned++
+	minion := &Minion{
+		Character:  &c,
+		SummonerID: actor.ID,
+		Side:       side,
+		Remaining:  ability.Summon.Rounds,
+	}
+	b.Minions = append(b.Minions, minion)
+	if b.controllers == nil {
+		b.controllers = make(map[*Character]Strategy)
+	}
+	b.controllers[&c] = strategy
+
+	ability.Use()
+	b.history = append(b.history, ActionRecord{
+		Round:        b.Round,
+		Kind:         ActionAbility,
+		CharacterID:  actor.ID,
+		AbilityIndex: action.AbilityIndex,
+		Ability:      ability.Name,
+		TargetID:     action.TargetID,
+	})
+	b.endTurn(actor, action.AbilityIndex)
+
+	result := AbilityResult{
+		Success:  true,
+		Message:  fmt.Sprintf("%s summons %s", actor.Name, c.Name),
+		Summoned: minion,
+	}
+	return BattleActionResult{
+		Success: true,
+		Message: result.Message,
+		Battle:  b,
+		Ability: &result,
+	}
+}
+
+// dismissMinions removes minions that have fallen or whose summoner has.
+// A summoner waiting in reserve keeps its minions. Minions are listed after
+// whoever summoned them, so those summoned by a dismissed minion follow it.
+func (b *Battle) dismissMinions() {
+	for i := 0; i < len(b.Minions); {
+		m := b.Minions[i]
+		summoner := b.anyCharacterByID(m.SummonerID)
+		if m.Character.Health > 0 && summoner != nil && summoner.Health > 0 {
+			i++
+			continue
+		}
+		b.dismiss(i)
+	}
+}
+
+// expireMinions counts down the minions summoned for a number of rounds at
+// the end of a round and dismisses those whose time is up
+func (b *Battle) expireMinions() {
+	for i := 0; i < len(b.Minions); {
+		m := b.Minions[i]
+		if m.Remaining > 0 {
+			if m.Remaining--; m.Remaining == 0 {
+				b.dismiss(i)
+				continue
+			}
+		}
+		i++
+	}
+	b.dismissMinions()
+}
+
+// dismiss takes the i'th minion out of the battle. It keeps its place in
+// the current turn order, where endTurn passes over it.
+func (b *Battle) dismiss(i int) {
+	c := b.Minions[i].Character
+	b.Minions = append(b.Minions[:i:i], b.Minions[i+1:]...)
+	delete(b.controllers, c)
+	delete(b.gauges, c.ID)
+	delete(b.acted, c.ID)
+	delete(b.sealed, c.ID)
+	if b.Grid != nil {
+		delete(b.Grid.Positions, c.ID)
+	}
+}
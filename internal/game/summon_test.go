@@ -0,0 +1,139 @@
+package game
+
+import "testing"
+
+// withSummon gives c a summoning ability at index 2 for a minion with the
+// given speed that stays for rounds rounds
+func withSummon(c *Character, speed, rounds int) {
+	c.Abilities = append(c.Abilities, Ability{Name: "Call Wolf", CooldownMax: 5, Summon: &Summon{
+		Minion: Character{
+			Name:      "Wolf",
+			Health:    20,
+			Attack:    5,
+			Speed:     speed,
+			Abilities: []Ability{{Name: "Bite", Damage: 5}},
+		},
+		Rounds: rounds,
+	}})
+}
+
+func TestBattle_SummonExpires(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char1.Speed = 20
+	withSummon(char1, 30, 2)
+	char2 := createTestCharacter("Mage", 100)
+	battle := newBattle(char1, char2, WithTurnMode(TurnModeSequential))
+	activate(battle)
+
+	result := battle.processAction(BattleAction{CharacterID: char1.ID, AbilityIndex: 2, TargetID: char2.ID})
+	if !result.Success || result.Ability.Summoned == nil {
+		t.Fatalf("Expected a minion to be summoned, got %+v", result)
+	}
+	wolf := result.Ability.Summoned.Character
+	if wolf.ID != "Warrior_id-minion-1" || wolf.MaxHealth != 20 || char2.Health != 100 {
+		t.Errorf("Unexpected summon %+v, Mage health %d", wolf, char2.Health)
+	}
+	if !battle.ControlledByAI(wolf) {
+		t.Error("Expected the minion to be played by its strategy")
+	}
+	if battle.minionsOn(1) != 1 || battle.sideOf(wolf.ID) != 1 {
+		t.Error("Expected the minion to fight on its summoner's side")
+	}
+
+	// The minion joins the turn order from the next round, fastest first
+	battle.processAction(BattleAction{CharacterID: char2.ID, AbilityIndex: 0, TargetID: char1.ID})
+	if battle.Round != 2 || battle.currentTurn() != wolf {
+		t.Fatalf("Expected the Wolf to open round 2, got %s in round %d", battle.currentTurn().Name, battle.Round)
+	}
+	if result := battle.processAction(BattleAction{CharacterID: wolf.ID, AbilityIndex: 0, TargetID: char2.ID}); !result.Success {
+		t.Fatalf("Wolf attack failed: %s", result.Message)
+	}
+	if char2.Health != 95 {
+		t.Errorf("Mage health = %d, want 95", char2.Health)
+	}
+	battle.processAction(BattleAction{CharacterID: char1.ID, AbilityIndex: 0, TargetID: char2.ID})
+	battle.processAction(BattleAction{CharacterID: char2.ID, AbilityIndex: 0, TargetID: wolf.ID})
+
+	if len(battle.Minions) != 0 || battle.characterByID(wolf.ID) != nil {
+		t.Error("Expected the Wolf to leave after two rounds")
+	}
+	if battle.Round != 3 || len(battle.turnOrder) != 2 {
+		t.Errorf("Expected round 3 without the Wolf, got round %d with %d in order", battle.Round, len(battle.turnOrder))
+	}
+}
+
+func TestBattle_SummonerDefeated(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 10)
+	char1.Speed = 20
+	withSummon(char1, 30, 0)
+	reserve := createTestCharacter("Knight", 100)
+	char2 := createTestCharacter("Mage", 100)
+	battle := newBattle(char1, char2, WithTurnMode(TurnModeSequential), WithReserves(1, reserve))
+	activate(battle)
+
+	battle.processAction(BattleAction{CharacterID: char1.ID, AbilityIndex: 2, TargetID: char2.ID})
+	if len(battle.Minions) != 1 {
+		t.Fatal("Expected a minion")
+	}
+	battle.processAction(BattleAction{CharacterID: char2.ID, AbilityIndex: 0, TargetID: char1.ID})
+	if battle.Character1 != reserve {
+		t.Fatalf("Expected the Knight to take over, got %s", battle.Character1.Name)
+	}
+	if len(battle.Minions) != 0 || battle.State != BattleStateActive {
+		t.Errorf("Expected the Wolf to leave with its summoner and the battle to go on, got %d minions and %s", len(battle.Minions), battle.State)
+	}
+}
+
+func TestBattle_MinionTargeted(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char1.Speed = 20
+	withSummon(char1, 5, 0)
+	char2 := createTestCharacter("Mage", 100)
+	char2.Abilities[0].Damage = 30
+	battle := newBattle(char1, char2, WithTurnMode(TurnModeSequential))
+	activate(battle)
+
+	battle.processAction(BattleAction{CharacterID: char1.ID, AbilityIndex: 2, TargetID: char2.ID})
+	wolf := battle.Minions[0].Character
+	if view := battle.viewFor(wolf); len(view.Opponents) != 1 || view.Opponents[0].ID != char2.ID {
+		t.Errorf("Expected the Wolf to face only the Mage, got %+v", view.Opponents)
+	}
+	if view := battle.viewFor(char2); len(view.Opponents) != 2 {
+		t.Errorf("Expected the Mage to face the Warrior and the Wolf, got %+v", view.Opponents)
+	}
+	battle.processAction(BattleAction{CharacterID: char2.ID, AbilityIndex: 0, TargetID: char1.ID})
+
+	// Round 2 runs Warrior, Mage, Wolf; the Wolf falls before its turn
+	battle.processAction(BattleAction{CharacterID: char1.ID, AbilityIndex: 0, TargetID: char2.ID})
+	if result := battle.processAction(BattleAction{CharacterID: char2.ID, AbilityIndex: 0, TargetID: wolf.ID}); !result.Success {
+		t.Fatalf("Attack on the Wolf failed: %s", result.Message)
+	}
+	if len(battle.Minions) != 0 {
+		t.Error("Expected the fallen Wolf to leave")
+	}
+	if battle.Round != 3 || battle.currentTurn() != char1 {
+		t.Errorf("Expected the Wolf's turn to be skipped, got %s in round %d", battle.currentTurn().Name, battle.Round)
+	}
+}
+
+func TestBattle_SimulationCopiesMinions(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char1.Speed = 20
+	withSummon(char1, 30, 3)
+	char2 := createTestCharacter("Mage", 100)
+	battle := newBattle(char1, char2, WithTurnMode(TurnModeSequential))
+	activate(battle)
+	battle.processAction(BattleAction{CharacterID: char1.ID, AbilityIndex: 2, TargetID: char2.ID})
+
+	sim := battle.simulationCopy()
+	if len(sim.Minions) != 1 || sim.Minions[0].Character == battle.Minions[0].Character {
+		t.Fatal("Expected the simulation to hold its own copy of the minion")
+	}
+	sim.Minions[0].Character.Health = 0
+	if battle.Minions[0].Character.Health != 20 {
+		t.Error("Expected the simulation to leave the battle's minion alone")
+	}
+	if sim.evaluate(char1.ID) != sim.evaluate(sim.Minions[0].Character.ID) {
+		t.Error("Expected a minion to score the battle as its summoner does")
+	}
+}
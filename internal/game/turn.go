@@ -0,0 +1,194 @@
+package game
+
+import (
+	"sort"
+	"time"
+)
+
+// TurnMode decides how strictly the battle enforces turn order
+type TurnMode string
+
+const (
+	// TurnModeFree accepts actions from any character at any time. Turn order
+	// is still tracked so that the computer knows when to act.
+	TurnModeFree TurnMode = "FREE"
+	// TurnModeSequential rejects actions from characters whose turn it is not
+	TurnModeSequential TurnMode = "SEQUENTIAL"
+)
+
+// BattleView is a read-only snapshot of a battle from one character's side.
+// It holds copies, so strategies are free to inspect or modify it.
+type BattleView struct {
+	Round     int
+	Self      Character
+	Opponents []Character
+
+	// sim is an exact copy of the battle for search strategies to play out
+	sim *Battle
+}
+
+// combatants returns every character taking part in the battle: the two in
+// front, then any minions in the order they were summoned
+func (b *Battle) combatants() []*Character {
+	combatants := []*Character{b.Character1, b.Character2}
+	for _, m := range b.Minions {
+		combatants = append(combatants, m.Character)
+	}
+	return combatants
+}
+
+// characterByID returns the combatant with the given ID, or nil
+func (b *Battle) characterByID(id string) *Character {
+	for _, c := range b.combatants() {
+		if c.ID == id {
+			return c
+		}
+	}
+	return nil
+}
+
+// initiativeOrder sorts combatants by Speed, fastest first. Ties keep the
+// order in which the characters joined the battle.
+func (b *Battle) initiativeOrder() []*Character {
+	order := b.combatants()
+	sort.SliceStable(order, func(i, j int) bool {
+		return order[i].Speed > order[j].Speed
+	})
+	return order
+}
+
+// CurrentTurn returns the character whose turn it is
+func (b *Battle) CurrentTurn() *Character {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.currentTurn()
+}
+
+// ControlledByAI reports whether the battle plays c's turns itself
+func (b *Battle) ControlledByAI(c *Character) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, ok := b.controllers[c]
+	return ok
+}
+
+func (b *Battle) currentTurn() *Character {
+	if len(b.turnOrder) == 0 {
+		return nil
+	}
+	return b.turnOrder[b.turnIndex]
+}
+
+// endTurn closes the actor's turn after it used the ability at usedIndex, or
+// passed if usedIndex is negative. Status effects tick unless the rules tick
+// them by round, the actor's other cooldowns count down and the turn moves
+// to whoever follows the actor in initiative. The round advances once the
+// last character in it has acted.
+func (b *Battle) endTurn(actor *Character, usedIndex int) {
+	if b.Rules.EffectTick != EffectTickRound {
+		b.tickEffects()
+	}
+
+	for i := range actor.Abilities {
+		if i != usedIndex {
+			actor.Abilities[i].ReduceCooldown()
+		}
+	}
+
+	b.checkBattleEnd()
+	if b.State == BattleStateComplete {
+		return
+	}
+	if b.TurnMode == TurnModeATB {
+		b.endATBTurn(actor)
+		return
+	}
+
+	next := b.turnIndex + 1
+	for i, c := range b.turnOrder {
+		if c == actor {
+			next = i + 1
+			break
+		}
+	}
+	// Minions dismissed this round keep their place but take no turn
+	for next < len(b.turnOrder) && b.characterByID(b.turnOrder[next].ID) == nil {
+		next++
+	}
+	if next >= len(b.turnOrder) {
+		if b.nextRound(); b.State == BattleStateComplete {
+			return
+		}
+		b.turnOrder = b.initiativeOrder()
+		b.waited = nil
+		next = 0
+	}
+	b.turnIndex = next
+	b.currentTurn().lowerGuard()
+	b.resetTurnTimer(time.Now())
+
+	// Stunned characters and those locked into a charge or channel pass
+	if next := b.currentTurn(); b.locked(next) != "" {
+		b.endTurn(next, -1)
+	}
+}
+
+// tickEffects processes every combatant's status effects once
+func (b *Battle) tickEffects() {
+	for _, c := range b.combatants() {
+		c.ProcessStatusEffect()
+	}
+}
+
+// nextRound closes the current round. Effects that tick by round take hold,
+// pending abilities that are due land, the weather has its say, minions
+// whose time is up leave and the battle ends as a draw once it has run out
+// of rounds.
+func (b *Battle) nextRound() {
+	if b.Rules.EffectTick == EffectTickRound {
+		b.tickEffects()
+		if b.checkBattleEnd(); b.State == BattleStateComplete {
+			return
+		}
+	}
+	b.resolvePending()
+	if b.checkBattleEnd(); b.State == BattleStateComplete {
+		return
+	}
+	b.passWeather()
+	b.expireMinions()
+	b.Round++
+	if b.Rules.MaxRounds > 0 && b.Round > b.Rules.MaxRounds {
+		b.Round = b.Rules.MaxRounds
+		b.conclude()
+	}
+}
+
+// applyStartingCooldowns puts every ability on cooldown if the rules say
+// battles start that way
+func (b *Battle) applyStartingCooldowns() {
+	if !b.Rules.StartingCooldowns {
+		return
+	}
+	for _, c := range append(append(b.combatants(), b.Reserves1...), b.Reserves2...) {
+		for i := range c.Abilities {
+			c.Abilities[i].Cooldown = c.Abilities[i].CooldownMax
+		}
+	}
+}
+
+// viewFor builds the read-only view handed to c's strategy
+func (b *Battle) viewFor(c *Character) BattleView {
+	view := BattleView{
+		Round: b.Round,
+		Self:  c.Clone(),
+		sim:   b.simulationCopy(),
+	}
+	side := b.sideOf(c.ID)
+	for _, other := range b.combatants() {
+		if other != c && b.sideOf(other.ID) != side {
+			view.Opponents = append(view.Opponents, other.Clone())
+		}
+	}
+	return view
+}
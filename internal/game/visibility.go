@@ -0,0 +1,233 @@
+package game
+
+import "time"
+
+// Hidden parts of a character, as listed in CharacterView.Hidden
+const (
+	HiddenCooldowns = "Cooldowns"
+	HiddenAbilities = "Abilities"
+	HiddenHealth    = "Health"
+)
+
+// Visibility is what a player is kept from seeing of the other side. The
+// zero value hides nothing.
+type Visibility struct {
+	// HideCooldowns hides how long opponents' abilities have left to cool
+	// down
+	HideCooldowns bool `json:"HideCooldowns,omitempty"`
+	// HideUnrevealedAbilities leaves out opponents' abilities until they
+	// have been used in the battle
+	HideUnrevealedAbilities bool `json:"HideUnrevealedAbilities,omitempty"`
+	// HealthBuckets shows opponents' health only as a percentage of their
+	// maximum, rounded up to one of this many steps so that anyone standing
+	// shows above zero. Zero shows exact health.
+	HealthBuckets int `json:"HealthBuckets,omitempty"`
+}
+
+// CharacterView is a character as one player sees it. Hidden fields are
+// cleared, and Hidden names them.
+type CharacterView struct {
+	Character
+	// HealthPercent stands in for Health and MaxHealth when they are hidden
+	HealthPercent *int `json:"HealthPercent,omitempty"`
+	// HiddenAbilities counts the abilities left out of Abilities
+	HiddenAbilities int      `json:"HiddenAbilities,omitempty"`
+	Hidden          []string `json:"Hidden,omitempty"`
+}
+
+// MinionView is a minion as one player sees it
+type MinionView struct {
+	Character  *CharacterView `json:"Character"`
+	SummonerID string         `json:"SummonerID"`
+	Side       int            `json:"Side"`
+	Remaining  int            `json:"Remaining,omitempty"`
+}
+
+// PlayerView is the whole battle as one player sees it, taken in a single
+// pass under the battle's lock so that it never mixes two moments of play
+type PlayerView struct {
+	ID         string
+	State      BattleState
+	Round      int
+	Character1 *CharacterView
+	Character2 *CharacterView
+	Winner     *CharacterView
+	Reserves1  []*CharacterView
+	Reserves2  []*CharacterView
+	Fled       *CharacterView
+	// Minions are the summoned characters in battle, as seen
+	Minions     []MinionView
+	PauseReason string
+	// TurnTimeRemaining is as TurnTimeRemaining reports it
+	TurnTimeRemaining time.Duration
+	TurnMode          TurnMode
+	Rules             RuleSet
+	// CurrentTurn is the ID of whoever's turn it is in the initiative
+	// order, if anyone's
+	CurrentTurn string
+	// AIControlled lists the IDs of the combatants the battle plays itself
+	AIControlled []string
+	Sealed       []string
+	Gauges       map[string]int
+	Environment  *Environment
+	Pending      []PendingAbility
+	Grid         *Grid
+	CreatedAt    time.Time
+}
+
+// View returns the battle as seen from side, which is 1 or 2 for a player
+// or 0 for a spectator. A player sees everything of their own side; the
+// other side, and both for a spectator, is hidden as Rules.Visibility says.
+func (b *Battle) View(side int) PlayerView {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	p := b.perspective(side)
+	view := PlayerView{
+		ID:                b.ID,
+		State:             b.State,
+		Round:             b.Round,
+		Character1:        p.character(b.Character1),
+		Character2:        p.character(b.Character2),
+		Winner:            p.character(b.Winner),
+		Reserves1:         p.characters(b.Reserves1),
+		Reserves2:         p.characters(b.Reserves2),
+		Fled:              p.character(b.Fled),
+		Minions:           p.minions(),
+		PauseReason:       b.PauseReason,
+		TurnTimeRemaining: b.turnTimeRemaining(),
+		TurnMode:          b.TurnMode,
+		Rules:             b.Rules,
+		Sealed:            b.sealedIDs(),
+		Gauges:            b.gaugeLevels(),
+		Pending:           append([]PendingAbility(nil), b.Pending...),
+		CreatedAt:         b.CreatedAt,
+	}
+	if c := b.currentTurn(); c != nil {
+		view.CurrentTurn = c.ID
+	}
+	for _, c := range b.combatants() {
+		if _, ok := b.controllers[c]; ok {
+			view.AIControlled = append(view.AIControlled, c.ID)
+		}
+	}
+	if b.Environment != nil {
+		environment := *b.Environment
+		view.Environment = &environment
+	}
+	if b.Grid != nil {
+		view.Grid = b.Grid.clone()
+	}
+	return view
+}
+
+// SideOf returns 1 or 2 for the side the character with the given ID
+// fights on, in front, in reserve or as a minion, or 0 if it is not in the
+// battle
+func (b *Battle) SideOf(id string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.sideOf(id)
+}
+
+// perspective renders characters as one side sees them. It reads the
+// battle, so its holder must hold b.mu.
+type perspective struct {
+	// side is 1 or 2 for a player, who sees everything of their own side,
+	// or 0 for a spectator, who sees neither side's secrets
+	side   int
+	battle *Battle
+	// revealed holds the indices of the abilities each character has used,
+	// by ID
+	revealed map[string]map[int]bool
+}
+
+// perspective returns the battle as seen from side. The caller must hold
+// b.mu.
+func (b *Battle) perspective(side int) perspective {
+	revealed := make(map[string]map[int]bool)
+	for _, record := range b.history {
+		if record.Kind != ActionAbility {
+			continue
+		}
+		if revealed[record.CharacterID] == nil {
+			revealed[record.CharacterID] = make(map[int]bool)
+		}
+		revealed[record.CharacterID][record.AbilityIndex] = true
+	}
+	return perspective{side: side, battle: b, revealed: revealed}
+}
+
+// character returns c as the viewer sees it, or nil for nil
+func (p perspective) character(c *Character) *CharacterView {
+	if c == nil {
+		return nil
+	}
+	view := &CharacterView{Character: c.Clone()}
+	if p.side != 0 && p.battle.sideOf(c.ID) == p.side {
+		return view
+	}
+
+	visibility := p.battle.Rules.Visibility
+	if visibility.HideUnrevealedAbilities {
+		shown := []Ability{}
+		for i, ability := range view.Abilities {
+			if p.revealed[c.ID][i] {
+				shown = append(shown, ability)
+			}
+		}
+		if view.HiddenAbilities = len(view.Abilities) - len(shown); view.HiddenAbilities > 0 {
+			view.Hidden = append(view.Hidden, HiddenAbilities)
+		}
+		view.Abilities = shown
+	}
+	if visibility.HideCooldowns {
+		for i := range view.Abilities {
+			view.Abilities[i].Cooldown = 0
+		}
+		view.Hidden = append(view.Hidden, HiddenCooldowns)
+	}
+	if n := visibility.HealthBuckets; n > 0 {
+		percent := healthBucket(c.Health, c.MaxHealth, n)
+		view.HealthPercent = &percent
+		view.Health, view.MaxHealth = 0, 0
+		view.Hidden = append(view.Hidden, HiddenHealth)
+	}
+	return view
+}
+
+// characters returns cs as the viewer sees them
+func (p perspective) characters(cs []*Character) []*CharacterView {
+	var views []*CharacterView
+	for _, c := range cs {
+		views = append(views, p.character(c))
+	}
+	return views
+}
+
+// minions returns the battle's minions as the viewer sees them
+func (p perspective) minions() []MinionView {
+	var views []MinionView
+	for _, m := range p.battle.Minions {
+		views = append(views, MinionView{
+			Character:  p.character(m.Character),
+			SummonerID: m.SummonerID,
+			Side:       m.Side,
+			Remaining:  m.Remaining,
+		})
+	}
+	return views
+}
+
+// healthBucket returns health as a percentage of maxHealth, rounded up to
+// one of n steps
+func healthBucket(health, maxHealth, n int) int {
+	if health <= 0 || maxHealth <= 0 {
+		return 0
+	}
+	if health >= maxHealth {
+		return 100
+	}
+	step := (health*n + maxHealth - 1) / maxHealth
+	return step * 100 / n
+}
@@ -0,0 +1,61 @@
+package game
+
+import "testing"
+
+func TestBattle_View(t *testing.T) {
+	char1 := createTestCharacter("Warrior", 100)
+	char1.Speed = 20
+	char2 := createTestCharacter("Mage", 100)
+	rules := ClassicRules
+	rules.TurnMode = TurnModeSequential
+	rules.Visibility = Visibility{HideCooldowns: true, HideUnrevealedAbilities: true, HealthBuckets: 4}
+	battle := newBattle(char1, char2, WithRules(rules))
+	activate(battle)
+
+	battle.processAction(BattleAction{CharacterID: char1.ID, AbilityIndex: 1, TargetID: char2.ID})
+	battle.processAction(BattleAction{CharacterID: char2.ID, AbilityIndex: 0, TargetID: char1.ID})
+
+	own := battle.View(1).Character1
+	if len(own.Hidden) != 0 || own.Health != char1.Health || own.Abilities[1].Cooldown == 0 {
+		t.Errorf("Expected a player to see all of their own character, got %+v", own)
+	}
+
+	seen := battle.View(2).Character1
+	if len(seen.Abilities) != 1 || seen.Abilities[0].Name != char1.Abilities[1].Name || seen.HiddenAbilities != 1 {
+		t.Errorf("Expected only the used ability to show, got %+v with %d hidden", seen.Abilities, seen.HiddenAbilities)
+	}
+	if seen.Abilities[0].Cooldown != 0 {
+		t.Error("Expected the opponent's cooldown to be hidden")
+	}
+	if seen.Health != 0 || seen.HealthPercent == nil || *seen.HealthPercent != healthBucket(char1.Health, char1.MaxHealth, 4) {
+		t.Errorf("Expected health as a bucket, got %d and %v", seen.Health, seen.HealthPercent)
+	}
+	if len(seen.Hidden) != 3 {
+		t.Errorf("Hidden = %v, want abilities, cooldowns and health", seen.Hidden)
+	}
+	if char1.Health == 0 || len(char1.Abilities) != 2 || char1.Abilities[1].Cooldown == 0 {
+		t.Error("Expected the view to leave the character alone")
+	}
+
+	if spectator := battle.View(0).Character2; spectator.HealthPercent == nil {
+		t.Error("Expected a spectator to see neither side's secrets")
+	}
+}
+
+func TestHealthBucket(t *testing.T) {
+	tests := []struct {
+		health, n, want int
+	}{
+		{100, 4, 100},
+		{76, 4, 100},
+		{75, 4, 75},
+		{1, 4, 25},
+		{0, 4, 0},
+		{50, 3, 66},
+	}
+	for _, tt := range tests {
+		if got := healthBucket(tt.health, 100, tt.n); got != tt.want {
+			t.Errorf("healthBucket(%d, 100, %d) = %d, want %d", tt.health, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+// Package progression turns throwaway battle characters into persistent ones
+// that earn experience, level up and unlock abilities as they grow.
+package progression
+
+import (
+	"fmt"
+
+	"github.com/MaterDev/golang_turnbased_game_spike/internal/game"
+	"github.com/MaterDev/golang_turnbased_game_spike/internal/validation"
+)
+
+// MaxLevel is the highest level a character can reach
+const MaxLevel = 50
+
+// Stats are the numbers a level changes
+type Stats struct {
+	Health  int `json:"Health"`
+	Attack  int `json:"Attack"`
+	Defense int `json:"Defense"`
+	Speed   int `json:"Speed"`
+}
+
+// Unlock grants an ability, by ID, once a character reaches Level
+type Unlock struct {
+	Level   int    `json:"Level"`
+	Ability string `json:"Ability"`
+}
+
+// GrowthCurve is how a class grows: the stats it gains with every level and
+// the abilities it unlocks along the way
+type GrowthCurve struct {
+	Stats
+	Unlocks []Unlock `json:"Unlocks"`
+}
+
+// Profile is a persistent character
+type Profile struct {
+	ID         string `json:"ID"`
+	Name       string `json:"Name"`
+	Class      string `json:"Class"`
+	Level      int    `json:"Level"`
+	Experience int    `json:"Experience"`
+	// Base holds the stats at level 1
+	Base Stats `json:"Base"`
+	// Abilities are the IDs of the abilities the character starts with
+	Abilities []string `json:"Abilities"`
+}
+
+// NewProfile returns a level 1 character
+func NewProfile(id, name, class string, base Stats, abilities []string) *Profile {
+	return &Profile{
+		ID:        id,
+		Name:      name,
+		Class:     class,
+		Level:     1,
+		Base:      base,
+		Abilities: append([]string(nil), abilities...),
+	}
+}
+
+// ExperienceForLevel is the total experience needed to reach level. Each
+// level costs 100 more than the one before: 100 for level 2, 300 for level
+// 3, 600 for level 4 and so on.
+func ExperienceForLevel(level int) int {
+	if level <= 1 {
+		return 0
+	}
+	return 100 * (level - 1) * level / 2
+}
+
+// LevelForExperience is the level reached with xp experience
+func LevelForExperience(xp int) int {
+	level := 1
+	for level < MaxLevel && xp >= ExperienceForLevel(level+1) {
+		level++
+	}
+	return level
+}
+
+// AddExperience adds xp and levels the character up as far as it now
+// reaches. It returns the number of levels gained.
+func (p *Profile) AddExperience(xp int) int {
+	if xp <= 0 {
+		return 0
+	}
+	p.Experience += xp
+	before := p.Level
+	p.Level = LevelForExperience(p.Experience)
+	return p.Level - before
+}
+
+// Stats returns the character's stats at its current level. Growth stops at
+// the validation limits so that a character of any level can still battle.
+func (p *Profile) Stats(curve GrowthCurve) Stats {
+	levels := p.Level - 1
+	limits := validation.DefaultLimits
+	return Stats{
+		Health:  grow(p.Base.Health, curve.Health*levels, limits.MaxHealth),
+		Attack:  grow(p.Base.Attack, curve.Attack*levels, limits.MaxAttack),
+		Defense: grow(p.Base.Defense, curve.Defense*levels, limits.MaxDefense),
+		Speed:   grow(p.Base.Speed, curve.Speed*levels, limits.MaxSpeed),
+	}
+}
+
+// grow adds gain to base up to limit. A base already past the limit is left
+// as it is.
+func grow(base, gain, limit int) int {
+	return max(min(base+gain, limit), base)
+}
+
+// AbilityIDs returns the starting abilities followed by every ability
+// unlocked so far, without duplicates
+func (p *Profile) AbilityIDs(curve GrowthCurve) []string {
+	ids := append([]string(nil), p.Abilities...)
+	for _, unlock := range curve.Unlocks {
+		if unlock.Level <= p.Level && !contains(ids, unlock.Ability) {
+			ids = append(ids, unlock.Ability)
+		}
+	}
+	return ids
+}
+
+// AbilitySource looks abilities up by ID
+type AbilitySource interface {
+	Ability(id string) (game.Ability, bool)
+}
+
+// CombatCharacter builds the character that takes this profile into battle.
+// It carries the profile's ID so results can be traced back to it.
+func (p *Profile) CombatCharacter(curve GrowthCurve, abilities AbilitySource) (game.Character, error) {
+	stats := p.Stats(curve)
+	c := game.Character{
+		ID:      p.ID,
+		Name:    p.Name,
+		Class:   p.Class,
+		Health:  stats.Health,
+		Attack:  stats.Attack,
+		Defense: stats.Defense,
+		Speed:   stats.Speed,
+	}
+	for _, id := range p.AbilityIDs(curve) {
+		ability, ok := abilities.Ability(id)
+		if !ok {
+			return game.Character{}, fmt.Errorf("unknown ability %q", id)
+		}
+		c.Abilities = append(c.Abilities, ability)
+	}
+	return c, nil
+}
+
+// Progress is a character's standing and what comes next
+type Progress struct {
+	Level      int `json:"Level"`
+	Experience int `json:"Experience"`
+	// NextLevelAt is the total experience needed for the next level, or
+	// zero at MaxLevel
+	NextLevelAt     int      `json:"NextLevelAt"`
+	Stats           Stats    `json:"Stats"`
+	Abilities       []string `json:"Abilities"`
+	UpcomingUnlocks []Unlock `json:"UpcomingUnlocks"`
+}
+
+// Progress reports the character's progression along curve
+func (p *Profile) Progress(curve GrowthCurve) Progress {
+	progress := Progress{
+		Level:           p.Level,
+		Experience:      p.Experience,
+		Stats:           p.Stats(curve),
+		Abilities:       p.AbilityIDs(curve),
+		UpcomingUnlocks: []Unlock{},
+	}
+	if p.Level < MaxLevel {
+		progress.NextLevelAt = ExperienceForLevel(p.Level + 1)
+	}
+	for _, unlock := range curve.Unlocks {
+		if unlock.Level > p.Level {
+			progress.UpcomingUnlocks = append(progress.UpcomingUnlocks, unlock)
+		}
+	}
+	return progress
+}
+
+// Outcome is how a battle ended for one character
+type Outcome string
+
+const (
+	OutcomeWin  Outcome = "WIN"
+	OutcomeLoss Outcome = "LOSS"
+	OutcomeDraw Outcome = "DRAW"
+)
+
+// baseReward is the experience for each outcome against an equal opponent
+var baseReward = map[Outcome]int{
+	OutcomeWin:  100,
+	OutcomeDraw: 50,
+	OutcomeLoss: 25,
+}
+
+// ExperienceReward is the experience self earns from a battle against
+// opponent. Stronger opponents are worth more, up to double, and weaker ones
+// less, down to half.
+func ExperienceReward(outcome Outcome, self, opponent game.Character) int {
+	ratio := float64(PowerRating(opponent)) / float64(max(PowerRating(self), 1))
+	ratio = min(max(ratio, 0.5), 2)
+	return int(float64(baseReward[outcome]) * ratio)
+}
+
+// PowerRating is a rough measure of how strong a character is
+func PowerRating(c game.Character) int {
+	return c.Health/10 + c.Attack + c.Defense + c.Speed
+}
+
+func contains(ids []string, id string) bool {
+	for _, existing := range ids {
+		if existing == id {
+			return true
+		}
+	}
+	return false
+}
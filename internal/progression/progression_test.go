@@ -0,0 +1,125 @@
+package progression
+
+import (
+	"testing"
+
+	"github.com/MaterDev/golang_turnbased_game_spike/internal/game"
+)
+
+type abilityMap map[string]game.Ability
+
+func (m abilityMap) Ability(id string) (game.Ability, bool) {
+	a, ok := m[id]
+	return a, ok
+}
+
+var testCurve = GrowthCurve{
+	Stats:   Stats{Health: 10, Attack: 2, Defense: 1, Speed: 0},
+	Unlocks: []Unlock{{Level: 3, Ability: "fireball"}, {Level: 5, Ability: "meteor"}},
+}
+
+func TestLevelForExperience(t *testing.T) {
+	tests := []struct {
+		xp   int
+		want int
+	}{
+		{0, 1},
+		{99, 1},
+		{100, 2},
+		{299, 2},
+		{300, 3},
+		{600, 4},
+		{1 << 30, MaxLevel},
+	}
+	for _, tt := range tests {
+		if got := LevelForExperience(tt.xp); got != tt.want {
+			t.Errorf("LevelForExperience(%d) = %d, want %d", tt.xp, got, tt.want)
+		}
+	}
+}
+
+func TestProfile_AddExperience(t *testing.T) {
+	p := NewProfile("1", "Mage", "mage", Stats{Health: 80, Attack: 20, Defense: 5, Speed: 12}, []string{"jab"})
+
+	if levels := p.AddExperience(50); levels != 0 || p.Level != 1 {
+		t.Errorf("AddExperience(50) = %d levels, level %d", levels, p.Level)
+	}
+	if levels := p.AddExperience(300); levels != 2 || p.Level != 3 {
+		t.Errorf("AddExperience(300) = %d levels, level %d", levels, p.Level)
+	}
+	if levels := p.AddExperience(-10); levels != 0 || p.Experience != 350 {
+		t.Errorf("Expected negative experience to be ignored, got %d levels and %d experience", levels, p.Experience)
+	}
+}
+
+func TestProfile_Growth(t *testing.T) {
+	p := NewProfile("1", "Mage", "mage", Stats{Health: 80, Attack: 20, Defense: 5, Speed: 12}, []string{"jab"})
+	p.AddExperience(ExperienceForLevel(3))
+
+	if got, want := p.Stats(testCurve), (Stats{Health: 100, Attack: 24, Defense: 7, Speed: 12}); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+
+	ids := p.AbilityIDs(testCurve)
+	if len(ids) != 2 || ids[0] != "jab" || ids[1] != "fireball" {
+		t.Errorf("AbilityIDs() = %v, want [jab fireball]", ids)
+	}
+
+	progress := p.Progress(testCurve)
+	if progress.NextLevelAt != ExperienceForLevel(4) {
+		t.Errorf("NextLevelAt = %d, want %d", progress.NextLevelAt, ExperienceForLevel(4))
+	}
+	if len(progress.UpcomingUnlocks) != 1 || progress.UpcomingUnlocks[0].Ability != "meteor" {
+		t.Errorf("UpcomingUnlocks = %+v, want meteor", progress.UpcomingUnlocks)
+	}
+
+	abilities := abilityMap{"jab": {Name: "Jab", Damage: 5}, "fireball": {Name: "Fireball", Damage: 15}}
+	c, err := p.CombatCharacter(testCurve, abilities)
+	if err != nil {
+		t.Fatalf("CombatCharacter() error = %v", err)
+	}
+	if c.ID != "1" || c.Health != 100 || len(c.Abilities) != 2 || c.Abilities[1].Name != "Fireball" {
+		t.Errorf("CombatCharacter() = %+v", c)
+	}
+
+	p.AddExperience(ExperienceForLevel(5))
+	if _, err := p.CombatCharacter(testCurve, abilities); err == nil {
+		t.Error("Expected error for an unlocked ability that does not exist")
+	}
+}
+
+func TestProfile_GrowthStopsAtLimits(t *testing.T) {
+	p := NewProfile("1", "Rogue", "rogue", Stats{Health: 80, Attack: 17, Defense: 6, Speed: 16}, nil)
+	p.AddExperience(ExperienceForLevel(MaxLevel))
+
+	curve := GrowthCurve{Stats: Stats{Health: 30, Attack: 2, Defense: 1, Speed: 2}}
+	if got, want := p.Stats(curve), (Stats{Health: 1000, Attack: 115, Defense: 55, Speed: 100}); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestExperienceReward(t *testing.T) {
+	equal := game.Character{Health: 100, Attack: 10, Defense: 10, Speed: 10}
+	strong := game.Character{Health: 500, Attack: 50, Defense: 50, Speed: 50}
+	weak := game.Character{Health: 10, Attack: 1, Defense: 1, Speed: 1}
+
+	tests := []struct {
+		name     string
+		outcome  Outcome
+		opponent game.Character
+		want     int
+	}{
+		{"win against equal", OutcomeWin, equal, 100},
+		{"draw against equal", OutcomeDraw, equal, 50},
+		{"loss against equal", OutcomeLoss, equal, 25},
+		{"win against stronger is capped at double", OutcomeWin, strong, 200},
+		{"win against weaker is floored at half", OutcomeWin, weak, 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExperienceReward(tt.outcome, equal, tt.opponent); got != tt.want {
+				t.Errorf("ExperienceReward() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
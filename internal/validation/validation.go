@@ -0,0 +1,420 @@
+// Package validation checks characters, abilities and status effects against
+// the game's limits and reports every problem found, each tagged with the
+// path of the offending field.
+package validation
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/MaterDev/golang_turnbased_game_spike/internal/formula"
+	"github.com/MaterDev/golang_turnbased_game_spike/internal/game"
+)
+
+// FieldError is a problem with a single field. Field is a path such as
+// "Character1.Abilities[1].Cooldown".
+type FieldError struct {
+	Field   string `json:"Field"`
+	Message string `json:"Message"`
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// Errors is every problem found by a validation run
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Limits are the bounds content must stay within. Ranges are inclusive.
+type Limits struct {
+	MinHealth, MaxHealth   int
+	MaxAttack              int
+	MaxDefense             int
+	MinSpeed, MaxSpeed     int
+	MinAbilities           int
+	MaxAbilities           int
+	MaxDamage              int
+	MaxCooldown            int
+	MinDuration            int
+	MaxDuration            int
+	MinPotency, MaxPotency int
+	MaxItemQuantity        int
+	MaxReserves            int
+	MaxRounds              int
+	MaxActionBuffer        int
+	MaxComboBonus          int
+	// MaxGridSize bounds a grid's width and height, and with it movement,
+	// range and area size
+	MaxGridSize int
+}
+
+// DefaultLimits are the limits the game runs with. Four abilities keeps a
+// character's options readable at a glance.
+var DefaultLimits = Limits{
+	MinHealth:    1,
+	MaxHealth:    1000,
+	MaxAttack:    200,
+	MaxDefense:   200,
+	MinSpeed:     1,
+	MaxSpeed:     100,
+	MinAbilities: 1,
+	MaxAbilities: 4,
+	MaxDamage:    500,
+	MaxCooldown:  10,
+	MinDuration:  1,
+	MaxDuration:  10,
+	MinPotency:   0,
+	MaxPotency:   100,
+
+	MaxItemQuantity: 10,
+	MaxReserves:     3,
+	MaxRounds:       100,
+	MaxActionBuffer: 1000,
+	MaxComboBonus:   200,
+	MaxGridSize:     32,
+}
+
+// Validator collects errors across any number of checks
+type Validator struct {
+	Limits Limits
+	errs   Errors
+}
+
+// New returns a validator that checks against limits
+func New(limits Limits) *Validator {
+	return &Validator{Limits: limits}
+}
+
+// Add records a problem with the field at path
+func (v *Validator) Add(path, format string, args ...any) {
+	v.errs = append(v.errs, FieldError{Field: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// Errors returns everything found so far
+func (v *Validator) Errors() Errors {
+	return v.errs
+}
+
+// Err returns the errors found so far as an error, or nil if there are none
+func (v *Validator) Err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+// Character checks a character's stats, abilities and active effects
+func (v *Validator) Character(path string, c game.Character) {
+	if strings.TrimSpace(c.Name) == "" {
+		v.Add(join(path, "Name"), "is required")
+	}
+	v.between(join(path, "Health"), c.Health, v.Limits.MinHealth, v.Limits.MaxHealth)
+	v.between(join(path, "Attack"), c.Attack, 0, v.Limits.MaxAttack)
+	v.between(join(path, "Defense"), c.Defense, 0, v.Limits.MaxDefense)
+	v.between(join(path, "Speed"), c.Speed, v.Limits.MinSpeed, v.Limits.MaxSpeed)
+
+	if n := len(c.Abilities); n < v.Limits.MinAbilities || n > v.Limits.MaxAbilities {
+		v.Add(join(path, "Abilities"), "must have between %d and %d abilities, got %d", v.Limits.MinAbilities, v.Limits.MaxAbilities, n)
+	}
+	for i, ability := range c.Abilities {
+		v.Ability(fmt.Sprintf("%s[%d]", join(path, "Abilities"), i), ability)
+	}
+	for i, effect := range c.StatusEffects {
+		v.ActiveEffect(fmt.Sprintf("%s[%d]", join(path, "StatusEffects"), i), effect)
+	}
+	v.Resistances(join(path, "Resistances"), c.Resistances)
+	v.Immunities(join(path, "Immunities"), c.Immunities)
+	v.TemporaryImmunities(join(path, "TemporaryImmunities"), c.TemporaryImmunities)
+	v.between(join(path, "Movement"), c.Movement, 0, v.Limits.MaxGridSize)
+	for i, item := range c.Inventory {
+		v.Consumable(fmt.Sprintf("%s[%d]", join(path, "Inventory"), i), item)
+	}
+}
+
+// Resistances checks that each resistance is to a known effect and is a
+// percentage
+func (v *Validator) Resistances(path string, resistances map[game.StatusEffect]int) {
+	effects := make([]game.StatusEffect, 0, len(resistances))
+	for effect := range resistances {
+		effects = append(effects, effect)
+	}
+	sort.Slice(effects, func(i, j int) bool { return effects[i] < effects[j] })
+
+	for _, effect := range effects {
+		effectPath := fmt.Sprintf("%s[%s]", path, effect)
+		if !effect.IsKnown() {
+			v.Add(effectPath, "unknown status effect %q", effect)
+			continue
+		}
+		v.between(effectPath, resistances[effect], 0, 100)
+	}
+}
+
+// Immunities checks that each immunity is to a known effect
+func (v *Validator) Immunities(path string, immunities []game.StatusEffect) {
+	for i, effect := range immunities {
+		if !effect.IsKnown() {
+			v.Add(fmt.Sprintf("%s[%d]", path, i), "unknown status effect %q", effect)
+		}
+	}
+}
+
+// TemporaryImmunities checks that each temporary immunity is to a known
+// crowd-control effect and lasts a sensible time
+func (v *Validator) TemporaryImmunities(path string, immunities map[game.StatusEffect]int) {
+	effects := make([]game.StatusEffect, 0, len(immunities))
+	for effect := range immunities {
+		effects = append(effects, effect)
+	}
+	sort.Slice(effects, func(i, j int) bool { return effects[i] < effects[j] })
+
+	for _, effect := range effects {
+		effectPath := fmt.Sprintf("%s[%s]", path, effect)
+		if !effect.IsCrowdControl() {
+			v.Add(effectPath, "%q is not crowd control", effect)
+			continue
+		}
+		v.between(effectPath, immunities[effect], 0, v.Limits.MaxDuration)
+	}
+}
+
+// Ability checks an ability and the status effect it applies
+func (v *Validator) Ability(path string, a game.Ability) {
+	if strings.TrimSpace(a.Name) == "" {
+		v.Add(join(path, "Name"), "is required")
+	}
+	v.between(join(path, "Damage"), a.Damage, 0, v.Limits.MaxDamage)
+	v.between(join(path, "CooldownMax"), a.CooldownMax, 0, v.Limits.MaxCooldown)
+	if a.Cooldown < 0 || a.Cooldown > max(a.CooldownMax, 0) {
+		v.Add(join(path, "Cooldown"), "must be between 0 and CooldownMax (%d), got %d", max(a.CooldownMax, 0), a.Cooldown)
+	}
+	v.StatusEffect(join(path, "StatusEffect"), a.StatusEffect)
+	v.Formula(join(path, "Formula"), a.Formula, game.AbilityFormulaVars)
+	if a.SetsWeather != "" && !a.SetsWeather.IsKnown() {
+		v.Add(join(path, "SetsWeather"), "unknown weather %q", a.SetsWeather)
+	}
+	if a.SetsWeather != "" && a.ClearsWeather {
+		v.Add(join(path, "ClearsWeather"), "cannot be set along with SetsWeather")
+	}
+	if a.SetsWeather == "" && a.WeatherDuration != 0 {
+		v.Add(join(path, "WeatherDuration"), "must be 0 without SetsWeather")
+	}
+	v.between(join(path, "WeatherDuration"), a.WeatherDuration, 0, v.Limits.MaxDuration)
+	if a.Combo != nil {
+		v.Combo(join(path, "Combo"), *a.Combo)
+	}
+	v.between(join(path, "ChargeRounds"), a.ChargeRounds, 0, v.Limits.MaxDuration)
+	v.between(join(path, "ChannelRounds"), a.ChannelRounds, 0, v.Limits.MaxDuration)
+	v.between(join(path, "DelayRounds"), a.DelayRounds, 0, v.Limits.MaxDuration)
+	deferred := 0
+	for _, rounds := range []int{a.ChargeRounds, a.ChannelRounds, a.DelayRounds} {
+		if rounds > 0 {
+			deferred++
+		}
+	}
+	if deferred > 1 {
+		v.Add(path, "may set only one of ChargeRounds, ChannelRounds and DelayRounds")
+	}
+	v.between(join(path, "InterruptDamage"), a.InterruptDamage, 0, v.Limits.MaxHealth)
+	if a.InterruptDamage > 0 && a.ChargeRounds == 0 && a.ChannelRounds == 0 {
+		v.Add(join(path, "InterruptDamage"), "needs ChargeRounds or ChannelRounds")
+	}
+	v.between(join(path, "Priority"), a.Priority, -game.MaxPriority, game.MaxPriority)
+	v.between(join(path, "Range"), a.Range, 0, v.Limits.MaxGridSize)
+	if a.Area != nil {
+		if !a.Area.Shape.IsKnown() {
+			v.Add(join(path, "Area.Shape"), "unknown area shape %q", a.Area.Shape)
+		}
+		v.between(join(path, "Area.Size"), a.Area.Size, 1, v.Limits.MaxGridSize)
+	}
+	if a.Summon != nil {
+		if deferred > 0 {
+			v.Add(join(path, "Summon"), "cannot be charged, channelled or delayed")
+		}
+		if a.Damage != 0 || a.Formula != nil {
+			v.Add(join(path, "Summon"), "abilities deal no damage")
+		}
+		v.Summon(join(path, "Summon"), *a.Summon)
+	}
+}
+
+// Summon checks the minion a summoning ability brings and the strategy that
+// plays it
+func (v *Validator) Summon(path string, s game.Summon) {
+	v.Character(join(path, "Minion"), s.Minion)
+	if s.Strategy != "" {
+		if _, err := game.NewStrategy(s.Strategy, nil); err != nil {
+			v.Add(join(path, "Strategy"), "%v", err)
+		}
+	}
+	v.between(join(path, "Rounds"), s.Rounds, 0, v.Limits.MaxDuration)
+}
+
+// Combo checks an ability's combo, which must have at least one condition
+func (v *Validator) Combo(path string, c game.Combo) {
+	if c.TargetHas == "" && len(c.After) == 0 && !c.AllyBefore {
+		v.Add(path, "needs TargetHas, After or AllyBefore")
+	}
+	if c.TargetHas != "" && !c.TargetHas.IsKnown() {
+		v.Add(join(path, "TargetHas"), "unknown status effect %q", c.TargetHas)
+	}
+	if len(c.After) > game.MaxComboLength {
+		v.Add(join(path, "After"), "must list at most %d abilities, got %d", game.MaxComboLength, len(c.After))
+	}
+	for i, name := range c.After {
+		if strings.TrimSpace(name) == "" {
+			v.Add(fmt.Sprintf("%s[%d]", join(path, "After"), i), "is required")
+		}
+	}
+	v.between(join(path, "Bonus"), c.Bonus, 0, v.Limits.MaxComboBonus)
+}
+
+// Formula checks that an expression only reads the variables allowed where
+// it is used
+func (v *Validator) Formula(path string, e *formula.Expr, allowed []string) {
+	for _, name := range e.Vars() {
+		if !slices.Contains(allowed, name) {
+			v.Add(path, "variable %q is not available here", name)
+		}
+	}
+}
+
+// Consumable checks a stack of consumables and what each one does
+func (v *Validator) Consumable(path string, c game.Consumable) {
+	if strings.TrimSpace(c.Name) == "" {
+		v.Add(join(path, "Name"), "is required")
+	}
+	v.between(join(path, "Quantity"), c.Quantity, 0, v.Limits.MaxItemQuantity)
+	if !c.Target.IsValid() {
+		v.Add(join(path, "Target"), "unknown target %q", c.Target)
+	}
+	v.between(join(path, "Heal"), c.Heal, 0, v.Limits.MaxHealth)
+	v.between(join(path, "Damage"), c.Damage, 0, v.Limits.MaxDamage)
+	for i, effect := range c.Cures {
+		if !effect.IsKnown() {
+			v.Add(fmt.Sprintf("%s[%d]", join(path, "Cures"), i), "unknown status effect %q", effect)
+		}
+	}
+	v.StatusEffect(join(path, "StatusEffect"), c.StatusEffect)
+}
+
+// Rules checks a rule set. Empty turn modes, damage models and tick phases
+// are allowed and take the classic rule.
+func (v *Validator) Rules(path string, r game.RuleSet) {
+	if r.TurnMode != "" && !r.TurnMode.IsValid() {
+		v.Add(join(path, "TurnMode"), "unknown turn mode %q", r.TurnMode)
+	}
+	if r.DamageModel != "" && !r.DamageModel.IsValid() {
+		v.Add(join(path, "DamageModel"), "unknown damage model %q", r.DamageModel)
+	}
+	if r.EffectTick != "" && !r.EffectTick.IsValid() {
+		v.Add(join(path, "EffectTick"), "unknown effect tick %q", r.EffectTick)
+	}
+	v.between(join(path, "MaxRounds"), r.MaxRounds, 0, v.Limits.MaxRounds)
+	v.between(join(path, "ActionBuffer"), r.ActionBuffer, 0, v.Limits.MaxActionBuffer)
+	v.between(join(path, "Visibility.HealthBuckets"), r.Visibility.HealthBuckets, 0, 100)
+}
+
+// Environment checks the weather a battle starts under. A Duration of zero
+// lasts the whole battle.
+func (v *Validator) Environment(path string, e game.Environment) {
+	if !e.Weather.IsKnown() {
+		v.Add(join(path, "Weather"), "unknown weather %q", e.Weather)
+	}
+	v.between(join(path, "Duration"), e.Duration, 0, v.Limits.MaxDuration)
+}
+
+// Grid checks a battle map and where the characters start on it. starts
+// holds each start position by the field it was given in.
+func (v *Validator) Grid(path string, rows []string, starts map[string]game.Position) {
+	rowsPath := join(path, "Rows")
+	if n := len(rows); n < 1 || n > v.Limits.MaxGridSize {
+		v.Add(rowsPath, "must have between 1 and %d rows, got %d", v.Limits.MaxGridSize, n)
+		return
+	}
+	grid := game.Grid{Rows: rows}
+	for y, row := range rows {
+		rowPath := fmt.Sprintf("%s[%d]", rowsPath, y)
+		if len(row) != grid.Width() {
+			v.Add(rowPath, "must be as wide as the first row (%d), got %d", grid.Width(), len(row))
+		}
+		if len(row) < 1 || len(row) > v.Limits.MaxGridSize {
+			v.Add(rowPath, "must be between 1 and %d cells wide, got %d", v.Limits.MaxGridSize, len(row))
+		}
+		for x := range len(row) {
+			if t := game.Terrain(row[x]); !t.IsKnown() {
+				v.Add(rowPath, "unknown terrain %q at %d", t, x)
+			}
+		}
+	}
+
+	fields := make([]string, 0, len(starts))
+	for field := range starts {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	taken := map[game.Position]string{}
+	for _, field := range fields {
+		p := starts[field]
+		if grid.TerrainAt(p) != game.TerrainOpen {
+			v.Add(join(path, field), "must be an open cell on the grid, got (%d, %d)", p.X, p.Y)
+		} else if other, ok := taken[p]; ok {
+			v.Add(join(path, field), "must differ from %s", other)
+		}
+		taken[p] = field
+	}
+}
+
+// StatusEffect checks an effect as an ability applies it. An effect with no
+// Type means the ability has none, so its other fields must be left unset.
+func (v *Validator) StatusEffect(path string, e game.StatusEffectData) {
+	if e.Type == "" {
+		if e.Duration != 0 || e.Potency != 0 {
+			v.Add(join(path, "Type"), "is required when Duration or Potency is set")
+		}
+		return
+	}
+	v.effect(path, e)
+}
+
+// ActiveEffect checks an effect already on a character, which must have a Type
+func (v *Validator) ActiveEffect(path string, e game.StatusEffectData) {
+	if e.Type == "" {
+		v.Add(join(path, "Type"), "is required")
+		return
+	}
+	v.effect(path, e)
+}
+
+func (v *Validator) effect(path string, e game.StatusEffectData) {
+	if !e.Type.IsKnown() {
+		v.Add(join(path, "Type"), "unknown status effect %q", e.Type)
+	}
+	v.between(join(path, "Duration"), e.Duration, v.Limits.MinDuration, v.Limits.MaxDuration)
+	v.between(join(path, "Potency"), e.Potency, v.Limits.MinPotency, v.Limits.MaxPotency)
+	v.Formula(join(path, "Formula"), e.Formula, game.EffectFormulaVars)
+}
+
+func (v *Validator) between(path string, value, lo, hi int) {
+	if value < lo || value > hi {
+		v.Add(path, "must be between %d and %d, got %d", lo, hi, value)
+	}
+}
+
+// join appends a field name to a path
+func join(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
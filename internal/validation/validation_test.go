@@ -0,0 +1,344 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MaterDev/golang_turnbased_game_spike/internal/formula"
+	"github.com/MaterDev/golang_turnbased_game_spike/internal/game"
+)
+
+func validCharacter() game.Character {
+	return game.Character{
+		Name:    "Warrior",
+		Health:  100,
+		Attack:  15,
+		Defense: 10,
+		Speed:   8,
+		Abilities: []game.Ability{
+			{Name: "Basic Attack", Damage: 10},
+			{
+				Name:        "Power Strike",
+				Damage:      20,
+				CooldownMax: 2,
+				StatusEffect: game.StatusEffectData{
+					Type:     game.StatusEnraged,
+					Duration: 2,
+					Potency:  20,
+				},
+			},
+		},
+	}
+}
+
+func TestValidator_Character(t *testing.T) {
+	tests := []struct {
+		name       string
+		modify     func(c *game.Character)
+		wantFields []string
+	}{
+		{
+			name:   "valid character",
+			modify: func(c *game.Character) {},
+		},
+		{
+			name: "bad stats",
+			modify: func(c *game.Character) {
+				c.Name = " "
+				c.Health = 9999
+				c.Attack = -1
+				c.Speed = 0
+			},
+			wantFields: []string{"Character1.Name", "Character1.Health", "Character1.Attack", "Character1.Speed"},
+		},
+		{
+			name:       "no abilities",
+			modify:     func(c *game.Character) { c.Abilities = nil },
+			wantFields: []string{"Character1.Abilities"},
+		},
+		{
+			name: "too many abilities",
+			modify: func(c *game.Character) {
+				for len(c.Abilities) <= DefaultLimits.MaxAbilities {
+					c.Abilities = append(c.Abilities, c.Abilities[0])
+				}
+			},
+			wantFields: []string{"Character1.Abilities"},
+		},
+		{
+			name: "negative cooldowns",
+			modify: func(c *game.Character) {
+				c.Abilities[1].CooldownMax = -2
+				c.Abilities[1].Cooldown = -1
+			},
+			wantFields: []string{"Character1.Abilities[1].CooldownMax", "Character1.Abilities[1].Cooldown"},
+		},
+		{
+			name:       "cooldown above its maximum",
+			modify:     func(c *game.Character) { c.Abilities[1].Cooldown = 3 },
+			wantFields: []string{"Character1.Abilities[1].Cooldown"},
+		},
+		{
+			name: "unknown effect out of bounds",
+			modify: func(c *game.Character) {
+				c.Abilities[1].StatusEffect = game.StatusEffectData{Type: "FROZEN", Duration: 0, Potency: 500}
+			},
+			wantFields: []string{
+				"Character1.Abilities[1].StatusEffect.Type",
+				"Character1.Abilities[1].StatusEffect.Duration",
+				"Character1.Abilities[1].StatusEffect.Potency",
+			},
+		},
+		{
+			name:       "effect without type",
+			modify:     func(c *game.Character) { c.Abilities[0].StatusEffect.Duration = 2 },
+			wantFields: []string{"Character1.Abilities[0].StatusEffect.Type"},
+		},
+		{
+			name:       "active effect without type",
+			modify:     func(c *game.Character) { c.StatusEffects = []game.StatusEffectData{{Duration: 1}} },
+			wantFields: []string{"Character1.StatusEffects[0].Type"},
+		},
+		{
+			name: "bad resistances",
+			modify: func(c *game.Character) {
+				c.Resistances = map[game.StatusEffect]int{game.StatusBurning: 150, "FROZEN": 10, game.StatusPoisoned: 50}
+			},
+			wantFields: []string{"Character1.Resistances[BURNING]", "Character1.Resistances[FROZEN]"},
+		},
+		{
+			name: "formula reads what is not there",
+			modify: func(c *game.Character) {
+				e, err := formula.Parse("effect.potency * caster.attack")
+				if err != nil {
+					t.Fatal(err)
+				}
+				c.Abilities[0].Formula = e
+			},
+			wantFields: []string{"Character1.Abilities[0].Formula"},
+		},
+		{
+			name: "bad consumable",
+			modify: func(c *game.Character) {
+				c.Inventory = []game.Consumable{{Name: "Elixir", Quantity: 99, Target: "ALLY", Cures: []game.StatusEffect{"FROZEN"}}}
+			},
+			wantFields: []string{"Character1.Inventory[0].Quantity", "Character1.Inventory[0].Target", "Character1.Inventory[0].Cures[0]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := validCharacter()
+			tt.modify(&c)
+
+			v := New(DefaultLimits)
+			v.Character("Character1", c)
+
+			got := map[string]bool{}
+			for _, e := range v.Errors() {
+				got[e.Field] = true
+			}
+			if len(got) != len(tt.wantFields) {
+				t.Errorf("Got errors %v, want fields %v", v.Errors(), tt.wantFields)
+			}
+			for _, field := range tt.wantFields {
+				if !got[field] {
+					t.Errorf("Expected an error for %s, got %v", field, v.Errors())
+				}
+			}
+			if (v.Err() == nil) != (len(tt.wantFields) == 0) {
+				t.Errorf("Err() = %v, want errors for %v", v.Err(), tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestErrors_Error(t *testing.T) {
+	errs := Errors{
+		{Field: "Character1.Health", Message: "must be between 1 and 1000, got 0"},
+		{Field: "Character2.Name", Message: "is required"},
+	}
+	want := "Character1.Health: must be between 1 and 1000, got 0; Character2.Name: is required"
+	if got := errs.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if !strings.Contains(errs[1].Error(), "Character2.Name") {
+		t.Errorf("FieldError.Error() = %q", errs[1].Error())
+	}
+}
+
+func TestValidator_Rules(t *testing.T) {
+	for _, preset := range game.Presets() {
+		v := New(DefaultLimits)
+		v.Rules("Rules", preset)
+		if err := v.Err(); err != nil {
+			t.Errorf("Preset %s is invalid: %v", preset.Name, err)
+		}
+	}
+
+	v := New(DefaultLimits)
+	v.Rules("Rules", game.RuleSet{TurnMode: "CHESS", MaxRounds: -1, DamageModel: "SQUARE", EffectTick: "NEVER", ActionBuffer: 5000, Visibility: game.Visibility{HealthBuckets: -2}})
+	want := []string{"Rules.TurnMode", "Rules.DamageModel", "Rules.EffectTick", "Rules.MaxRounds", "Rules.ActionBuffer", "Rules.Visibility.HealthBuckets"}
+	errs := v.Errors()
+	if len(errs) != len(want) {
+		t.Fatalf("Got errors %v, want fields %v", errs, want)
+	}
+	for i, field := range want {
+		if errs[i].Field != field {
+			t.Errorf("Error %d is for %s, want %s", i, errs[i].Field, field)
+		}
+	}
+}
+
+func TestValidator_Weather(t *testing.T) {
+	v := New(DefaultLimits)
+	v.Environment("Environment", game.Environment{Weather: game.WeatherFog, Duration: 3})
+	v.Ability("Ability", game.Ability{Name: "Heat Wave", SetsWeather: game.WeatherHeatwave, WeatherDuration: 4})
+	v.Ability("Ability", game.Ability{Name: "Gust", ClearsWeather: true})
+	if err := v.Err(); err != nil {
+		t.Fatalf("Expected valid weather, got %v", err)
+	}
+
+	v = New(DefaultLimits)
+	v.Environment("Environment", game.Environment{Weather: "HAIL", Duration: -1})
+	v.Ability("Ability", game.Ability{Name: "Storm", SetsWeather: "HAIL", ClearsWeather: true})
+	v.Ability("Ability", game.Ability{Name: "Lull", WeatherDuration: 2})
+	want := []string{
+		"Environment.Weather", "Environment.Duration",
+		"Ability.SetsWeather", "Ability.ClearsWeather", "Ability.WeatherDuration",
+	}
+	errs := v.Errors()
+	if len(errs) != len(want) {
+		t.Fatalf("Got errors %v, want fields %v", errs, want)
+	}
+	for i, field := range want {
+		if errs[i].Field != field {
+			t.Errorf("Error %d is for %s, want %s", i, errs[i].Field, field)
+		}
+	}
+}
+
+func TestValidator_Combo(t *testing.T) {
+	v := New(DefaultLimits)
+	v.Combo("Combo", game.Combo{After: []string{"Basic Attack", "Basic Attack"}, Finisher: true})
+	v.Combo("Combo", game.Combo{TargetHas: game.StatusBurning, Bonus: 75})
+	if err := v.Err(); err != nil {
+		t.Fatalf("Expected valid combos, got %v", err)
+	}
+
+	v = New(DefaultLimits)
+	v.Combo("Combo", game.Combo{Bonus: 10})
+	v.Combo("Combo", game.Combo{TargetHas: "SOGGY", After: []string{"a", "b", " ", "d"}, Bonus: 500})
+	want := []string{"Combo", "Combo.TargetHas", "Combo.After", "Combo.After[2]", "Combo.Bonus"}
+	errs := v.Errors()
+	if len(errs) != len(want) {
+		t.Fatalf("Got errors %v, want fields %v", errs, want)
+	}
+	for i, field := range want {
+		if errs[i].Field != field {
+			t.Errorf("Error %d is for %s, want %s", i, errs[i].Field, field)
+		}
+	}
+}
+
+func TestValidator_PendingAbility(t *testing.T) {
+	v := New(DefaultLimits)
+	v.Ability("Ability", game.Ability{Name: "Meteor", ChargeRounds: 2, InterruptDamage: 20})
+	v.Ability("Ability", game.Ability{Name: "Time Bomb", DelayRounds: 3})
+	if err := v.Err(); err != nil {
+		t.Fatalf("Expected valid abilities, got %v", err)
+	}
+
+	v = New(DefaultLimits)
+	v.Ability("Ability", game.Ability{Name: "Muddle", ChargeRounds: 1, DelayRounds: 20})
+	v.Ability("Ability", game.Ability{Name: "Bomb", DelayRounds: 1, InterruptDamage: 5})
+	want := []string{"Ability.DelayRounds", "Ability", "Ability.InterruptDamage"}
+	errs := v.Errors()
+	if len(errs) != len(want) {
+		t.Fatalf("Got errors %v, want fields %v", errs, want)
+	}
+	for i, field := range want {
+		if errs[i].Field != field {
+			t.Errorf("Error %d is for %s, want %s", i, errs[i].Field, field)
+		}
+	}
+}
+
+func TestValidator_AbilityPriority(t *testing.T) {
+	for _, tt := range []struct {
+		priority int
+		valid    bool
+	}{{0, true}, {game.MaxPriority, true}, {-game.MaxPriority, true}, {game.MaxPriority + 1, false}, {-game.MaxPriority - 1, false}} {
+		v := New(DefaultLimits)
+		v.Ability("Ability", game.Ability{Name: "Counter", Priority: tt.priority})
+		if (v.Err() == nil) != tt.valid {
+			t.Errorf("Priority %d: valid = %v, want %v", tt.priority, v.Err() == nil, tt.valid)
+		}
+	}
+}
+
+func TestValidator_Immunities(t *testing.T) {
+	v := New(DefaultLimits)
+	v.Immunities("Immunities", []game.StatusEffect{game.StatusPoisoned, "FROZEN"})
+	v.TemporaryImmunities("TemporaryImmunities", map[game.StatusEffect]int{game.StatusStunned: 2, game.StatusBurning: 1})
+	v.TemporaryImmunities("TemporaryImmunities", map[game.StatusEffect]int{game.StatusStunned: -1})
+	want := []string{"Immunities[1]", "TemporaryImmunities[BURNING]", "TemporaryImmunities[STUNNED]"}
+	errs := v.Errors()
+	if len(errs) != len(want) {
+		t.Fatalf("Got errors %v, want fields %v", errs, want)
+	}
+	for i, field := range want {
+		if errs[i].Field != field {
+			t.Errorf("Error %d is for %s, want %s", i, errs[i].Field, field)
+		}
+	}
+}
+
+func TestValidator_Summon(t *testing.T) {
+	wolf := game.Character{Name: "Wolf", Health: 30, Attack: 8, Speed: 12, Abilities: []game.Ability{{Name: "Bite", Damage: 4}}}
+	v := New(DefaultLimits)
+	v.Ability("Ability", game.Ability{Name: "Call Wolf", Summon: &game.Summon{Minion: wolf, Strategy: "random", Rounds: 3}})
+	if err := v.Err(); err != nil {
+		t.Fatalf("Expected a valid summon, got %v", err)
+	}
+
+	v = New(DefaultLimits)
+	v.Ability("Ability", game.Ability{Name: "Call Wolf", Damage: 5, DelayRounds: 1, Summon: &game.Summon{
+		Minion:   game.Character{Name: "Wolf", Health: 30, Speed: 12},
+		Strategy: "feral",
+		Rounds:   20,
+	}})
+	want := []string{"Ability.Summon", "Ability.Summon", "Ability.Summon.Minion.Abilities", "Ability.Summon.Strategy", "Ability.Summon.Rounds"}
+	errs := v.Errors()
+	if len(errs) != len(want) {
+		t.Fatalf("Got errors %v, want fields %v", errs, want)
+	}
+	for i, field := range want {
+		if errs[i].Field != field {
+			t.Errorf("Error %d is for %s, want %s", i, errs[i].Field, field)
+		}
+	}
+}
+
+func TestValidator_Grid(t *testing.T) {
+	v := New(DefaultLimits)
+	v.Grid("Grid", []string{"....", ".#~.", "...."}, map[string]game.Position{"Position1": {X: 0, Y: 0}, "Position2": {X: 3, Y: 2}})
+	v.Ability("Ability", game.Ability{Name: "Fireball", Range: 4, Area: &game.Area{Shape: game.AreaRadius, Size: 1}})
+	if err := v.Err(); err != nil {
+		t.Fatalf("Expected a valid grid, got %v", err)
+	}
+
+	v = New(DefaultLimits)
+	v.Grid("Grid", []string{"....", ".#?", "...."}, map[string]game.Position{"Position1": {X: 1, Y: 1}, "Position2": {X: 9, Y: 0}, "Position3": {X: 0, Y: 0}, "Position4": {X: 0, Y: 0}})
+	v.Ability("Ability", game.Ability{Name: "Fireball", Range: -1, Area: &game.Area{Shape: "STAR"}})
+	want := []string{"Grid.Rows[1]", "Grid.Rows[1]", "Grid.Position1", "Grid.Position2", "Grid.Position4", "Ability.Range", "Ability.Area.Shape", "Ability.Area.Size"}
+	errs := v.Errors()
+	if len(errs) != len(want) {
+		t.Fatalf("Got errors %v, want fields %v", errs, want)
+	}
+	for i, field := range want {
+		if errs[i].Field != field {
+			t.Errorf("Error %d is for %s, want %s", i, errs[i].Field, field)
+		}
+	}
+}